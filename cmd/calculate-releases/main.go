@@ -11,14 +11,16 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/utils/merkletrie"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 
+	"github.com/uw-labs/go-mono/cmd/internal/deployfile"
 	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
 )
 
 var (
@@ -27,18 +29,18 @@ var (
 	moduleName   = flag.String("module-name", "github.com/uw-labs/go-mono", "The name of the local module.")
 	baseRevision = flag.String("base", "", "The base revision to diff against when finding changes. Defaults to master.")
 	headRevision = flag.String("head", "", "The head revision to diff with when finding changes. Defaults to HEAD.")
+	logConfig    = pkglog.RegisterFlags(flag.CommandLine)
 )
 
 func main() {
 	flag.Parse()
 
-	logger := logrus.New()
-	logger.Formatter = &logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: time.StampMilli,
+	logger, err := pkglog.New(logConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal()
 	}
 
-	err := run(logger, *repoRoot, *buildFile, *moduleName, *baseRevision, *headRevision)
+	err = run(logger, *repoRoot, *buildFile, *moduleName, *baseRevision, *headRevision)
 	if err != nil {
 		logger.WithError(err).Fatal()
 	}
@@ -78,42 +80,28 @@ func run(logger *logrus.Logger, repoRoot, buildFilePath, moduleName, baseRevisio
 
 	logger.Infoln("Changed packages:", packages)
 
-	releases := map[string]string{}
+	deployFiles, err := findDeployFiles(repoRoot)
+	if err != nil {
+		return fmt.Errorf("find deploy files: %w", err)
+	}
+
+	releases := map[string]struct{}{}
 	for _, pkg := range packages {
 		for _, dependant := range revDeps[pkg] {
-			if _, ok := releases[dependant]; ok {
-				// Already added
-				continue
+			// Binaries without a deploy file are not released
+			for _, conf := range deployFiles[dependant] {
+				releases[conf] = struct{}{}
 			}
-
-			df1 := filepath.Join(repoRoot, dependant, "deploy.yml")
-			df2 := filepath.Join(repoRoot, dependant, "deploy.yaml")
-			_, err1 := os.Stat(df1)
-			_, err2 := os.Stat(df2)
-			if os.IsNotExist(err1) && os.IsNotExist(err2) {
-				// Binaries without a deploy file are not released
-				continue
-			}
-			if err1 == nil {
-				releases[dependant] = df1
-				continue
-			}
-			if err2 == nil {
-				releases[dependant] = df2
-				continue
-			}
-
-			return fmt.Errorf("stat deployment file: %v, %w", err1, err2)
 		}
 	}
 
-	for release, conf := range releases {
-		if filepath.Dir(release) == "." {
+	for conf := range releases {
+		if filepath.Dir(conf) == "." {
 			// Skip top level release
 			continue
 		}
-		logger.Infoln("Release", release)
-		_, err = buildFile.WriteString(conf + "\n")
+		logger.Infoln("Release", conf)
+		_, err = buildFile.WriteString(filepath.Join(repoRoot, conf) + "\n")
 		if err != nil {
 			return fmt.Errorf("write releases to buildFile: %w", err)
 		}
@@ -122,6 +110,74 @@ func run(logger *logrus.Logger, repoRoot, buildFilePath, moduleName, baseRevisio
 	return nil
 }
 
+// deployFile holds the fields of a deploy file
+// listing the main packages it releases.
+type deployFile struct {
+	Main     string `yaml:"main"`
+	Binaries []struct {
+		Main string `yaml:"main"`
+	} `yaml:"binaries"`
+}
+
+// findDeployFiles finds the deploy files in the repository, and returns
+// the paths of the deploy files releasing each main package. Main packages
+// and deploy file paths are relative to the repository root.
+func findDeployFiles(repoRoot string) (map[string][]string, error) {
+	files := map[string][]string{}
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "vendor", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !deployfile.IsFileName(info.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read deploy file: %w", err)
+		}
+
+		conf := deployFile{
+			Main: filepath.Dir(rel),
+		}
+		err = yaml.Unmarshal(content, &conf)
+		if err != nil {
+			return fmt.Errorf("parse deploy file %s: %w", rel, err)
+		}
+
+		mains := []string{conf.Main}
+		if len(conf.Binaries) > 0 {
+			mains = mains[:0]
+			for _, b := range conf.Binaries {
+				mains = append(mains, b.Main)
+			}
+		}
+		for _, main := range mains {
+			main = filepath.Clean(main)
+			files[main] = append(files[main], rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
 func getChangedFiles(ctx context.Context, logger *logrus.Logger, repoRoot, baseRevision, headRevision string) ([]string, error) {
 	repo, err := git.PlainOpen(repoRoot)
 	if err != nil {
@@ -294,7 +350,7 @@ func getReverseDependencies(ctx context.Context, logger *logrus.Logger, repoRoot
 		}
 
 		// Trim module path from local packages, to match locally changed files
-		pkgName := strings.TrimPrefix(pkg.ImportPath, moduleName)
+		pkgName := strings.TrimPrefix(pkg.ImportPath, moduleName+"/")
 
 		// Add the package as a dependency of itself, so that if only the package itself has
 		// changed, we still build it
@@ -311,7 +367,7 @@ func getReverseDependencies(ctx context.Context, logger *logrus.Logger, repoRoot
 			}
 
 			// Trim module path from local packages, to match locally changed files
-			dep = strings.TrimPrefix(dep, moduleName)
+			dep = strings.TrimPrefix(dep, moduleName+"/")
 
 			revDeps[dep] = append(revDeps[dep], pkgName)
 		}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/dev"
+	pkgcontext "github.com/uw-labs/go-mono/pkg/context"
+)
+
+// composeFile is the name of the docker-compose file in the directory of the environment.
+const composeFile = "docker-compose.yml"
+
+// devOptions configures the local development environment.
+type devOptions struct {
+	RepoRoot string
+	Dir      string
+	Project  string
+	Volumes  bool
+}
+
+// devMain runs the dev subcommand, which brings the local development
+// environment of the deployments up, tears it down or prints its
+// docker-compose file.
+func devMain(logger *logrus.Logger, args []string) {
+	usage := "usage: deploy dev up|down|config [flags] [deploy files]"
+	if len(args) == 0 {
+		logger.Fatal(usage)
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("dev "+action, flag.ExitOnError)
+	repoRoot := fs.String("repo-root", ".", "The root of the repo, to find deploy files and main packages in.")
+	dir := fs.String("dir", ".dev", "The directory to write the docker-compose file and binaries of the environment to.")
+	project := fs.String("project", "", "The docker-compose project name of the environment. Defaults to the name of the repository root directory.")
+	volumes := fs.Bool("volumes", false, "When tearing the environment down, also remove the volumes of its dependencies, such as database data.")
+	_ = fs.Parse(args[1:])
+
+	// The repository root must be absolute for the main packages
+	// to be built as directories rather than import paths.
+	root, err := filepath.Abs(*repoRoot)
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+	opts := &devOptions{
+		RepoRoot: root,
+		Dir:      *dir,
+		Project:  *project,
+		Volumes:  *volumes,
+	}
+	if opts.Project == "" {
+		opts.Project = filepath.Base(root)
+	}
+
+	ctx := pkgcontext.WithSignalHandler(context.Background())
+	switch action {
+	case "up":
+		err = devUp(ctx, logger, opts, fs.Args())
+	case "down":
+		downArgs := []string{"down", "--remove-orphans"}
+		if opts.Volumes {
+			downArgs = append(downArgs, "--volumes")
+		}
+		err = compose(ctx, opts, downArgs...)
+	case "config":
+		var env *dev.Environment
+		env, err = renderDev(opts, fs.Args())
+		if err == nil {
+			_, err = os.Stdout.Write(env.Compose)
+		}
+	default:
+		logger.Fatal(usage)
+	}
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+}
+
+// devUp builds the binaries of the deployments for Linux, writes the
+// docker-compose file and starts the environment, recreating the containers
+// of the deployments so that they run the new binaries.
+func devUp(ctx context.Context, logger logrus.FieldLogger, opts *devOptions, deployFiles []string) error {
+	env, err := renderDev(opts, deployFiles)
+	if err != nil {
+		return err
+	}
+
+	services := make([]string, 0, len(env.Deployments))
+	for _, d := range env.Deployments {
+		for _, b := range d.Binaries {
+			logger.Infof("Building binary %s of %s", b.Main, d.Name)
+			output := filepath.Join(opts.Dir, filepath.FromSlash(dev.BinaryPath(d, b)))
+			err = os.MkdirAll(filepath.Dir(output), 0o755)
+			if err != nil {
+				return fmt.Errorf("create binary directory: %w", err)
+			}
+			_, err = binary.Build(ctx, logger, &binary.Request{
+				Name:     d.Name,
+				RepoRoot: opts.RepoRoot,
+				MainPath: b.Main,
+				GOOS:     "linux",
+				Output:   output,
+			})
+			if err != nil {
+				return fmt.Errorf("build binary %s: %w", b.Main, err)
+			}
+		}
+		services = append(services, d.Name)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(opts.Dir, composeFile), env.Compose, 0o644)
+	if err != nil {
+		return fmt.Errorf("write docker-compose file: %w", err)
+	}
+
+	logger.Infof("Starting %d deployments", len(services))
+	return compose(ctx, opts, append([]string{"up", "--detach", "--remove-orphans", "--force-recreate"}, services...)...)
+}
+
+// renderDev renders the environment of the deploy files, or of every
+// deploy file in the repository if there are none. Every deploy file
+// is parsed, so that the deployments they depend on can be found.
+// The deploy files are relative to the repository root.
+func renderDev(opts *devOptions, deployFiles []string) (*dev.Environment, error) {
+	files, err := deploy.FindFiles(opts.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &dev.Request{}
+	for _, file := range files {
+		d, err := deploy.Parse(opts.RepoRoot, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		req.Deployments = append(req.Deployments, d)
+	}
+	for _, file := range deployFiles {
+		d, err := deploy.Parse(opts.RepoRoot, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		req.Services = append(req.Services, d.Name)
+	}
+
+	return dev.Render(req)
+}
+
+// compose runs docker-compose with the arguments on the environment.
+func compose(ctx context.Context, opts *devOptions, args ...string) error {
+	bin, err := exec.LookPath("docker-compose")
+	if err != nil {
+		return fmt.Errorf("find docker-compose binary: %w", err)
+	}
+
+	args = append([]string{"--project-name", opts.Project, "--file", filepath.Join(opts.Dir, composeFile)}, args...)
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("run docker-compose: %w", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -16,19 +17,27 @@ type Request struct {
 	RepoRoot string
 	Name     string
 	MainPath string
+	// GOOS is the operating system to build for. Defaults to that of the local "go".
+	GOOS string
+	// Output is the path to write the binary to. Defaults to a temporary directory.
+	Output string
 }
 
 // Build builds a CGO-disabled Go binary using a local version of
 // "go" and returns the path where the binary lives.
-func Build(ctx context.Context, logger *logrus.Logger, req *Request) (string, error) {
+func Build(ctx context.Context, logger logrus.FieldLogger, req *Request) (string, error) {
 	goBin, err := exec.LookPath("go")
 	if err != nil {
 		return "", fmt.Errorf("find go binary: %w", err)
 	}
 
-	tempDir, err := ioutil.TempDir("", "build")
-	if err != nil {
-		return "", fmt.Errorf("create temp directory: %w", err)
+	output := req.Output
+	if output == "" {
+		tempDir, err := ioutil.TempDir("", "build")
+		if err != nil {
+			return "", fmt.Errorf("create temp directory: %w", err)
+		}
+		output = filepath.Join(tempDir, "app")
 	}
 
 	cmd := exec.CommandContext(
@@ -37,16 +46,35 @@ func Build(ctx context.Context, logger *logrus.Logger, req *Request) (string, er
 		"build",
 		"-mod=vendor",
 		"-o",
-		filepath.Join(tempDir, "app"),
+		output,
 		filepath.Join(req.RepoRoot, req.MainPath),
 	)
 
 	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if req.GOOS != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+req.GOOS)
+	}
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		logger.Infoln("Build output:\n", string(out))
 		return "", err
 	}
-	return filepath.Join(tempDir, "app"), nil
+	return output, nil
+}
+
+// GoVersion returns the version of the local "go" used to build binaries,
+// as reported by `go version`.
+func GoVersion(ctx context.Context) (string, error) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return "", fmt.Errorf("find go binary: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, goBin, "version").Output()
+	if err != nil {
+		return "", fmt.Errorf("get go version: %w", err)
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "go version "), nil
 }
@@ -0,0 +1,104 @@
+package binary
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Module is a Go module linked into a binary.
+type Module struct {
+	Path    string
+	Version string
+	Sum     string
+	Replace *Module
+}
+
+// BuildInfo is the build information embedded in a Go binary.
+type BuildInfo struct {
+	GoVersion string
+	// Path is the import path of the main package.
+	Path string
+	Main Module
+	Deps []*Module
+}
+
+// ReadBuildInfo reads the build information embedded in the binary at the path,
+// using `go version -m`.
+func ReadBuildInfo(ctx context.Context, path string) (*BuildInfo, error) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return nil, fmt.Errorf("find go binary: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, goBin, "version", "-m", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("read build info: %w", err)
+	}
+
+	return parseBuildInfo(out)
+}
+
+// parseBuildInfo parses the output of `go version -m`, which looks like
+//
+//	/tmp/app: go1.14.3
+//		path	github.com/uw-labs/go-mono/cmd/user-api
+//		mod	github.com/uw-labs/go-mono	(devel)
+//		dep	github.com/sirupsen/logrus	v1.6.0	h1:UBcNElsrwanuuMsnGSlYmtmgbb23qDR5dG+6X6Oo89I=
+//		=>	github.com/sirupsen/logrus	v1.5.0	h1:...
+func parseBuildInfo(out []byte) (*BuildInfo, error) {
+	bi := &BuildInfo{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+
+	if !sc.Scan() {
+		return nil, fmt.Errorf("empty build info")
+	}
+	header := sc.Text()
+	if i := strings.LastIndex(header, ": "); i >= 0 {
+		bi.GoVersion = header[i+2:]
+	}
+
+	var last *Module
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		mod := &Module{Path: fields[1]}
+		if len(fields) > 2 {
+			mod.Version = fields[2]
+		}
+		if len(fields) > 3 {
+			mod.Sum = fields[3]
+		}
+
+		switch fields[0] {
+		case "path":
+			bi.Path = fields[1]
+		case "mod":
+			bi.Main = *mod
+			last = &bi.Main
+		case "dep":
+			bi.Deps = append(bi.Deps, mod)
+			last = mod
+		case "=>":
+			if last == nil {
+				return nil, fmt.Errorf("replacement without a module: %q", sc.Text())
+			}
+			last.Replace = mod
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("scan build info: %w", err)
+	}
+
+	if bi.Path == "" {
+		return nil, fmt.Errorf("binary has no module build info")
+	}
+
+	return bi, nil
+}
@@ -1,22 +1,325 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+//go:generate go-bindata -pkg static -prefix static -nometadata -ignore bindata -o ./static/bindata.go ./static
+
+// DefaultBinaryPath is the path of the binary in the image
+// for deployments with a single binary.
+const DefaultBinaryPath = "/app"
+
 // Deployment describes a parsed deploy.yaml file
 type Deployment struct {
 	Main string `yaml:"main"`
 	Name string `yaml:"name"`
+	// Binaries are the binaries to build into the image. Defaults
+	// to the main package at DefaultBinaryPath, as the entrypoint.
+	Binaries []*Binary `yaml:"binaries"`
+	// Tags are templates for the tags to push the image with.
+	// See the tags package for the available template data.
+	Tags []string `yaml:"tags"`
+	// Targets are the registries to push the image to. The image is
+	// built and pushed to the first target, and copied to the others.
+	// Defaults to the registry configured on the command line.
+	Targets []*Target `yaml:"targets"`
+	// Verify configures the gates the release must pass before
+	// the image is pushed.
+	Verify Verify `yaml:"verify"`
+	// Webhooks are notified when the release is published or fails,
+	// in addition to the webhooks configured on the command line.
+	Webhooks []*Webhook `yaml:"webhooks"`
+	// Runtime describes what the service needs to run, to render
+	// Kubernetes manifests for it, if set.
+	Runtime *Runtime `yaml:"runtime"`
+}
+
+// Runtime describes what a service needs to run.
+type Runtime struct {
+	// Replicas is the number of replicas to run. Defaults to 1.
+	Replicas int `yaml:"replicas"`
+	// Ports are the ports the entrypoint listens on.
+	Ports []*Port `yaml:"ports"`
+	// Env are environment variables to set, from a ConfigMap.
+	Env map[string]string `yaml:"env"`
+	// Flags are flags to pass to the entrypoint, without the leading dashes.
+	Flags map[string]string `yaml:"flags"`
+	// Secrets are environment variables to set from Kubernetes secrets.
+	Secrets   []*SecretRef `yaml:"secrets"`
+	Resources Resources    `yaml:"resources"`
+	Probes    Probes       `yaml:"probes"`
+	// Dependencies are the backing services and other deployments the
+	// service needs, started by the local development environment.
+	Dependencies []*Dependency `yaml:"dependencies"`
+}
+
+// Port is a port the entrypoint listens on.
+type Port struct {
+	Name string `yaml:"name"`
+	Port int    `yaml:"port"`
+	// Flag is the flag to pass the port to the entrypoint with, if any.
+	Flag string `yaml:"flag"`
+}
+
+// SecretRef sets an environment variable from a key of a Kubernetes secret.
+type SecretRef struct {
+	Env    string `yaml:"env"`
+	Secret string `yaml:"secret"`
+	Key    string `yaml:"key"`
+	// Flag is the flag to pass the value to the entrypoint with, if any.
+	// The value is passed by reference to the environment variable,
+	// so that it does not appear in the manifests.
+	Flag string `yaml:"flag"`
+	// Local is the value of the secret in the local development environment.
+	Local string `yaml:"local"`
+}
+
+// The types of dependencies.
+const (
+	DependencyPostgres = "postgres"
+	DependencyRedis    = "redis"
+	// DependencyService is another deployment of the repository.
+	DependencyService = "service"
+)
+
+// Dependency is a service the deployment needs to run. The local development
+// environment starts it, and passes its address to the flag or environment
+// variable, overriding any secret with the same flag or environment variable.
+type Dependency struct {
+	// Name is the name of the dependency in the local development
+	// environment. Deployments depending on the same name share it.
+	// Defaults to the type, or the service of service dependencies.
+	Name string `yaml:"name"`
+	// Type is one of postgres, redis or service.
+	Type string `yaml:"type"`
+	// Image is the image of postgres and redis dependencies,
+	// such as postgres:13-alpine. Defaults to a recent version.
+	Image string `yaml:"image"`
+	// Service and Port are the name of the deployment and the name
+	// of its port that service dependencies connect to.
+	Service string `yaml:"service"`
+	Port    string `yaml:"port"`
+	// Flag and Env are the flag and environment variable to pass the
+	// address of the dependency with. Postgres and redis addresses are
+	// URLs, and services are addressed as host:port.
+	Flag string `yaml:"flag"`
+	Env  string `yaml:"env"`
+}
+
+// Resources are the compute resources of the entrypoint,
+// in Kubernetes quantities such as 100m or 64Mi.
+type Resources struct {
+	Requests ResourceList `yaml:"requests"`
+	Limits   ResourceList `yaml:"limits"`
+}
+
+// ResourceList lists quantities of compute resources.
+type ResourceList struct {
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+}
+
+// Probes are the probes Kubernetes checks the entrypoint with.
+type Probes struct {
+	Liveness  *Probe `yaml:"liveness"`
+	Readiness *Probe `yaml:"readiness"`
+	Startup   *Probe `yaml:"startup"`
+}
+
+// Probe checks the entrypoint with an HTTP GET request of the path,
+// or by opening a TCP connection if there is no path.
+type Probe struct {
+	// Port is the name of a port of the runtime.
+	Port string `yaml:"port"`
+	Path string `yaml:"path"`
+	// InitialDelay and Period default to the Kubernetes defaults.
+	InitialDelay     time.Duration `yaml:"initialDelay"`
+	Period           time.Duration `yaml:"period"`
+	FailureThreshold int           `yaml:"failureThreshold"`
+}
+
+// Webhook describes a URL notified of releases.
+type Webhook struct {
+	// URL is the URL to post to. Environment variables such as
+	// $SLACK_WEBHOOK_URL are expanded when the webhook is notified.
+	URL string `yaml:"url"`
+	// Secret is the name of the environment variable holding
+	// the secret to sign the payload with, if any.
+	Secret string `yaml:"secret"`
+	// Events are the events to notify the webhook of,
+	// published or failed. Defaults to both.
+	Events []string `yaml:"events"`
+}
+
+// Target describes a registry the image is pushed to.
+type Target struct {
+	// Name identifies the target in reports. Defaults to the registry.
+	Name string `yaml:"name"`
+	// Registry is the registry to push the image to. Can include any subpaths.
+	Registry string `yaml:"registry"`
+	// Tags are templates for the tags to push the image with.
+	// Defaults to the tags of the deployment.
+	Tags []string `yaml:"tags"`
+	// Credentials is the prefix of the environment variables holding the
+	// user and password for the registry, such as MIRROR for MIRROR_USER
+	// and MIRROR_PASSWORD. Defaults to the credentials on the command line.
+	Credentials string `yaml:"credentials"`
+}
+
+// Binary describes a binary built into the image.
+type Binary struct {
+	// Main is the path of the main package, relative to the repository root.
+	Main string `yaml:"main"`
+	// Path is the path of the binary inside the image.
+	// Defaults to the name of the main package directory, in the root.
+	Path string `yaml:"path"`
+	// Entrypoint marks the binary run by the image.
+	Entrypoint bool `yaml:"entrypoint"`
+}
+
+// Mains returns the paths of the main packages of the binaries.
+func (d *Deployment) Mains() []string {
+	mains := make([]string, 0, len(d.Binaries))
+	for _, b := range d.Binaries {
+		mains = append(mains, b.Main)
+	}
+
+	return mains
 }
 
-// Parse parses the deploy.yaml file at the path
+// Verify describes the verification gates of a deployment.
+type Verify struct {
+	// Test runs go test on the packages of the repository
+	// that the main package depends on.
+	Test bool `yaml:"test"`
+	// FailOnSkip fails the test gate if any test is skipped, such as
+	// a test needing Docker on an agent without it.
+	FailOnSkip bool `yaml:"failOnSkip"`
+	// Vet runs go vet on the same packages.
+	Vet bool `yaml:"vet"`
+	// Smoke runs the built binary, if set.
+	Smoke *Smoke `yaml:"smoke"`
+	// MaxImageSize is the size budget of the image, if set.
+	MaxImageSize ByteSize `yaml:"maxImageSize"`
+	// Vulnerabilities configures the check of the modules built into
+	// the image against the vulnerability database, if one is given.
+	Vulnerabilities Vulnerabilities `yaml:"vulnerabilities"`
+}
+
+// Vulnerabilities configures the vulnerability check of a deployment.
+type Vulnerabilities struct {
+	// Severity is the lowest severity that blocks the release, one of low,
+	// moderate (or its alias medium, as used by some databases), high or critical.
+	// Vulnerabilities below it are reported. None reports every vulnerability
+	// without blocking. Defaults to critical.
+	Severity string `yaml:"severity"`
+	// BlockUnknown blocks the release on vulnerabilities without a severity,
+	// such as those of the Go vulnerability database.
+	BlockUnknown bool `yaml:"blockUnknown"`
+	// Ignore lists the IDs or aliases, such as CVE IDs,
+	// of vulnerabilities that never block the release.
+	Ignore []string `yaml:"ignore"`
+}
+
+// severities are the valid values of Vulnerabilities.Severity.
+var severities = map[string]bool{
+	"":         true,
+	"none":     true,
+	"low":      true,
+	"moderate": true,
+	"medium":   true,
+	"high":     true,
+	"critical": true,
+}
+
+// Smoke describes a smoke run of the built binary.
+type Smoke struct {
+	// Args are the arguments to run the binary with, such as --help.
+	Args []string `yaml:"args"`
+	// ExitCode is the expected exit code of the binary.
+	ExitCode int `yaml:"exitCode"`
+	// Timeout is the time the binary has to exit in. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ByteSize is a size in bytes. It is parsed from a YAML integer, or a
+// string with a decimal (kB, MB, GB) or binary (KiB, MiB, GiB) unit.
+type ByteSize int64
+
+var byteUnits = []struct {
+	Suffix string
+	Size   float64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"kB", 1e3},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as 50MB or 1.5GiB.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	size := 1.0
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(s, unit.Suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.Suffix))
+			size = unit.Size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return ByteSize(n * size), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+
+	*b = size
+	return nil
+}
+
+// String formats the size with a decimal unit.
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1e9:
+		return fmt.Sprintf("%.1fGB", float64(b)/1e9)
+	case b >= 1e6:
+		return fmt.Sprintf("%.1fMB", float64(b)/1e6)
+	case b >= 1e3:
+		return fmt.Sprintf("%.1fkB", float64(b)/1e3)
+	default:
+		return fmt.Sprintf("%dB", b)
+	}
+}
+
+// Parse parses the deploy.yaml file at the path, relative to the repository root.
 func Parse(repoRoot, path string) (_ *Deployment, err error) {
-	f, err := os.Open(path)
+	f, err := os.Open(filepath.Join(repoRoot, path))
 	if err != nil {
 		return nil, fmt.Errorf("open the deploy file: %w", err)
 	}
@@ -27,15 +330,206 @@ func Parse(repoRoot, path string) (_ *Deployment, err error) {
 		}
 	}()
 
-	name := filepath.Dir(path)
+	return decode(f, path, false)
+}
 
+// decode decodes the deploy file read from r, defaulting the main package
+// to the directory of the path. Strict decoding rejects unknown fields.
+func decode(r io.Reader, path string, strict bool) (*Deployment, error) {
 	dc := Deployment{
-		Main: name,
+		Main: filepath.Dir(path),
+	}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(strict)
+	err := dec.Decode(&dc)
+	if err != nil {
+		return nil, fmt.Errorf("parse the deploy file: %w", err)
 	}
-	err = yaml.NewDecoder(f).Decode(&dc)
+
+	err = dc.setBinaries()
 	if err != nil {
 		return nil, fmt.Errorf("parse the deploy file: %w", err)
 	}
 
+	err = dc.setTargets()
+	if err != nil {
+		return nil, fmt.Errorf("parse the deploy file: %w", err)
+	}
+
+	err = dc.setWebhooks()
+	if err != nil {
+		return nil, fmt.Errorf("parse the deploy file: %w", err)
+	}
+
+	err = dc.setRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("parse the deploy file: %w", err)
+	}
+
+	severity := strings.ToLower(dc.Verify.Vulnerabilities.Severity)
+	if !severities[severity] {
+		return nil, fmt.Errorf("parse the deploy file: unknown vulnerability severity %q", severity)
+	}
+	dc.Verify.Vulnerabilities.Severity = severity
+
 	return &dc, nil
 }
+
+// setBinaries defaults and validates the binaries of the deployment.
+func (d *Deployment) setBinaries() error {
+	if len(d.Binaries) == 0 {
+		d.Binaries = []*Binary{{
+			Main: d.Main,
+			Path: DefaultBinaryPath,
+		}}
+	}
+	if len(d.Binaries) == 1 {
+		d.Binaries[0].Entrypoint = true
+	}
+
+	paths := map[string]bool{}
+	var entrypoints int
+	for _, b := range d.Binaries {
+		if b.Main == "" {
+			return errors.New("binaries must specify a main package")
+		}
+		if b.Path == "" {
+			b.Path = "/" + filepath.Base(b.Main)
+		}
+		if !path.IsAbs(b.Path) {
+			return fmt.Errorf("binary path %q is not absolute", b.Path)
+		}
+		if paths[b.Path] {
+			return fmt.Errorf("binary path %q is used more than once", b.Path)
+		}
+		paths[b.Path] = true
+		if b.Entrypoint {
+			entrypoints++
+		}
+	}
+	if entrypoints != 1 {
+		return fmt.Errorf("exactly one binary must be the entrypoint, got %d", entrypoints)
+	}
+
+	return nil
+}
+
+// setTargets defaults and validates the targets of the deployment.
+func (d *Deployment) setTargets() error {
+	names := map[string]bool{}
+	for _, t := range d.Targets {
+		if t.Registry == "" {
+			return errors.New("targets must specify a registry")
+		}
+		if t.Name == "" {
+			t.Name = t.Registry
+		}
+		if names[t.Name] {
+			return fmt.Errorf("target name %q is used more than once", t.Name)
+		}
+		names[t.Name] = true
+	}
+
+	return nil
+}
+
+// setWebhooks validates the webhooks of the deployment. Their URLs are
+// expanded when they are notified, so that deploy files can be parsed
+// without the environment of the release.
+func (d *Deployment) setWebhooks() error {
+	for _, w := range d.Webhooks {
+		if w.URL == "" {
+			return errors.New("webhooks must specify a url")
+		}
+		for _, e := range w.Events {
+			if e != "published" && e != "failed" {
+				return fmt.Errorf("unknown webhook event %q", e)
+			}
+		}
+	}
+
+	return nil
+}
+
+// envName matches valid environment variable names.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// setRuntime defaults and validates the runtime of the deployment.
+func (d *Deployment) setRuntime() error {
+	r := d.Runtime
+	if r == nil {
+		return nil
+	}
+	if r.Replicas == 0 {
+		r.Replicas = 1
+	}
+	if r.Replicas < 0 {
+		return fmt.Errorf("replicas must not be negative, got %d", r.Replicas)
+	}
+
+	ports := map[string]bool{}
+	for _, p := range r.Ports {
+		if p.Name == "" {
+			return errors.New("ports must specify a name")
+		}
+		if p.Port < 1 || p.Port > 65535 {
+			return fmt.Errorf("port %s must be between 1 and 65535, got %d", p.Name, p.Port)
+		}
+		if ports[p.Name] {
+			return fmt.Errorf("port name %q is used more than once", p.Name)
+		}
+		ports[p.Name] = true
+	}
+
+	for name := range r.Env {
+		if !envName.MatchString(name) {
+			return fmt.Errorf("%q is not a valid environment variable name", name)
+		}
+	}
+
+	for _, s := range r.Secrets {
+		if s.Env == "" || s.Secret == "" || s.Key == "" {
+			return errors.New("secrets must specify an env, secret and key")
+		}
+		if !envName.MatchString(s.Env) {
+			return fmt.Errorf("%q is not a valid environment variable name", s.Env)
+		}
+	}
+
+	probes := map[string]*Probe{
+		"liveness":  r.Probes.Liveness,
+		"readiness": r.Probes.Readiness,
+		"startup":   r.Probes.Startup,
+	}
+	for kind, p := range probes {
+		if p != nil && !ports[p.Port] {
+			return fmt.Errorf("%s probe port %q is not a port of the runtime", kind, p.Port)
+		}
+	}
+
+	for _, dep := range r.Dependencies {
+		switch dep.Type {
+		case DependencyPostgres, DependencyRedis:
+			if dep.Name == "" {
+				dep.Name = dep.Type
+			}
+		case DependencyService:
+			if dep.Service == "" || dep.Port == "" {
+				return errors.New("service dependencies must specify a service and port")
+			}
+			if dep.Name == "" {
+				dep.Name = dep.Service
+			}
+		default:
+			return fmt.Errorf("unknown dependency type %q", dep.Type)
+		}
+		if dep.Flag == "" && dep.Env == "" {
+			return fmt.Errorf("dependency %s must specify a flag or env", dep.Name)
+		}
+		if dep.Env != "" && !envName.MatchString(dep.Env) {
+			return fmt.Errorf("%q is not a valid environment variable name", dep.Env)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package deploy_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deploy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Deploy files are parsed at paths relative to the repository root.
+	main := filepath.Join("cmd", "user-api")
+	err = os.MkdirAll(filepath.Join(dir, main), 0o755)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	app := []*deploy.Binary{{Main: main, Path: deploy.DefaultBinaryPath, Entrypoint: true}}
+
+	tests := []struct {
+		Name    string
+		Content string
+		Want    *deploy.Deployment
+		WantErr bool
+	}{
+		{
+			Name:    "It defaults the main package to the directory of the file",
+			Content: "name: user-api\n",
+			Want:    &deploy.Deployment{Main: main, Name: "user-api", Binaries: app},
+		},
+		{
+			Name: "It parses verification gates",
+			Content: `name: user-api
+verify:
+  test: true
+  vet: true
+  smoke:
+    args: ["--help"]
+    exitCode: 2
+    timeout: 30s
+  maxImageSize: 1.5MiB
+`,
+			Want: &deploy.Deployment{
+				Main:     main,
+				Name:     "user-api",
+				Binaries: app,
+				Verify: deploy.Verify{
+					Test:         true,
+					Vet:          true,
+					Smoke:        &deploy.Smoke{Args: []string{"--help"}, ExitCode: 2, Timeout: 30 * time.Second},
+					MaxImageSize: 1572864,
+				},
+			},
+		},
+		{
+			Name:    "It parses sizes without a unit as bytes",
+			Content: "name: user-api\nverify:\n  maxImageSize: 50000000\n",
+			Want: &deploy.Deployment{
+				Main:     main,
+				Name:     "user-api",
+				Binaries: app,
+				Verify:   deploy.Verify{MaxImageSize: 50 * 1e6},
+			},
+		},
+		{
+			Name:    "It leaves environment variables in webhook URLs to be expanded when notifying",
+			Content: "name: user-api\nwebhooks:\n  - url: $DEPLOY_TEST_UNSET_WEBHOOK_URL\n",
+			Want: &deploy.Deployment{
+				Main:     main,
+				Name:     "user-api",
+				Binaries: app,
+				Webhooks: []*deploy.Webhook{{URL: "$DEPLOY_TEST_UNSET_WEBHOOK_URL"}},
+			},
+		},
+		{
+			Name: "It parses several binaries",
+			Content: `name: user-api
+binaries:
+  - main: cmd/user-api
+    entrypoint: true
+  - main: cmd/user-api-migrate
+    path: /usr/local/bin/migrate
+`,
+			Want: &deploy.Deployment{
+				Main: main,
+				Name: "user-api",
+				Binaries: []*deploy.Binary{
+					{Main: "cmd/user-api", Path: "/user-api", Entrypoint: true},
+					{Main: "cmd/user-api-migrate", Path: "/usr/local/bin/migrate"},
+				},
+			},
+		},
+		{
+			Name:    "It requires an entrypoint when there are several binaries",
+			Content: "name: user-api\nbinaries:\n  - main: cmd/user-api\n  - main: cmd/user-api-migrate\n",
+			WantErr: true,
+		},
+		{
+			Name:    "It rejects relative binary paths",
+			Content: "name: user-api\nbinaries:\n  - main: cmd/user-api\n    path: app\n",
+			WantErr: true,
+		},
+		{
+			Name:    "It rejects invalid sizes",
+			Content: "name: user-api\nverify:\n  maxImageSize: 50 potatoes\n",
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			path := filepath.Join(main, "deploy.yml")
+			err := ioutil.WriteFile(filepath.Join(dir, path), []byte(test.Content), 0o600)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := deploy.Parse(dir, path)
+			if test.WantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Errorf("unexpected deployment (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
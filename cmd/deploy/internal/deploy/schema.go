@@ -0,0 +1,232 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy/static"
+)
+
+// Schema returns the JSON schema of deploy files. Editors supporting
+// JSON schemas for YAML files can use it to validate deploy files.
+func Schema() []byte {
+	return static.MustAsset("deploy.schema.json")
+}
+
+// schema is the subset of JSON schema used by the schema of deploy files.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaType         `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	MinItems             int                `json:"minItems"`
+	MinLength            int                `json:"minLength"`
+	Pattern              string             `json:"pattern"`
+	Enum                 []interface{}      `json:"enum"`
+	Definitions          map[string]*schema `json:"definitions"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaType is a type or a list of types.
+type schemaType []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = schemaType{s}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// deploySchema is the parsed schema of deploy files.
+var deploySchema = mustParseSchema(Schema())
+
+func mustParseSchema(data []byte) *schema {
+	var s schema
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		panic(fmt.Sprintf("parse deploy file schema: %v", err))
+	}
+
+	err = s.compile(&s)
+	if err != nil {
+		panic(fmt.Sprintf("compile deploy file schema: %v", err))
+	}
+
+	return &s
+}
+
+// compile resolves the references and compiles the patterns of the schema.
+func (s *schema) compile(root *schema) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		def, ok := root.Definitions[name]
+		if !ok {
+			return fmt.Errorf("unknown reference %q", s.Ref)
+		}
+		*s = *def
+	}
+
+	if s.Pattern != "" {
+		var err error
+		s.pattern, err = regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, prop := range s.Properties {
+		if err := prop.compile(root); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(root)
+	}
+
+	return nil
+}
+
+// validate validates the value decoded from YAML against the schema,
+// returning a problem for every violation.
+func (s *schema) validate(field string, v interface{}) []*Problem {
+	problem := func(format string, args ...interface{}) []*Problem {
+		return []*Problem{{Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		return problem("must be of type %s", strings.Join(s.Type, " or "))
+	}
+	if len(s.Enum) > 0 {
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				return nil
+			}
+		}
+		return problem("must be one of %s", enumString(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case string:
+		if len(v) < s.MinLength {
+			return problem("must not be empty")
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return problem("%q does not match %s", v, s.Pattern)
+		}
+	case []interface{}:
+		if len(v) < s.MinItems {
+			return problem("must have at least %d items", s.MinItems)
+		}
+		var problems []*Problem
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item)...)
+			}
+		}
+		return problems
+	case map[string]interface{}:
+		return s.validateObject(field, v)
+	}
+
+	return nil
+}
+
+func (s *schema) validateObject(field string, v map[string]interface{}) []*Problem {
+	var problems []*Problem
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			problems = append(problems, &Problem{Field: join(field, name), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prop, ok := s.Properties[key]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				problems = append(problems, &Problem{Field: join(field, key), Message: "is not a known field"})
+			}
+			continue
+		}
+		problems = append(problems, prop.validate(join(field, key), v[key])...)
+	}
+
+	return problems
+}
+
+// matches returns true if the value is of one of the types.
+func (t schemaType) matches(v interface{}) bool {
+	for _, typ := range t {
+		switch v := v.(type) {
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case int, int64, uint64:
+			if typ == "integer" || typ == "number" {
+				return true
+			}
+		case float64:
+			if typ == "number" || typ == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func enumString(enum []interface{}) string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		if s, ok := e.(string); ok {
+			values = append(values, strconv.Quote(s))
+			continue
+		}
+		values = append(values, fmt.Sprint(e))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+
+	return field + "." + key
+}
@@ -0,0 +1,274 @@
+// Code generated by go-bindata. DO NOT EDIT.
+//  memcopy: true
+//  compress: true
+//  decompress: once
+//  asset-dir: true
+//  restore: true
+// sources:
+//  static/deploy.schema.json
+
+package static
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmthrgd/go-bindata/restore"
+)
+
+type asset struct {
+	name string
+	data string
+	size int64
+
+	once  sync.Once
+	bytes []byte
+	err   error
+}
+
+func (a *asset) Name() string {
+	return a.name
+}
+
+func (a *asset) Size() int64 {
+	return a.size
+}
+
+func (a *asset) Mode() os.FileMode {
+	return 0
+}
+
+func (a *asset) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (*asset) IsDir() bool {
+	return false
+}
+
+func (*asset) Sys() interface{} {
+	return nil
+}
+
+// _bindata is a table, holding each asset generator, mapped to its name.
+var _bindata = map[string]*asset{
+	"deploy.schema.json": &asset{
+		name: "deploy.schema.json",
+		data: "" +
+			"\xec\x5a\x5d\x6f\xdb\x38\xd6\xbe\xcf\xaf\x38\xd0\xe4\x22\x99\xfa\x23\xc5\xbc\xef\x2e\x26\x37\xc5" +
+			"\xb8\x33\xdb\x1d\x74\x83\x2d\x30\x59\x0c\xb0\x6d\x36\xa0\xa4\x63\xe9\x8c\x29\x52\x4b\x52\x4e\xdd" +
+			"\x71\xfe\xfb\x82\x94\x64\xcb\x32\x65\xcb\x49\x9d\xe6\x22\x57\x96\x29\x7e\x9c\x8f\xe7\x39\xe7\x90" +
+			"\xd4\x9f\x27\x00\xc1\xa9\x8e\x52\xcc\x58\x70\x09\x41\x6a\x4c\x7e\x39\x1e\xff\xa1\xa5\x18\x96\xad" +
+			"\x23\xa9\x92\x71\xac\xd8\xd4\x0c\x2f\xfe\x3a\x2e\xdb\xbe\x0b\x06\x6e\x1c\xc5\xf5\x18\x7d\x39\x1e" +
+			"\x27\x64\xd2\x22\x1c\x45\x32\x1b\x17\x77\x43\xce\x42\x3d\x4e\xe4\x30\x93\x42\x8e\x43\x2e\xc3\x71" +
+			"\xc6\xb4\x41\x35\x8e\xb2\x78\x1c\x63\xce\xe5\x62\x4c\xc2\xa0\x12\x8c\xd7\xff\xb5\x61\x86\xa2\xea" +
+			"\xdf\xa8\x5a\xdf\xca\x52\xae\x67\xc8\x70\xb4\x2b\x56\x1d\x16\x19\x2f\x5f\xc4\xa8\x23\x45\xb9\x21" +
+			"\x29\xec\xeb\xeb\x14\x21\x92\x62\x4a\x49\xa1\x98\x6d\x04\x39\x05\x26\x80\x32\x96\x20\x84\x05\x71" +
+			"\x03\x4c\xc4\x90\x17\x21\x27\x9d\x62\x0c\xe1\x02\xd6\x62\x8d\xaa\xd5\x16\xb9\x5b\x4c\x86\x7f\x60" +
+			"\x64\xca\x36\x16\xc7\x64\x27\x64\xfc\x83\x92\x39\x2a\x43\xa8\x83\x4b\x98\x32\xae\xd1\x75\x50\xf8" +
+			"\xdf\x82\x14\x5a\xbb\x7c\x0c\x04\xcb\x30\xb8\x71\xed\x79\xb3\xfb\x9f\x27\x00\x00\x41\xc6\x48\xac" +
+			"\xfe\xf9\xb5\xc8\x99\x49\xad\xf0\x26\x45\xb0\xdd\x21\x67\xd1\x8c\x25\x38\x00\x85\x9c\x19\x9a\x23" +
+			"\x18\xe9\xde\x2a\xcc\xa5\x26\x23\xd5\x02\x94\x94\x66\x04\x3f\xe3\x94\x15\xdc\xe8\xba\x43\x4c\x0a" +
+			"\x23\xf7\xbe\x9a\xaf\x54\x16\xa6\xc4\xb1\xd4\x18\xa0\xa1\xb5\x36\x8a\x44\xb2\x6e\xcf\x48\xfc\x03" +
+			"\x45\x62\xd2\xe0\x12\x5e\x9f\x00\x00\xdc\x97\xef\x4a\x2d\x77\xab\x61\xbb\xd4\xcb\x3a\x27\xf4\x58" +
+			"\x30\x67\xc6\x82\xc3\xbe\xfa\xcf\x47\x36\xfc\x72\x31\xfc\xf1\xe6\xd5\xd9\xd9\xa7\x4f\xa3\xe5\xed" +
+			"\xf2\xf6\x76\x39\x7c\x75\xbe\x6a\x3e\xff\xfe\x6c\xbc\xbf\xcf\xf9\xf7\xa7\xc1\x86\xe4\x21\x09\xa6" +
+			"\x9a\x2e\xf1\x4b\x5f\x77\x03\x23\x1d\x78\x62\x20\x61\xe4\x4e\x65\x98\x52\x6c\xb1\x61\xbc\x5f\x0d" +
+			"\x66\x76\xa1\xd7\xab\x46\xaa\x5a\xea\xa5\xfd\x90\x03\x00\xe8\x01\x3c\x00\x80\x36\xfc\x1c\xbe\x6e" +
+			"\x1a\x6f\x3d\x20\xac\x05\xdc\x84\xe2\x31\x00\x19\x0c\x36\x27\xef\xf0\x7b\x27\xdc\x00\x1a\xae\x5b" +
+			"\x63\x24\xed\x25\x36\x0b\xb5\xe4\x85\xd9\x94\xdf\xf9\x75\x01\x24\x34\xc5\xe8\x73\x67\x2f\x49\x9b" +
+			"\x38\x1d\x07\x9d\xa2\xa2\x30\x6a\x91\x4b\x12\x66\xaf\xc0\xbf\xa7\x68\x52\x54\x6b\x89\x40\x15\x42" +
+			"\x37\x44\xee\x94\x30\x94\x92\x23\x13\x1b\x52\x9c\xb4\x9f\xee\x37\x38\x60\x58\xb2\x13\xff\x98\xe5" +
+			"\x9c\x19\xd4\x30\x95\xa5\x44\x76\x00\x18\x09\x79\xa1\xd3\x86\x88\x77\x64\xd2\x06\x11\x4e\x15\x4e" +
+			"\xed\xf8\xef\xc6\x31\x4e\x49\x38\xec\xea\xb1\xa9\x67\x0b\x5a\x32\xa8\x04\xcd\x3e\x1a\x2a\x4c\x48" +
+			"\x9b\x9a\x88\xad\xe5\x8d\xdc\xcf\xc2\xa3\x13\xae\x92\x70\xd1\x93\x74\xad\xc0\xf9\x15\x79\xb1\x12" +
+			"\xe4\x28\xb3\xb7\x20\x73\x88\xc7\x7d\xd3\x45\x0a\x63\x14\x86\x18\xd7\xfd\x42\x90\xc2\x29\x7d\xae" +
+			"\x49\x8c\x62\x4e\x4a\x8a\x0c\x85\x81\x39\x53\xc4\x42\x8e\x1a\x52\xc9\x63\x12\x89\xeb\x51\x68\x54" +
+			"\x65\xba\x67\x5a\xdf\x49\x15\xd7\x43\x6b\x2b\x3d\x82\xf0\x1f\x7f\x1a\xfe\x9b\x0d\xbf\xdc\xde\x54" +
+			"\x0f\x17\xc3\x1f\x6f\x6f\xea\x34\xd3\x8f\x80\x73\x54\x34\x5d\xec\xc1\x7e\x62\x4d\x58\x09\xcd\x91" +
+			"\x69\x84\xac\xd0\xc6\x69\x04\x21\x4e\xa5\x6a\xc4\x2f\x20\xed\xd8\x81\xb1\x87\x12\x2d\xa0\xf7\x82" +
+			"\x79\x17\x84\x03\x83\xba\x1d\xcd\xba\x43\x51\xc3\xe9\xc1\x94\x11\xff\xa7\xf8\x6d\x46\x79\x7b\x78" +
+			"\x4b\xf7\xbf\x31\xe2\x65\xd4\x41\x6d\x9c\x15\x80\x6c\x29\xb7\x28\x1b\x48\x83\x9e\x51\x9e\x37\x55" +
+			"\xed\x2f\xc5\x1c\x1f\x26\xbd\xce\xe4\x0c\xbb\x46\xb6\x0c\xdc\xdb\xc8\xbb\x0c\x5d\x4e\xa2\x3c\xac" +
+			"\xeb\x0c\x76\x00\x9d\x41\xaf\x0b\xe6\xad\x0e\xf7\x1b\xff\xef\x5b\x24\xc0\xcf\x64\xde\xca\x18\x77" +
+			"\x49\x64\xab\xfb\x04\x55\xb0\x73\x22\x43\x19\xca\xc2\xf8\xe6\xf1\x47\x94\xb8\xaa\xe9\x5b\xd3\xfa" +
+			"\x19\xd7\xf0\x5a\xc6\x3e\xff\x6a\xe9\xf1\x1b\x7d\xc1\x3d\xa8\xb3\x8c\xd3\xf4\xc5\x6e\x15\xe2\x04" +
+			"\xcd\x46\xe5\x3a\x00\x12\x10\x2e\x2c\x1d\xa5\x72\x59\x0f\x18\x14\x82\x0c\xe8\x22\x4a\x81\x69\xf8" +
+			"\xff\x8b\xab\x89\x7d\xf7\x97\xff\xbb\xa2\x89\x1f\x97\x1f\x57\xb6\x19\xac\xcc\x7f\x33\x38\xe9\x0a" +
+			"\x32\x65\x59\xfb\xe9\xd3\xa8\x7c\x3a\x7f\x03\x6f\xce\x26\xcb\xd9\x64\xf9\x7e\xb2\xbc\x9a\x2c\xdf" +
+			"\x4d\x96\xef\x69\xb2\xbc\xa2\xc9\xf2\x1d\x4d\xce\xdf\x9c\x76\xc0\xbd\xe0\x02\x15\x0b\x89\x93\x2f" +
+			"\x1b\x1d\x1d\xc0\x1a\x6d\xa8\x33\x0b\x9f\xab\x3d\x0e\xe0\xf2\xce\x52\xbc\x1e\x05\x26\x65\x06\x42" +
+			"\x2e\xa3\xd9\x46\x20\x1c\x80\x54\x20\xa4\x40\x30\x12\xa4\xe0\x0b\x57\x78\x2a\x03\x2d\x75\x47\x70" +
+			"\x85\x31\x15\x19\x90\x06\x26\x80\x71\x62\xda\x3a\x36\x93\x31\x2a\x66\x70\x73\xc3\x14\x29\x32\x14" +
+			"\x31\x3e\xda\x26\x14\x8a\x22\x2b\x77\x77\x52\xa0\xf5\x1f\x97\x77\xf6\xa7\x9e\xc8\x3d\xbb\xa5\xec" +
+			"\x53\x4a\x49\x6a\x7f\xeb\x09\x83\x9b\x9d\x6c\x70\xfa\xfd\x4b\xcc\x84\xbc\x13\xbb\xa8\x15\x6e\x17" +
+			"\x7a\xdb\x93\x51\x22\xa4\xc2\xa3\xc6\x8c\xc1\x76\x8f\xae\x1a\xc2\x13\x53\x0e\xc8\x92\x77\x18\xa6" +
+			"\x52\xce\xf6\xd5\x88\x75\x37\x30\x12\x84\x34\x34\x75\xbb\xdd\x0a\x2a\xfa\x19\x94\x88\x85\xe2\x3d" +
+			"\xab\x43\xdb\xf3\x28\xe5\x9b\xc6\x48\xa1\xe9\x55\x6a\x35\xf7\xed\xbe\x42\x6b\xa3\xce\x2a\xe7\x05" +
+			"\x23\x41\x53\x62\x37\x86\x0b\x2e\x59\xac\x5b\x3b\x83\xa3\x94\x58\x1b\xfa\xe1\x1c\x85\xd1\xdd\xb6" +
+			"\xf3\x81\xbe\x13\xf2\x6b\xba\xaf\x0e\x8b\x82\x41\x59\xc0\x60\xdc\x26\xf3\x01\x78\x56\x85\xb0\x99" +
+			"\x6f\x07\x9c\x7f\xb7\xf1\xae\xb4\xab\x9a\x53\x84\x20\x10\x63\x07\x6c\x55\x88\x81\xfb\x45\x11\xa3" +
+			"\x82\xf7\x45\x88\x4a\xa0\x41\x0d\x19\x13\x34\x45\x6d\xb4\xab\x77\x55\x21\x80\x0c\x70\x19\x31\xce" +
+			"\x17\xc7\x2e\x06\x15\xe6\x9c\x22\xd6\x99\x57\xb6\xaa\x81\x66\x6e\xb2\x11\xbb\x73\xe4\xb6\xc3\x3a" +
+			"\xdc\xb5\x2b\x87\x1d\x90\xc5\xbc\xc7\x78\x83\x52\xc8\xcd\x34\xbd\x3b\xe3\x75\x6d\xf1\xbe\x46\x20" +
+			"\x6d\xf7\x2f\x85\xdb\xb9\xd2\x96\x03\xba\xa6\x9a\x72\x96\xf8\xa7\xf2\x84\x08\xdb\xd9\xed\xc7\xed" +
+			"\x56\xc4\xc2\xd5\x0a\x52\x9f\x01\xad\x0f\x3d\xbc\x51\xe0\xa9\x32\xca\x7a\xce\x00\xc5\x7c\x4f\xf1" +
+			"\xf7\x8b\x77\x43\x69\xa3\x1a\x1a\x98\x2a\x99\x01\x83\xb7\xee\x70\xf9\x8a\xe5\x1d\xdb\x8e\x0a\x7f" +
+			"\xfe\xbd\x0f\xdf\xde\x3e\x6f\x6d\x7b\x78\x7d\xc8\xe2\xac\xda\x36\xe6\xc0\x59\x53\x16\x65\x7c\xe0" +
+			"\xc8\x5c\x0c\x8e\x99\x4e\x9b\x39\xae\xb7\x44\x65\xe0\x7e\xb6\xf4\xb3\x2e\x1b\xac\xd2\xd6\x00\x82" +
+			"\x19\x2e\x0e\xa4\xe1\xb6\xd7\x7b\x83\xef\xc0\x54\xe4\x67\x54\x47\xca\x3d\x4a\x20\xb0\xd6\x79\x92" +
+			"\x85\x1e\x17\x26\xe6\x8c\x17\xf8\x84\x71\x62\x4b\x7c\x97\x16\x7b\xcb\x5f\x8a\x5b\x95\x42\xa5\x3b" +
+			"\x81\x44\xc9\x3f\x3b\x11\xc4\x38\x47\x2e\x73\x17\x36\x1a\xa5\x52\x1f\x55\x1e\x19\xd2\x62\xcc\x6d" +
+			"\x21\x20\x22\x4f\x11\xe9\xbb\xcd\x60\xd1\xcc\x86\x8b\xaa\xac\x28\x4b\x05\xe9\x0e\x9c\xcb\x1b\x21" +
+			"\x2b\xb6\xde\xae\x3c\x06\xa0\x0d\x53\xa6\xbc\x2c\x3b\x50\xef\x67\x13\x4b\xdc\xac\x5f\x2b\x85\xef" +
+			"\x29\x98\x57\x7e\x59\xd8\xbd\xe5\xda\xb2\x65\xbb\x75\x81\x2c\x01\xa4\x59\x56\x8d\xd4\x29\x53\x08" +
+			"\x64\x1e\x48\x00\xdf\x95\x59\xf5\x7b\x3b\x1a\xf6\x8a\x54\xd5\x22\x1e\x6d\xd7\x85\xb0\xd4\x26\x51" +
+			"\xa8\x83\x01\x04\x0a\x63\xd2\x65\x74\x76\x50\x09\x6e\xf6\xae\xe0\x4e\x51\x7a\x1b\xd4\xf5\xb6\x16" +
+			"\xad\x57\x2d\x2b\x5b\xbb\x2c\x34\x81\xff\x24\x21\xa3\x56\xf2\x81\x68\xa8\x10\x00\x6c\x45\xac\x35" +
+			"\x44\xec\x7d\xb5\xc0\xc8\x9d\x3e\x3c\x89\x2e\xdd\xf5\xe2\x1e\x45\xec\xc0\xe7\xaa\xd4\xe3\x52\x12" +
+			"\x8b\x63\x85\x5a\x6f\xf3\xf7\xe9\x92\x52\x67\xa1\xe2\x91\xdf\xbb\x21\x3f\xba\x3e\x0f\xa9\x87\x0e" +
+			"\x4c\x68\x0a\xb5\x2c\x54\xf4\x0d\x8e\x28\x6d\xae\xb0\xdb\xe7\x5d\x67\x66\x9e\xc5\x0f\x12\x60\x9f" +
+			"\x10\x00\x00\x41\x94\x17\xde\x17\xcd\x23\xe4\xda\x47\xd0\x3c\x4c\x16\x45\x16\xa2\xda\x0a\xc3\x1e" +
+			"\xac\x01\x04\x19\x66\x52\x2d\x1e\xb0\x90\x67\xfa\x83\x6e\x0f\x38\x65\xf4\x62\xe5\xaf\x6e\xe5\xbd" +
+			"\xcc\xca\x95\x0c\xbf\x01\xad\x38\xcd\x51\xa0\x3e\xbe\xc3\x37\x6a\x3d\xdf\x71\x4d\x0f\x50\x74\x26" +
+			"\xc6\xdd\xa9\x91\x6d\x24\xc6\xea\x78\xcf\x17\x63\xfb\x44\xd9\x3d\x79\xc3\x8f\x33\xef\xc7\x30\xdd" +
+			"\x82\xdb\xee\xe5\xb1\x86\x88\xe1\xef\xd7\xd7\x1f\xe0\xdd\x2f\xd7\x50\x47\x40\x9b\xb1\xe1\x83\x83" +
+			"\xcb\xea\xb0\x81\x95\x63\x64\x8e\x02\x18\x5c\xbf\xfd\x50\xe7\x77\x92\xe2\x11\x9a\x76\x7e\x40\xd3" +
+			"\xad\xaa\xbb\x0c\x64\xfc\x67\xe4\xac\x93\x58\x87\xdc\x1e\x76\x9a\x14\x15\xc9\xf8\x98\x2b\xd8\x83" +
+			"\xe4\x42\xe1\x75\xaa\x50\xdb\xc3\xf4\x3d\x61\xa2\xeb\x10\xef\xd0\x00\xac\xdc\x99\xd1\x0b\x25\x5f" +
+			"\x28\xf9\x42\xc9\x67\x42\x49\x77\xba\x52\xe4\x2f\x84\x7c\x21\xe4\x0b\x21\xbf\x09\x21\xfb\x5c\xdf" +
+			"\x9e\x54\x22\x06\x0d\x75\x56\xf2\x04\xeb\xaf\x1d\xeb\xa6\x07\x7d\x6a\xb0\x85\x04\x2f\xd2\x37\x2f" +
+			"\x94\x57\x26\xed\xbe\x51\xfe\x09\xea\x4e\xab\x6f\x95\x7e\xb8\xd0\x20\x15\xbc\xce\x7e\xb8\xd0\x07" +
+			"\x7e\x93\x7f\xb6\xfd\x59\xd2\x99\xd0\xcb\x42\x2f\x33\xbd\xd4\xcb\x6c\x99\x9e\x9f\xbf\x3a\x0d\xd6" +
+			"\x66\x3b\xb9\x3f\xf9\xdf\x00",
+		size: 12910,
+	},
+}
+
+// AssetAndInfo loads and returns the asset and asset info for the
+// given name. It returns an error if the asset could not be found
+// or could not be loaded.
+func AssetAndInfo(name string) ([]byte, os.FileInfo, error) {
+	a, ok := _bindata[filepath.ToSlash(name)]
+	if !ok {
+		return nil, nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	a.once.Do(func() {
+		fr := flate.NewReader(strings.NewReader(a.data))
+
+		var buf bytes.Buffer
+		if _, a.err = io.Copy(&buf, fr); a.err != nil {
+			return
+		}
+
+		if a.err = fr.Close(); a.err == nil {
+			a.bytes = buf.Bytes()
+		}
+	})
+	if a.err != nil {
+		return nil, nil, &os.PathError{Op: "read", Path: name, Err: a.err}
+	}
+
+	return a.bytes, a, nil
+}
+
+// AssetInfo loads and returns the asset info for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func AssetInfo(name string) (os.FileInfo, error) {
+	a, ok := _bindata[filepath.ToSlash(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	return a, nil
+}
+
+// Asset loads and returns the asset for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func Asset(name string) ([]byte, error) {
+	data, _, err := AssetAndInfo(name)
+	return data, err
+}
+
+// MustAsset is like Asset but panics when Asset would return an error.
+// It simplifies safe initialization of global variables.
+func MustAsset(name string) []byte {
+	a, err := Asset(name)
+	if err != nil {
+		panic("asset: Asset(" + name + "): " + err.Error())
+	}
+
+	return a
+}
+
+// AssetNames returns the names of the assets.
+func AssetNames() []string {
+	names := make([]string, 0, len(_bindata))
+	for name := range _bindata {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// RestoreAsset restores an asset under the given directory
+func RestoreAsset(dir, name string) error {
+	return restore.Asset(dir, name, AssetAndInfo)
+}
+
+// RestoreAssets restores an asset under the given directory recursively
+func RestoreAssets(dir, name string) error {
+	return restore.Assets(dir, name, AssetDir, AssetAndInfo)
+}
+
+// AssetDir returns the file names below a certain
+// directory embedded in the file by go-bindata.
+// For example if you run go-bindata on data/... and data contains the
+// following hierarchy:
+//     data/
+//       foo.txt
+//       img/
+//         a.png
+//         b.png
+// then AssetDir("data") would return []string{"foo.txt", "img"}
+// AssetDir("data/img") would return []string{"a.png", "b.png"}
+// AssetDir("foo.txt") and AssetDir("notexist") would return an error
+// AssetDir("") will return []string{"data"}.
+func AssetDir(name string) ([]string, error) {
+	node := _bintree
+
+	if name != "" {
+		var ok bool
+		for _, p := range strings.Split(filepath.ToSlash(name), "/") {
+			if node, ok = node[p]; !ok {
+				return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+			}
+		}
+	}
+
+	if len(node) == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	rv := make([]string, 0, len(node))
+	for name := range node {
+		rv = append(rv, name)
+	}
+
+	return rv, nil
+}
+
+type bintree map[string]bintree
+
+var _bintree = bintree{
+	"deploy.schema.json": bintree{},
+}
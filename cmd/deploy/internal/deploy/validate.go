@@ -0,0 +1,172 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/uw-labs/go-mono/cmd/internal/deployfile"
+)
+
+// Problem is a problem found in a deploy file.
+type Problem struct {
+	// File is the path of the deploy file, relative to the repository root.
+	File string
+	// Field is the path of the field with the problem, if any, such as binaries[0].main.
+	Field   string
+	Message string
+}
+
+// String formats the problem as file: field: message.
+func (p *Problem) String() string {
+	if p.Field == "" {
+		return p.File + ": " + p.Message
+	}
+
+	return p.File + ": " + p.Field + ": " + p.Message
+}
+
+// FindFiles returns the paths of the deploy files in the repository,
+// relative to its root. Vendored and hidden directories are skipped.
+func FindFiles(repoRoot string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != repoRoot && (info.Name() == "vendor" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !deployfile.IsFileName(info.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find deploy files: %w", err)
+	}
+
+	return files, nil
+}
+
+// imageName matches valid image names, as defined by the distribution spec.
+var imageName = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+// Validate validates the deploy files at the paths, relative to the repository
+// root, returning the problems found. Each file is checked against the schema
+// of deploy files and decoded strictly, rejecting unknown fields. Names must be
+// valid image names, unique across the files, and the main packages of the
+// binaries must be main packages of the module at the repository root.
+// An error is returned only if a file cannot be read.
+func Validate(repoRoot string, files []string) ([]*Problem, error) {
+	var problems []*Problem
+	names := map[string]string{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(repoRoot, file))
+		if err != nil {
+			return nil, fmt.Errorf("read deploy file: %w", err)
+		}
+
+		fileProblems := validateFile(repoRoot, file, data, names)
+		for _, p := range fileProblems {
+			p.File = file
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	return problems, nil
+}
+
+func validateFile(repoRoot, file string, data []byte, names map[string]string) []*Problem {
+	var doc interface{}
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return []*Problem{{Message: err.Error()}}
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	problems := deploySchema.validate("", doc)
+	if len(problems) > 0 {
+		// The deployment cannot be decoded if it does not match the schema.
+		return problems
+	}
+
+	d, err := decode(bytes.NewReader(data), file, true)
+	if err != nil {
+		return []*Problem{{Message: err.Error()}}
+	}
+
+	if !imageName.MatchString(d.Name) {
+		problems = append(problems, &Problem{Field: "name", Message: fmt.Sprintf("%q is not a valid image name", d.Name)})
+	}
+	if other, ok := names[d.Name]; ok {
+		problems = append(problems, &Problem{Field: "name", Message: fmt.Sprintf("%q is also used by %s", d.Name, other)})
+	} else {
+		names[d.Name] = file
+	}
+
+	for i, b := range d.Binaries {
+		err = checkMain(repoRoot, b.Main)
+		if err != nil {
+			field := "main"
+			if len(d.Binaries) > 1 || b.Main != d.Main {
+				field = fmt.Sprintf("binaries[%d].main", i)
+			}
+			problems = append(problems, &Problem{Field: field, Message: err.Error()})
+		}
+	}
+
+	return problems
+}
+
+// checkMain checks that the directory, relative to the repository root,
+// holds a main package of the module at the repository root.
+func checkMain(repoRoot, main string) error {
+	main = filepath.Clean(filepath.FromSlash(main))
+	if filepath.IsAbs(main) || main == ".." || strings.HasPrefix(main, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not inside the repository", main)
+	}
+	if main == "vendor" || strings.HasPrefix(main, "vendor"+string(filepath.Separator)) {
+		return fmt.Errorf("%s is a vendored package", main)
+	}
+
+	// Directories with a go.mod file belong to another module.
+	for dir := main; dir != "."; dir = filepath.Dir(dir) {
+		_, err := os.Stat(filepath.Join(repoRoot, dir, "go.mod"))
+		if err == nil {
+			return fmt.Errorf("%s is in the module in %s, not the module of the repository", main, dir)
+		}
+	}
+
+	pkg, err := build.ImportDir(filepath.Join(repoRoot, main), 0)
+	var noGo *build.NoGoError
+	switch {
+	case errors.As(err, &noGo), os.IsNotExist(err):
+		return fmt.Errorf("%s is not a Go package", main)
+	case err != nil:
+		return fmt.Errorf("%s: %w", main, err)
+	case pkg.Name != "main":
+		return fmt.Errorf("%s is package %s, not package main", main, pkg.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package deploy_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":                          "module example.com/repo\n",
+		"cmd/api/main.go":                 "package main\n\nfunc main() {}\n",
+		"cmd/api/deploy.yml":              "name: api\n",
+		"cmd/migrate/main.go":             "package main\n\nfunc main() {}\n",
+		"cmd/migrate/deploy.yaml":         "name: migrate\n",
+		"cmd/worker/main.go":              "package main\n\nfunc main() {}\n",
+		"cmd/worker/deploy.yml":           "name: api\nbinaries:\n  - main: pkg/lib\n    entrypoint: true\n",
+		"cmd/typo/deploy.yml":             "name: Typo\nverify:\n  tset: true\n  smoke:\n    exitCode: one\n",
+		"cmd/other/go.mod":                "module example.com/other\n",
+		"cmd/other/main.go":               "package main\n\nfunc main() {}\n",
+		"cmd/other/deploy.yml":            "name: other\n",
+		"pkg/lib/lib.go":                  "package lib\n",
+		"vendor/example.com/x/deploy.yml": "name: vendored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	found, err := deploy.FindFiles(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantFiles := []string{"cmd/api/deploy.yml", "cmd/migrate/deploy.yaml", "cmd/other/deploy.yml", "cmd/typo/deploy.yml", "cmd/worker/deploy.yml"}
+	if diff := cmp.Diff(wantFiles, found, cmp.Transformer("ToSlash", filepath.ToSlash)); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+
+	problems, err := deploy.Validate(dir, wantFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make([]string, 0, len(problems))
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"cmd/other/deploy.yml: main: cmd/other is in the module in cmd/other, not the module of the repository",
+		`cmd/typo/deploy.yml: name: "Typo" does not match ^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`,
+		"cmd/typo/deploy.yml: verify.smoke.exitCode: must be of type integer",
+		"cmd/typo/deploy.yml: verify.tset: is not a known field",
+		`cmd/worker/deploy.yml: name: "api" is also used by cmd/api/deploy.yml`,
+		"cmd/worker/deploy.yml: binaries[0].main: pkg/lib is package lib, not package main",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected problems (-want +got):\n%s", diff)
+	}
+}
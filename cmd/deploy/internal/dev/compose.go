@@ -0,0 +1,30 @@
+package dev
+
+// compose is a docker-compose file. Version 2.4 is used since it is the
+// latest to support waiting for dependencies to be healthy.
+type compose struct {
+	Version  string                     `yaml:"version"`
+	Services map[string]*composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string                `yaml:"image"`
+	Entrypoint  []string              `yaml:"entrypoint,omitempty"`
+	Command     []string              `yaml:"command,omitempty"`
+	Environment map[string]string     `yaml:"environment,omitempty"`
+	Ports       []string              `yaml:"ports,omitempty"`
+	Volumes     []string              `yaml:"volumes,omitempty"`
+	DependsOn   map[string]*dependsOn `yaml:"depends_on,omitempty"`
+	Healthcheck *healthcheck          `yaml:"healthcheck,omitempty"`
+}
+
+type dependsOn struct {
+	Condition string `yaml:"condition"`
+}
+
+type healthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval"`
+	Timeout  string   `yaml:"timeout"`
+	Retries  int      `yaml:"retries"`
+}
@@ -0,0 +1,323 @@
+package dev
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+// BaseImage is the image the binaries of deployments are run in.
+const BaseImage = "alpine:3.12"
+
+// backingService describes how to run a type of dependency.
+type backingService struct {
+	Image       string
+	Env         map[string]string
+	Healthcheck []string
+	// URL is the format of the URL of the dependency, given its host.
+	URL string
+}
+
+var backingServices = map[string]*backingService{
+	deploy.DependencyPostgres: {
+		Image: "postgres:12-alpine",
+		// Local databases do not need passwords.
+		Env:         map[string]string{"POSTGRES_HOST_AUTH_METHOD": "trust"},
+		Healthcheck: []string{"CMD", "pg_isready", "-U", "postgres"},
+		URL:         "postgresql://postgres@%s:5432/postgres?sslmode=disable",
+	},
+	deploy.DependencyRedis: {
+		Image:       "redis:6-alpine",
+		Healthcheck: []string{"CMD", "redis-cli", "ping"},
+		URL:         "redis://%s:6379",
+	},
+}
+
+// Request is the input to Render.
+type Request struct {
+	// Deployments are the deployments of the repository.
+	Deployments []*deploy.Deployment
+	// Services are the names of the deployments to run, along with the
+	// deployments they depend on. Defaults to every deployment.
+	Services []string
+}
+
+// Environment is a rendered local development environment.
+type Environment struct {
+	// Deployments are the deployments run in the environment. Their
+	// binaries must be built to their BinaryPath before it is started.
+	Deployments []*deploy.Deployment
+	// Compose is the docker-compose file of the environment.
+	Compose []byte
+}
+
+// BinaryPath returns the path the binary of the deployment is mounted
+// from, relative to the directory of the docker-compose file.
+func BinaryPath(d *deploy.Deployment, b *deploy.Binary) string {
+	return path.Join(d.Name, b.Path)
+}
+
+// Render renders a docker-compose file running the binaries of the requested
+// deployments, with the flags and environment variables of their runtime, and
+// starting their dependencies. The addresses of the dependencies are passed
+// with the flags and environment variables declared by the dependencies, and
+// secrets are set to their local values.
+func Render(req *Request) (*Environment, error) {
+	deployments, err := resolve(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compose{
+		Version:  "2.4",
+		Services: map[string]*composeService{},
+	}
+	hostPorts := map[int]string{}
+	backing := map[string]*deploy.Dependency{}
+	for _, d := range deployments {
+		svc, err := newService(d, deployments)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		c.Services[d.Name] = svc
+
+		if d.Runtime == nil {
+			continue
+		}
+		for _, p := range d.Runtime.Ports {
+			if other, ok := hostPorts[p.Port]; ok {
+				return nil, fmt.Errorf("%s and %s both listen on port %d", other, d.Name, p.Port)
+			}
+			hostPorts[p.Port] = d.Name
+			svc.Ports = append(svc.Ports, fmt.Sprintf("%d:%d", p.Port, p.Port))
+		}
+		for _, dep := range d.Runtime.Dependencies {
+			if dep.Type == deploy.DependencyService {
+				continue
+			}
+			if other, ok := backing[dep.Name]; ok && (other.Type != dep.Type || other.Image != dep.Image) {
+				return nil, fmt.Errorf("dependency %s is declared with different types or images", dep.Name)
+			}
+			backing[dep.Name] = dep
+		}
+	}
+
+	for name, dep := range backing {
+		if _, ok := c.Services[name]; ok {
+			return nil, fmt.Errorf("dependency %s has the name of a deployment", name)
+		}
+		bs := backingServices[dep.Type]
+		image := dep.Image
+		if image == "" {
+			image = bs.Image
+		}
+		c.Services[name] = &composeService{
+			Image:       image,
+			Environment: bs.Env,
+			Healthcheck: &healthcheck{
+				Test:     bs.Healthcheck,
+				Interval: "2s",
+				Timeout:  "5s",
+				Retries:  30,
+			},
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by deploy dev. DO NOT EDIT.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(c)
+	if err != nil {
+		return nil, fmt.Errorf("render docker-compose file: %w", err)
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("render docker-compose file: %w", err)
+	}
+
+	return &Environment{
+		Deployments: deployments,
+		Compose:     buf.Bytes(),
+	}, nil
+}
+
+// resolve returns the requested deployments and the deployments they depend
+// on, in the order of the deployments of the request.
+func resolve(req *Request) ([]*deploy.Deployment, error) {
+	byName := map[string]*deploy.Deployment{}
+	for _, d := range req.Deployments {
+		byName[d.Name] = d
+	}
+
+	queue := req.Services
+	if len(queue) == 0 {
+		for _, d := range req.Deployments {
+			queue = append(queue, d.Name)
+		}
+	}
+
+	selected := map[string]bool{}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if selected[name] {
+			continue
+		}
+		d, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown deployment %s", name)
+		}
+		selected[name] = true
+
+		if d.Runtime == nil {
+			continue
+		}
+		for _, dep := range d.Runtime.Dependencies {
+			if dep.Type != deploy.DependencyService {
+				continue
+			}
+			target, ok := byName[dep.Service]
+			if !ok {
+				return nil, fmt.Errorf("%s depends on unknown deployment %s", name, dep.Service)
+			}
+			if findPort(target, dep.Port) == nil {
+				return nil, fmt.Errorf("%s depends on unknown port %s of %s", name, dep.Port, dep.Service)
+			}
+			queue = append(queue, dep.Service)
+		}
+	}
+
+	var deployments []*deploy.Deployment
+	for _, d := range req.Deployments {
+		if selected[d.Name] {
+			deployments = append(deployments, d)
+		}
+	}
+
+	return deployments, nil
+}
+
+// newService returns the service running the entrypoint of the deployment.
+func newService(d *deploy.Deployment, deployments []*deploy.Deployment) (*composeService, error) {
+	svc := &composeService{
+		Image: BaseImage,
+	}
+	for _, b := range d.Binaries {
+		svc.Volumes = append(svc.Volumes, "./"+BinaryPath(d, b)+":"+b.Path+":ro")
+		if b.Entrypoint {
+			svc.Entrypoint = []string{b.Path}
+		}
+	}
+
+	rt := d.Runtime
+	if rt == nil {
+		return svc, nil
+	}
+
+	flags := map[string]string{}
+	env := map[string]string{}
+	for _, p := range rt.Ports {
+		if p.Flag != "" {
+			flags[p.Flag] = strconv.Itoa(p.Port)
+		}
+	}
+	for name, value := range rt.Flags {
+		flags[name] = value
+	}
+	for name, value := range rt.Env {
+		env[name] = value
+	}
+
+	depFlags := map[string]string{}
+	depEnv := map[string]string{}
+	for _, dep := range rt.Dependencies {
+		var address string
+		if dep.Type == deploy.DependencyService {
+			address = fmt.Sprintf("%s:%d", dep.Service, findPort(findDeployment(deployments, dep.Service), dep.Port).Port)
+			svc.dependOn(dep.Service, "service_started")
+		} else {
+			address = fmt.Sprintf(backingServices[dep.Type].URL, dep.Name)
+			svc.dependOn(dep.Name, "service_healthy")
+		}
+		if dep.Flag != "" {
+			depFlags[dep.Flag] = address
+		}
+		if dep.Env != "" {
+			depEnv[dep.Env] = address
+		}
+	}
+
+	for _, s := range rt.Secrets {
+		if _, ok := depEnv[s.Env]; ok {
+			continue
+		}
+		if _, ok := depFlags[s.Flag]; ok && s.Flag != "" {
+			continue
+		}
+		if s.Local == "" {
+			return nil, fmt.Errorf("secret %s has no local value", s.Env)
+		}
+		env[s.Env] = s.Local
+		if s.Flag != "" {
+			flags[s.Flag] = s.Local
+		}
+	}
+
+	for name, value := range depFlags {
+		flags[name] = value
+	}
+	for name, value := range depEnv {
+		env[name] = value
+	}
+
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		svc.Command = append(svc.Command, "--"+name+"="+flags[name])
+	}
+	if len(env) > 0 {
+		svc.Environment = env
+	}
+
+	return svc, nil
+}
+
+func (s *composeService) dependOn(name, condition string) {
+	if s.DependsOn == nil {
+		s.DependsOn = map[string]*dependsOn{}
+	}
+	s.DependsOn[name] = &dependsOn{Condition: condition}
+}
+
+func findDeployment(deployments []*deploy.Deployment, name string) *deploy.Deployment {
+	for _, d := range deployments {
+		if d.Name == name {
+			return d
+		}
+	}
+
+	return nil
+}
+
+func findPort(d *deploy.Deployment, name string) *deploy.Port {
+	if d.Runtime == nil {
+		return nil
+	}
+	for _, p := range d.Runtime.Ports {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package dev_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/dev"
+)
+
+func newDeployment(name string, rt *deploy.Runtime) *deploy.Deployment {
+	return &deploy.Deployment{
+		Name: name,
+		Binaries: []*deploy.Binary{{
+			Main:       "cmd/" + name,
+			Path:       deploy.DefaultBinaryPath,
+			Entrypoint: true,
+		}},
+		Runtime: rt,
+	}
+}
+
+func TestRender(t *testing.T) {
+	api := newDeployment("api", &deploy.Runtime{
+		Ports: []*deploy.Port{{Name: "grpc", Port: 8080, Flag: "grpc-port"}},
+		Flags: map[string]string{"admin-user": "admin"},
+		Secrets: []*deploy.SecretRef{
+			{Env: "DB_URL", Secret: "api", Key: "db-url", Flag: "db-url"},
+			{Env: "ADMIN_PASSWORD", Secret: "api", Key: "admin-password", Local: "admin"},
+		},
+		Dependencies: []*deploy.Dependency{
+			{Name: "postgres", Type: deploy.DependencyPostgres, Flag: "db-url"},
+		},
+	})
+	worker := newDeployment("worker", &deploy.Runtime{
+		Dependencies: []*deploy.Dependency{
+			{Name: "backend", Type: deploy.DependencyService, Service: "api", Port: "grpc", Env: "API_ADDRESS"},
+			{Name: "cache", Type: deploy.DependencyRedis, Image: "redis:5-alpine", Flag: "redis-url"},
+		},
+	})
+	tool := newDeployment("tool", nil)
+
+	tests := []struct {
+		name         string
+		req          *dev.Request
+		wantServices []string
+		wantCompose  string
+		wantErr      string
+	}{
+		{
+			name: "dependencies",
+			req: &dev.Request{
+				Deployments: []*deploy.Deployment{api, worker, tool},
+				Services:    []string{"worker"},
+			},
+			wantServices: []string{"api", "worker"},
+			wantCompose: `# Generated by deploy dev. DO NOT EDIT.
+version: "2.4"
+services:
+  api:
+    image: alpine:3.12
+    entrypoint:
+      - /app
+    command:
+      - --admin-user=admin
+      - --db-url=postgresql://postgres@postgres:5432/postgres?sslmode=disable
+      - --grpc-port=8080
+    environment:
+      ADMIN_PASSWORD: admin
+    ports:
+      - 8080:8080
+    volumes:
+      - ./api/app:/app:ro
+    depends_on:
+      postgres:
+        condition: service_healthy
+  cache:
+    image: redis:5-alpine
+    healthcheck:
+      test:
+        - CMD
+        - redis-cli
+        - ping
+      interval: 2s
+      timeout: 5s
+      retries: 30
+  postgres:
+    image: postgres:12-alpine
+    environment:
+      POSTGRES_HOST_AUTH_METHOD: trust
+    healthcheck:
+      test:
+        - CMD
+        - pg_isready
+        - -U
+        - postgres
+      interval: 2s
+      timeout: 5s
+      retries: 30
+  worker:
+    image: alpine:3.12
+    entrypoint:
+      - /app
+    command:
+      - --redis-url=redis://cache:6379
+    environment:
+      API_ADDRESS: api:8080
+    volumes:
+      - ./worker/app:/app:ro
+    depends_on:
+      api:
+        condition: service_started
+      cache:
+        condition: service_healthy
+`,
+		},
+		{
+			name: "no runtime",
+			req: &dev.Request{
+				Deployments: []*deploy.Deployment{api, tool},
+				Services:    []string{"tool"},
+			},
+			wantServices: []string{"tool"},
+			wantCompose: `# Generated by deploy dev. DO NOT EDIT.
+version: "2.4"
+services:
+  tool:
+    image: alpine:3.12
+    entrypoint:
+      - /app
+    volumes:
+      - ./tool/app:/app:ro
+`,
+		},
+		{
+			name: "unknown deployment",
+			req: &dev.Request{
+				Deployments: []*deploy.Deployment{worker},
+			},
+			wantErr: "worker depends on unknown deployment api",
+		},
+		{
+			name: "secret without local value",
+			req: &dev.Request{
+				Deployments: []*deploy.Deployment{newDeployment("api", &deploy.Runtime{
+					Secrets: []*deploy.SecretRef{{Env: "DB_URL", Secret: "api", Key: "db-url"}},
+				})},
+			},
+			wantErr: "api: secret DB_URL has no local value",
+		},
+		{
+			name: "port conflict",
+			req: &dev.Request{
+				Deployments: []*deploy.Deployment{api, newDeployment("other", &deploy.Runtime{
+					Ports: []*deploy.Port{{Name: "http", Port: 8080}},
+				})},
+			},
+			wantErr: "api and other both listen on port 8080",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			env, err := dev.Render(test.req)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var services []string
+			for _, d := range env.Deployments {
+				services = append(services, d.Name)
+			}
+			if diff := cmp.Diff(test.wantServices, services); diff != "" {
+				t.Errorf("unexpected deployments (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantCompose, string(env.Compose)); diff != "" {
+				t.Errorf("unexpected docker-compose file (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
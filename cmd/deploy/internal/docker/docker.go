@@ -7,17 +7,24 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	docker "github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker/static"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
 )
 
 //go:generate go-bindata -pkg static -prefix static -nometadata -ignore bindata -o ./static/bindata.go ./static
@@ -44,95 +51,253 @@ type dockerResp struct {
 		Current float64
 		Total   float64
 	} `json:"progressDetail,omitempty"`
-	Progress string `json:"progress,omitempty"`
+	Progress    string `json:"progress,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ErrorDetail struct {
+		Message string `json:"message,omitempty"`
+	} `json:"errorDetail,omitempty"`
+}
+
+// err returns the error reported by the message, if any.
+func (r *dockerResp) err() error {
+	switch {
+	case r.ErrorDetail.Message != "":
+		return errors.New(r.ErrorDetail.Message)
+	case r.Error != "":
+		return errors.New(r.Error)
+	default:
+		return nil
+	}
 }
 
 // Request is the input to BuildAndPushImage
 type Request struct {
 	RepoRoot         string
-	BinaryPath       string
+	Binaries         []*Binary
 	Registry         string
 	RegistryUser     string
 	RegistryPassword string
 	Name             string
 	GitSHA           string
-	Tag              string
+	Tags             []string
+	// MaxSize is the size budget of the image in bytes, if set.
+	// Images exceeding it are not pushed.
+	MaxSize int64
+	// BuildTimeout limits pulling the base images and building the image, if set.
+	BuildTimeout time.Duration
+	// PushTimeout limits each attempt to push a tag, if set.
+	PushTimeout time.Duration
+	// Retry is the policy for retrying base image pulls and pushes.
+	Retry retry.Policy
+	// ProgressInterval is the interval between progress lines of pulls
+	// and pushes. Defaults to 10 seconds.
+	ProgressInterval time.Duration
 }
 
-// BuildAndPushImage builds a docker image using the default Dockerfile and pushes
-// it to the partner registry. It returns the registry SHA256 digest of the pushed image.
-func BuildAndPushImage(ctx context.Context, logger *logrus.Logger, req *Request) (digest string, err error) {
+// dockerfileTemplate is the template of the Dockerfile used to build images.
+var dockerfileTemplate = template.Must(template.New("Dockerfile").Parse(string(static.MustAsset("Dockerfile"))))
+
+// dockerfileData is the data used to render the Dockerfile.
+type dockerfileData struct {
+	Binaries   []*dockerfileBinary
+	Entrypoint string
+}
+
+type dockerfileBinary struct {
+	// Source is the name of the binary in the build context.
+	Source string
+	Path   string
+}
+
+// Binary is a binary to copy into the image.
+type Binary struct {
+	// LocalPath is the path of the built binary.
+	LocalPath string
+	// Path is the path of the binary inside the image.
+	Path string
+	// Entrypoint marks the binary run by the image.
+	Entrypoint bool
+}
+
+// Image describes an image that has been pushed to the registry.
+type Image struct {
+	// Repository is the registry path of the image, without a tag.
+	Repository string
+	// Tags are the tags pushed to the repository.
+	Tags []string
+	// Digest is the immutable registry digest of the image manifest.
+	Digest string
+	// Platforms lists the OS/architecture pairs the image was built for.
+	Platforms []string
+	// Size is the uncompressed size of the image in bytes.
+	Size int64
+}
+
+// Reference returns the immutable, digest-pinned reference of the image.
+func (i *Image) Reference() string {
+	return i.Repository + "@" + i.Digest
+}
+
+// Client builds and pushes Docker images. It is safe for concurrent use,
+// so a single client can be shared between several releases.
+type Client struct {
+	client *docker.Client
+}
+
+// NewClient connects to the Docker daemon configured in the environment.
+func NewClient() (*Client, error) {
 	client, err := docker.NewClientWithOpts(docker.FromEnv, docker.WithAPIVersionNegotiation())
 	if err != nil {
-		return "", fmt.Errorf("connect to docker: %w", err)
+		return nil, fmt.Errorf("connect to docker: %w", err)
 	}
 
+	return &Client{
+		client: client,
+	}, nil
+}
+
+// Close closes the connection to the Docker daemon.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// BuildAndPushImage builds a docker image using the default Dockerfile and pushes
+// it to the partner registry. It returns a description of the pushed image, including
+// its registry SHA256 digest.
+func (c *Client) BuildAndPushImage(ctx context.Context, logger logrus.FieldLogger, req *Request) (_ *Image, err error) {
 	var buf bytes.Buffer
 	gw := gzip.NewWriter(&buf)
 	w := tar.NewWriter(gw)
 
-	dockerfile := static.MustAsset("Dockerfile")
+	data := &dockerfileData{}
+	for i, b := range req.Binaries {
+		// The first binary keeps the name used by single binary images.
+		source := "app"
+		if i > 0 {
+			source = fmt.Sprintf("app-%d", i)
+		}
+		data.Binaries = append(data.Binaries, &dockerfileBinary{
+			Source: source,
+			Path:   b.Path,
+		})
+		if b.Entrypoint {
+			data.Entrypoint = b.Path
+		}
+
+		err = addFile(w, source, b.LocalPath, 0o500)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var dockerfile bytes.Buffer
+	err = dockerfileTemplate.Execute(&dockerfile, data)
+	if err != nil {
+		return nil, fmt.Errorf("render Dockerfile: %w", err)
+	}
+
 	err = w.WriteHeader(&tar.Header{
 		Name: "Dockerfile",
 		Mode: 0o400,
-		Size: int64(len(dockerfile)),
+		Size: int64(dockerfile.Len()),
 	})
 	if err != nil {
-		return "", fmt.Errorf("create tar header for Dockerfile: %w", err)
+		return nil, fmt.Errorf("create tar header for Dockerfile: %w", err)
 	}
 
-	_, err = w.Write(dockerfile)
+	_, err = w.Write(dockerfile.Bytes())
 	if err != nil {
-		return "", fmt.Errorf("write Dockerfile to tar: %w", err)
+		return nil, fmt.Errorf("write Dockerfile to tar: %w", err)
 	}
 
-	f, err := os.Open(req.BinaryPath)
+	err = w.Close()
 	if err != nil {
-		return "", fmt.Errorf("open binary for reading: %w", err)
+		return nil, fmt.Errorf("close tar writer: %w", err)
 	}
-	defer func() {
-		cErr := f.Close()
-		if err == nil {
-			err = cErr
-		}
-	}()
 
-	finfo, err := f.Stat()
+	err = gw.Close()
 	if err != nil {
-		return "", fmt.Errorf("stat binary: %w", err)
+		return nil, fmt.Errorf("close gzip writer: %w", err)
 	}
 
-	err = w.WriteHeader(&tar.Header{
-		Name: "app",
-		Mode: 0o500,
-		Size: finfo.Size(),
-	})
+	img := &Image{
+		Repository: req.Registry + "/" + req.Name,
+		Tags:       req.Tags,
+	}
+	tags := make([]string, 0, len(img.Tags))
+	for _, tag := range img.Tags {
+		tags = append(tags, img.Repository+":"+tag)
+	}
 
-	_, err = io.Copy(w, f)
+	err = c.build(ctx, logger, req, dockerfile.Bytes(), &buf, tags)
 	if err != nil {
-		return "", fmt.Errorf("copy binary: %w", err)
+		return nil, err
 	}
 
-	err = w.Close()
+	inspect, _, err := c.client.ImageInspectWithRaw(ctx, tags[0])
 	if err != nil {
-		return "", fmt.Errorf("close tar writer: %w", err)
+		return nil, fmt.Errorf("inspect docker image: %w", err)
 	}
+	img.Size = inspect.Size
+	img.Platforms = []string{inspect.Os + "/" + inspect.Architecture}
 
-	err = gw.Close()
+	if req.MaxSize > 0 && img.Size > req.MaxSize {
+		return nil, fmt.Errorf("image size of %s exceeds the budget of %s",
+			formatBytes(img.Size), formatBytes(req.MaxSize))
+	}
+
+	auth := types.AuthConfig{
+		Username: req.RegistryUser,
+		Password: req.RegistryPassword,
+	}
+	encodedJSON, err := json.Marshal(auth)
 	if err != nil {
-		return "", fmt.Errorf("close gzip writer: %w", err)
+		return nil, fmt.Errorf("marshal docker auth: %w", err)
 	}
+	authStr := base64.URLEncoding.EncodeToString(encodedJSON)
 
-	pr, pw := io.Pipe()
-	tags := []string{
-		req.Registry + "/" + req.Name + ":" + req.GitSHA,
-		req.Registry + "/" + req.Name + ":" + req.Tag,
+	for _, image := range tags {
+		image := image
+		err = req.Retry.Do(ctx, logger, "Push "+image, func(ctx context.Context) error {
+			digest, err := c.push(ctx, logger, req, image, authStr)
+			if err != nil {
+				return err
+			}
+			img.Digest = digest
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return img, nil
+}
+
+// build pulls the base images of the Dockerfile, retrying failed pulls,
+// and builds the image from the context, tagging it with the tags.
+func (c *Client) build(ctx context.Context, logger logrus.FieldLogger, req *Request, dockerfile []byte, buildCtx io.Reader, tags []string) error {
+	if req.BuildTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.BuildTimeout)
+		defer cancel()
+	}
+
+	for _, base := range baseImages(dockerfile) {
+		base := base
+		err := req.Retry.Do(ctx, logger, "Pull "+base, func(ctx context.Context) error {
+			return c.pull(ctx, logger, req, base)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
+	pr, pw := io.Pipe()
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
 		defer pw.Close()
-		resp, err := client.ImageBuild(egCtx, &buf, types.ImageBuildOptions{
+		resp, err := c.client.ImageBuild(egCtx, buildCtx, types.ImageBuildOptions{
 			Labels: map[string]string{
 				"revision": req.GitSHA,
 			},
@@ -154,7 +319,7 @@ func BuildAndPushImage(ctx context.Context, logger *logrus.Logger, req *Request)
 		dec := json.NewDecoder(pr)
 		for dec.More() {
 			var msg dockerResp
-			err = dec.Decode(&msg)
+			err := dec.Decode(&msg)
 			if err != nil {
 				return fmt.Errorf("parse piped docker build response: %w", err)
 			}
@@ -162,74 +327,213 @@ func BuildAndPushImage(ctx context.Context, logger *logrus.Logger, req *Request)
 			if msg.Stream != "" {
 				logger.Println(strings.TrimSpace(msg.Stream))
 			}
+			if err = msg.err(); err != nil {
+				return fmt.Errorf("build docker image: %w", err)
+			}
 		}
 
 		return nil
 	})
 
-	err = eg.Wait()
+	return eg.Wait()
+}
+
+// pull pulls the image, logging its progress periodically.
+func (c *Client) pull(ctx context.Context, logger logrus.FieldLogger, req *Request, image string) error {
+	resp, err := c.client.ImagePull(ctx, image, types.ImagePullOptions{})
 	if err != nil {
-		return "", err
+		return classify(fmt.Errorf("pull docker image (%s): %w", image, err))
 	}
+	defer resp.Close()
 
-	auth := types.AuthConfig{
-		Username: req.RegistryUser,
-		Password: req.RegistryPassword,
+	p := newProgress(logger, "Pulling "+image, req.ProgressInterval)
+	err = readResponse(resp, p.update)
+	p.log()
+	if err != nil {
+		return classify(fmt.Errorf("pull docker image (%s): %w", image, err))
 	}
-	encodedJSON, err := json.Marshal(auth)
+
+	return nil
+}
+
+// push pushes the image, logging its progress periodically.
+// It returns the digest reported by the registry.
+func (c *Client) push(ctx context.Context, logger logrus.FieldLogger, req *Request, image, auth string) (digest string, err error) {
+	if req.PushTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.PushTimeout)
+		defer cancel()
+	}
+
+	resp, err := c.client.ImagePush(ctx, image, types.ImagePushOptions{
+		RegistryAuth: auth,
+	})
 	if err != nil {
-		return "", fmt.Errorf("marshal docker auth: %w", err)
+		return "", classify(fmt.Errorf("failed to push docker image (%s): %w", image, err))
 	}
-	authStr := base64.URLEncoding.EncodeToString(encodedJSON)
+	defer resp.Close()
 
-	for _, image := range tags {
-		eg, egCtx = errgroup.WithContext(ctx)
+	p := newProgress(logger, "Pushing "+image, req.ProgressInterval)
+	err = readResponse(resp, func(msg *dockerResp) {
+		p.update(msg)
+		if msg.Aux.Digest != "" {
+			digest = msg.Aux.Digest
+		}
+	})
+	p.log()
+	if err != nil {
+		return "", classify(fmt.Errorf("failed to push docker image (%s): %w", image, err))
+	}
 
-		pr, pw = io.Pipe()
+	return digest, nil
+}
 
-		eg.Go(func() error {
-			defer pw.Close()
-			resp, err := client.ImagePush(egCtx, image, types.ImagePushOptions{
-				RegistryAuth: authStr,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to push docker image (%s): %w", image, err)
-			}
-			defer resp.Close()
-			// Stream response
-			_, err = io.Copy(pw, resp)
-			if err != nil {
-				return fmt.Errorf("read docker push response: %w", err)
-			}
-			return nil
-		})
-		eg.Go(func() error {
-			defer pr.Close()
-			dec := json.NewDecoder(pr)
-			for dec.More() {
-				var msg dockerResp
-				err = dec.Decode(&msg)
-				if err != nil {
-					return fmt.Errorf("parse piped docker push response: %w", err)
-				}
-
-				if msg.Status != "" {
-					logger.Println(strings.TrimSpace(msg.Status))
-				}
-
-				if msg.Aux.Digest != "" {
-					digest = fmt.Sprintf("%s/%s@%s", req.Registry, req.Name, msg.Aux.Digest)
-				}
-			}
+// readResponse decodes the streamed response, calling fn for every message.
+// It returns the first error reported in the stream.
+func readResponse(r io.Reader, fn func(msg *dockerResp)) error {
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		var msg dockerResp
+		err := dec.Decode(&msg)
+		if err != nil {
+			return fmt.Errorf("parse docker response: %w", err)
+		}
 
+		if err = msg.err(); err != nil {
 			return err
-		})
+		}
+		fn(&msg)
+	}
 
-		err = eg.Wait()
-		if err != nil {
-			return "", err
+	return nil
+}
+
+// classify marks errors that are not worth retrying as permanent,
+// such as rejected credentials or missing images.
+func classify(err error) error {
+	if errdefs.IsUnauthorized(err) || errdefs.IsForbidden(err) ||
+		errdefs.IsNotFound(err) || errdefs.IsInvalidParameter(err) {
+		return retry.Permanent(err)
+	}
+
+	// Errors reported in the stream are only available as messages.
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"unauthorized", "denied", "authentication required", "manifest unknown"} {
+		if strings.Contains(msg, s) {
+			return retry.Permanent(err)
 		}
 	}
 
-	return digest, nil
+	return err
+}
+
+// baseImages returns the images the Dockerfile builds from,
+// skipping scratch and earlier stages of multi-stage builds.
+func baseImages(dockerfile []byte) []string {
+	stages := map[string]bool{"scratch": true}
+	var images []string
+	for _, line := range strings.Split(string(dockerfile), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		// Skip flags such as --platform.
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		if !stages[strings.ToLower(args[0])] {
+			images = append(images, args[0])
+		}
+		if len(args) == 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+	}
+
+	return images
+}
+
+// addFile adds the file at the path to the tar archive under the name.
+func addFile(w *tar.Writer, name, path string, mode int64) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s for reading: %w", name, err)
+	}
+	defer func() {
+		cErr := f.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	finfo, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", name, err)
+	}
+
+	err = w.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: mode,
+		Size: finfo.Size(),
+	})
+	if err != nil {
+		return fmt.Errorf("create tar header for %s: %w", name, err)
+	}
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ReadFile reads the file at the path from the local image with the reference,
+// by copying it out of a container created, but not started, from the image.
+// It returns an error wrapping os.ErrNotExist if the file does not exist.
+func (c *Client) ReadFile(ctx context.Context, ref, path string) (_ []byte, err error) {
+	created, err := c.client.ContainerCreate(ctx, &container.Config{
+		Image: ref,
+		// The container is never started, but a command
+		// is required for images that do not define one.
+		Entrypoint: []string{"/nonexistent"},
+	}, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("create container: %w", err)
+	}
+	defer func() {
+		rErr := c.client.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{
+			Force: true,
+		})
+		if err == nil && rErr != nil {
+			err = fmt.Errorf("remove container: %w", rErr)
+		}
+	}()
+
+	rc, _, err := c.client.CopyFromContainer(ctx, created.ID, path)
+	if docker.IsErrNotFound(err) {
+		return nil, fmt.Errorf("copy %s from container: %w", path, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy %s from container: %w", path, err)
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	_, err = tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read %s from container: %w", path, err)
+	}
+
+	data, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("read %s from container: %w", path, err)
+	}
+
+	return data, nil
 }
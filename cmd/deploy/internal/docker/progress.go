@@ -0,0 +1,147 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultProgressInterval is the interval between progress lines, if not configured.
+const defaultProgressInterval = 10 * time.Second
+
+// layerProgress is the progress of a single layer.
+type layerProgress struct {
+	Current int64
+	Total   int64
+	Done    bool
+}
+
+// progress aggregates the per-layer progress reported by pulls and pushes,
+// logging a single summary line per interval instead of every status.
+type progress struct {
+	logger   logrus.FieldLogger
+	action   string
+	interval time.Duration
+	last     time.Time
+	layers   map[string]*layerProgress
+}
+
+func newProgress(logger logrus.FieldLogger, action string, interval time.Duration) *progress {
+	if interval == 0 {
+		interval = defaultProgressInterval
+	}
+
+	return &progress{
+		logger:   logger,
+		action:   action,
+		interval: interval,
+		last:     time.Now(),
+		layers:   map[string]*layerProgress{},
+	}
+}
+
+// layerStatuses are the statuses reported for individual layers.
+var layerStatuses = map[string]bool{
+	"Preparing":            true,
+	"Waiting":              true,
+	"Pushing":              true,
+	"Pushed":               true,
+	"Layer already exists": true,
+	"Pulling fs layer":     true,
+	"Downloading":          true,
+	"Verifying Checksum":   true,
+	"Download complete":    true,
+	"Extracting":           true,
+	"Pull complete":        true,
+	"Already exists":       true,
+}
+
+// update records the message, logging the progress if the interval
+// has passed. Messages other than layer updates are logged as is.
+func (p *progress) update(msg *dockerResp) {
+	mounted := strings.HasPrefix(msg.Status, "Mounted from")
+	if msg.ID == "" || !layerStatuses[msg.Status] && !mounted {
+		if msg.Status != "" {
+			p.logger.Println(strings.TrimSpace(strings.TrimPrefix(msg.ID+": "+msg.Status, ": ")))
+		}
+		return
+	}
+
+	layer, ok := p.layers[msg.ID]
+	if !ok {
+		layer = &layerProgress{}
+		p.layers[msg.ID] = layer
+	}
+
+	switch msg.Status {
+	case "Pushing", "Downloading":
+		layer.Current = int64(msg.ProgressDetail.Current)
+		layer.Total = int64(msg.ProgressDetail.Total)
+	case "Pushed", "Layer already exists", "Pull complete", "Already exists":
+		layer.Done = true
+		layer.Current = layer.Total
+	default:
+		if mounted {
+			layer.Done = true
+			layer.Current = layer.Total
+		}
+	}
+
+	if time.Since(p.last) >= p.interval {
+		p.log()
+	}
+}
+
+// log logs the progress of every layer seen so far.
+func (p *progress) log() {
+	p.last = time.Now()
+	if len(p.layers) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(p.layers))
+	var done int
+	var current, total int64
+	for id, layer := range p.layers {
+		ids = append(ids, id)
+		if layer.Done {
+			done++
+		}
+		current += layer.Current
+		total += layer.Total
+	}
+	sort.Strings(ids)
+
+	details := make([]string, 0, len(ids))
+	for _, id := range ids {
+		layer := p.layers[id]
+		switch {
+		case layer.Done:
+			details = append(details, id+" done")
+		case layer.Total > 0:
+			details = append(details, fmt.Sprintf("%s %s/%s", id, formatBytes(layer.Current), formatBytes(layer.Total)))
+		default:
+			details = append(details, id+" waiting")
+		}
+	}
+
+	p.logger.Infof("%s: %d/%d layers done, %s/%s (%s)", p.action, done, len(ids),
+		formatBytes(current), formatBytes(total), strings.Join(details, ", "))
+}
+
+// formatBytes formats the size with a decimal unit.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fGB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fMB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fkB", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
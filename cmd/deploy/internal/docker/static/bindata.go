@@ -61,12 +61,13 @@ var _bindata = map[string]*asset{
 	"Dockerfile": &asset{
 		name: "Dockerfile",
 		data: "" +
-			"\x14\xca\xb1\x0a\xc2\x30\x10\x06\xe0\xfd\x9e\xe2\xa7\x7b\xe8\xee\x2a\x0a\x0e\x26\x25\xd4\xa1\x88" +
-			"\xc3\xcf\xe5\xc4\x60\xa9\x87\xde\xe4\xd3\x8b\xfb\x77\xac\xe5\x0c\xae\xde\x37\xdb\xad\x0c\xfb\x84" +
-			"\x48\xbd\x64\xd0\x9f\x60\x6b\x48\x69\x7b\x25\xa5\x3e\x0c\xca\xa4\xf6\x8e\x7e\xef\xfa\x87\x88\x6f" +
-			"\x63\x50\x64\x5f\xa6\x05\x74\xc7\x28\x72\xc8\x73\x5d\xa6\x72\xca\x33\xae\xc3\x48\xf7\xe1\x26\xbf" +
-			"\x00\x00\x00\xff\xff",
-		size: 99,
+			"\x14\xca\xb1\x6e\x83\x40\x0c\x00\xd0\xdd\x5f\x61\xb1\x1f\x1f\xd0\xb1\x15\x95\x3a\x14\x10\x21\x03" +
+			"\x8a\x32\x58\x77\x4e\x38\x05\x19\x74\x38\x43\x62\xf9\xdf\x23\xb6\x37\xbc\xdf\xa1\xfb\x47\x5a\xb6" +
+			"\x2c\xfc\xb5\x90\xf2\xae\x00\xc3\xb9\x45\xda\x1e\x48\x29\x61\x08\xb2\x86\x48\x71\x66\x8c\x14\x22" +
+			"\x17\xcd\xb7\x1c\x8f\x88\xfa\x4e\xa4\x04\x66\x85\xe4\xce\x58\x7f\x67\xa1\x92\x79\x77\x87\x9f\xae" +
+			"\x9f\xd0\xac\x3e\xad\xcf\x12\xd9\xfd\x70\x4f\x3a\xbb\x9b\xb1\x24\x77\x80\xa6\x1d\x87\xa9\xef\xfe" +
+			"\xda\x11\x2f\x95\x59\xdd\x88\x96\xd7\xb6\x66\x51\xf7\xea\x0a\x9f\x01\x00",
+		size: 152,
 	},
 }
 
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"strings"
+)
+
+// CI describes the build being run by a CI system.
+type CI struct {
+	// Provider is the name of the CI system.
+	Provider string
+	// Branch and Tag are the branch or tag being built, if known.
+	Branch string
+	Tag    string
+	// PullRequest is the number of the pull or merge request being built, if any.
+	PullRequest string
+	BuildNumber string
+	BuildURL    string
+}
+
+// DetectCI returns the build described by the environment variables of
+// CircleCI, GitHub Actions, GitLab CI or Buildkite, read with getenv.
+// It returns nil when not running in a supported CI system.
+func DetectCI(getenv func(string) string) *CI {
+	switch {
+	case getenv("CIRCLECI") == "true":
+		ci := &CI{
+			Provider:    "circleci",
+			Branch:      getenv("CIRCLE_BRANCH"),
+			Tag:         getenv("CIRCLE_TAG"),
+			PullRequest: getenv("CIRCLE_PR_NUMBER"),
+			BuildNumber: getenv("CIRCLE_BUILD_NUM"),
+			BuildURL:    getenv("CIRCLE_BUILD_URL"),
+		}
+		// CIRCLE_PR_NUMBER is only set for pull requests from forks.
+		if pr := getenv("CIRCLE_PULL_REQUEST"); ci.PullRequest == "" && pr != "" {
+			ci.PullRequest = pr[strings.LastIndex(pr, "/")+1:]
+		}
+		return ci
+	case getenv("GITHUB_ACTIONS") == "true":
+		ci := &CI{
+			Provider:    "github",
+			BuildNumber: getenv("GITHUB_RUN_NUMBER"),
+			BuildURL: getenv("GITHUB_SERVER_URL") + "/" + getenv("GITHUB_REPOSITORY") +
+				"/actions/runs/" + getenv("GITHUB_RUN_ID"),
+		}
+		ref := getenv("GITHUB_REF")
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			ci.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		case strings.HasPrefix(ref, "refs/tags/"):
+			ci.Tag = strings.TrimPrefix(ref, "refs/tags/")
+		case strings.HasPrefix(ref, "refs/pull/"):
+			// refs/pull/<number>/merge
+			ci.PullRequest = strings.Split(strings.TrimPrefix(ref, "refs/pull/"), "/")[0]
+			ci.Branch = getenv("GITHUB_HEAD_REF")
+		}
+		return ci
+	case getenv("GITLAB_CI") == "true":
+		ci := &CI{
+			Provider:    "gitlab",
+			Branch:      getenv("CI_COMMIT_BRANCH"),
+			Tag:         getenv("CI_COMMIT_TAG"),
+			PullRequest: getenv("CI_MERGE_REQUEST_IID"),
+			BuildNumber: getenv("CI_PIPELINE_IID"),
+			BuildURL:    getenv("CI_PIPELINE_URL"),
+		}
+		if ci.Branch == "" {
+			ci.Branch = getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")
+		}
+		return ci
+	case getenv("BUILDKITE") == "true":
+		ci := &CI{
+			Provider:    "buildkite",
+			Branch:      getenv("BUILDKITE_BRANCH"),
+			Tag:         getenv("BUILDKITE_TAG"),
+			BuildNumber: getenv("BUILDKITE_BUILD_NUMBER"),
+			BuildURL:    getenv("BUILDKITE_BUILD_URL"),
+		}
+		if pr := getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+			ci.PullRequest = pr
+		}
+		// Buildkite sets the branch to the tag name for tag builds.
+		if ci.Tag != "" && ci.Branch == ci.Tag {
+			ci.Branch = ""
+		}
+		return ci
+	default:
+		return nil
+	}
+}
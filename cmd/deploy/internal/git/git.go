@@ -1,22 +1,44 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // Metadata contains git metadata
 type Metadata struct {
-	GitSHA    string
+	GitSHA string
+	// GitBranch is the branch being built. It is empty if
+	// it cannot be determined for a detached HEAD.
 	GitBranch string
-	BuildTime time.Time
+	// GitTags are the names of the tags pointing at the commit.
+	GitTags []string
+	// Author is the author of the commit, as "Name <email>",
+	// and CommitMessage its full message.
+	Author        string
+	CommitMessage string
+	// RemoteURL is the URL of the origin remote, if configured.
+	RemoteURL string
+	// Dirty is true if tracked files have uncommitted changes.
+	Dirty bool
+	// PullRequest, BuildNumber and BuildURL are read from the CI system, if any.
+	PullRequest string
+	BuildNumber string
+	BuildURL    string
+	BuildTime   time.Time
 }
 
-// GetMetadata reads the git metadata from the repo root.
-func GetMetadata(repoRoot string) (*Metadata, error) {
+// GetMetadata reads the git metadata from the repo root. The branch and tag
+// being built are taken from the CI system if set, as CI systems usually
+// check out a detached HEAD. Otherwise the branch of a detached HEAD is
+// found by looking for local and remote branches containing the commit.
+func GetMetadata(repoRoot string, ci *CI) (*Metadata, error) {
 	repo, err := git.PlainOpen(repoRoot)
 	if err != nil {
 		return nil, fmt.Errorf("open local repository: %w", err)
@@ -32,17 +54,180 @@ func GetMetadata(repoRoot string) (*Metadata, error) {
 		return nil, fmt.Errorf("get base commit: %w", err)
 	}
 
-	branch := headRef.Name().Short()
-	// Remove commas
-	branch = strings.ReplaceAll(branch, ":", "-")
-	// Remove slashes
-	branch = strings.ReplaceAll(branch, "/", "-")
-
 	md := &Metadata{
-		GitSHA:    headCommit.Hash.String(),
-		GitBranch: branch,
-		BuildTime: time.Now(),
+		GitSHA:        headCommit.Hash.String(),
+		Author:        headCommit.Author.String(),
+		CommitMessage: strings.TrimSpace(headCommit.Message),
+		BuildTime:     time.Now(),
+	}
+
+	switch {
+	case ci != nil && ci.Branch != "":
+		md.GitBranch = ci.Branch
+	case headRef.Name().IsBranch():
+		md.GitBranch = headRef.Name().Short()
+	default:
+		md.GitBranch, err = findBranch(repo, headCommit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	md.GitTags, err = getTags(repo, headCommit.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if ci != nil {
+		if ci.Tag != "" && !contains(md.GitTags, ci.Tag) {
+			md.GitTags = append(md.GitTags, ci.Tag)
+		}
+		md.PullRequest = ci.PullRequest
+		md.BuildNumber = ci.BuildNumber
+		md.BuildURL = ci.BuildURL
+	}
+
+	md.Dirty, err = isDirty(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	switch {
+	case errors.Is(err, git.ErrRemoteNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("get origin remote: %w", err)
+	case len(remote.Config().URLs) > 0:
+		md.RemoteURL = remote.Config().URLs[0]
 	}
 
 	return md, nil
 }
+
+// findBranch returns the name of the branch containing the commit, preferring
+// branches pointing at the commit, and local branches over remote branches.
+// It returns an empty string if no branch contains the commit.
+func findBranch(repo *git.Repository, commit *object.Commit) (string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return "", fmt.Errorf("list references: %w", err)
+	}
+
+	var local, remote []*plumbing.Reference
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		switch {
+		case ref.Type() != plumbing.HashReference:
+		case ref.Name().IsBranch():
+			local = append(local, ref)
+		case ref.Name().IsRemote():
+			remote = append(remote, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("list references: %w", err)
+	}
+
+	branches := append(local, remote...)
+	for _, ref := range branches {
+		if ref.Hash() == commit.Hash {
+			return branchName(ref.Name()), nil
+		}
+	}
+
+	for _, ref := range branches {
+		tip, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return "", fmt.Errorf("get commit of %s: %w", ref.Name().Short(), err)
+		}
+		ok, err := commit.IsAncestor(tip)
+		if err != nil {
+			return "", fmt.Errorf("check whether %s contains the commit: %w", ref.Name().Short(), err)
+		}
+		if ok {
+			return branchName(ref.Name()), nil
+		}
+	}
+
+	return "", nil
+}
+
+// branchName returns the name of a local or remote branch,
+// without the remote name.
+func branchName(name plumbing.ReferenceName) string {
+	if name.IsRemote() {
+		short := name.Short()
+		return short[strings.Index(short, "/")+1:]
+	}
+
+	return name.Short()
+}
+
+// isDirty returns true if tracked files in the worktree have
+// uncommitted changes. Untracked files are ignored, as CI steps
+// often write files into the checkout.
+func isDirty(repo *git.Repository) (bool, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("get worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("get worktree status: %w", err)
+	}
+
+	for _, s := range status {
+		if s.Worktree == git.Untracked {
+			continue
+		}
+		if s.Worktree != git.Unmodified || s.Staging != git.Unmodified {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTags returns the names of the lightweight and
+// annotated tags pointing at the commit.
+func getTags(repo *git.Repository, commit plumbing.Hash) ([]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	var tags []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		tag, err := repo.TagObject(hash)
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// Lightweight tag
+		case err != nil:
+			return fmt.Errorf("get tag %s: %w", ref.Name().Short(), err)
+		default:
+			hash = tag.Target
+		}
+
+		if hash == commit {
+			tags = append(tags, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	return tags, nil
+}
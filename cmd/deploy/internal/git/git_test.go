@@ -0,0 +1,193 @@
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/git"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		Name string
+		Env  map[string]string
+		Want *git.CI
+	}{
+		{
+			Name: "It returns nil outside CI",
+			Env:  map[string]string{},
+		},
+		{
+			Name: "It reads CircleCI pull requests",
+			Env: map[string]string{
+				"CIRCLECI":            "true",
+				"CIRCLE_BRANCH":       "feature",
+				"CIRCLE_PULL_REQUEST": "https://github.com/uw-labs/go-mono/pull/12",
+				"CIRCLE_BUILD_NUM":    "34",
+				"CIRCLE_BUILD_URL":    "https://circleci.com/gh/uw-labs/go-mono/34",
+			},
+			Want: &git.CI{
+				Provider:    "circleci",
+				Branch:      "feature",
+				PullRequest: "12",
+				BuildNumber: "34",
+				BuildURL:    "https://circleci.com/gh/uw-labs/go-mono/34",
+			},
+		},
+		{
+			Name: "It reads GitHub Actions pull requests",
+			Env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REF":        "refs/pull/12/merge",
+				"GITHUB_HEAD_REF":   "feature",
+				"GITHUB_RUN_NUMBER": "34",
+				"GITHUB_RUN_ID":     "5678",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "uw-labs/go-mono",
+			},
+			Want: &git.CI{
+				Provider:    "github",
+				Branch:      "feature",
+				PullRequest: "12",
+				BuildNumber: "34",
+				BuildURL:    "https://github.com/uw-labs/go-mono/actions/runs/5678",
+			},
+		},
+		{
+			Name: "It reads GitLab tags",
+			Env: map[string]string{
+				"GITLAB_CI":       "true",
+				"CI_COMMIT_TAG":   "user-api/v1.0.0",
+				"CI_PIPELINE_IID": "34",
+			},
+			Want: &git.CI{
+				Provider:    "gitlab",
+				Tag:         "user-api/v1.0.0",
+				BuildNumber: "34",
+			},
+		},
+		{
+			Name: "It reads Buildkite tags",
+			Env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "user-api/v1.0.0",
+				"BUILDKITE_TAG":          "user-api/v1.0.0",
+				"BUILDKITE_PULL_REQUEST": "false",
+			},
+			Want: &git.CI{
+				Provider: "buildkite",
+				Tag:      "user-api/v1.0.0",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			got := git.DetectCI(func(key string) string {
+				return test.Env[key]
+			})
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Errorf("unexpected CI (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var commits []plumbing.Hash
+	for _, content := range []string{"first", "second"} {
+		err = ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = wt.Add("file.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		hash, err := wt.Commit(content, &gogit.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@localhost", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		commits = append(commits, hash)
+	}
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/feature", commits[1]))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = repo.Storer.RemoveReference(plumbing.Master)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Detach HEAD at the first commit, as CI systems do.
+	err = wt.Checkout(&gogit.CheckoutOptions{Hash: commits[0]})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Untracked files do not make the tree dirty.
+	err = ioutil.WriteFile(filepath.Join(dir, "builds.txt"), nil, 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	md, err := git.GetMetadata(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if md.GitBranch != "feature" {
+		t.Errorf("expected the branch containing the commit, got %q", md.GitBranch)
+	}
+	if md.Dirty {
+		t.Error("expected a clean working tree")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("changed"), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	md, err = git.GetMetadata(dir, &git.CI{Branch: "master", Tag: "v1.0.0", BuildNumber: "34"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &git.Metadata{
+		GitSHA:        commits[0].String(),
+		GitBranch:     "master",
+		GitTags:       []string{"v1.0.0"},
+		Author:        "test <test@localhost>",
+		CommitMessage: "first",
+		Dirty:         true,
+		BuildNumber:   "34",
+		BuildTime:     md.BuildTime,
+	}
+	if diff := cmp.Diff(want, md); diff != "" {
+		t.Errorf("unexpected metadata (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,87 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// CommitRequest is the input to Commit.
+type CommitRequest struct {
+	// Dir is the directory the files are relative to.
+	// It must be inside a git repository.
+	Dir         string
+	Files       []string
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+	// Push enables pushing the commit to the "origin" remote.
+	Push         bool
+	PushUser     string
+	PushPassword string
+}
+
+// Commit commits the files to the repository containing the directory,
+// optionally pushing the commit. It returns the hash of the new commit.
+func Commit(ctx context.Context, req *CommitRequest) (string, error) {
+	repo, err := git.PlainOpenWithOptions(req.Dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("open manifests repository: %w", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("get manifests worktree: %w", err)
+	}
+
+	dir, err := filepath.Abs(req.Dir)
+	if err != nil {
+		return "", fmt.Errorf("get absolute manifests path: %w", err)
+	}
+
+	for _, file := range req.Files {
+		path, err := filepath.Rel(wt.Filesystem.Root(), filepath.Join(dir, file))
+		if err != nil {
+			return "", fmt.Errorf("get path relative to worktree: %w", err)
+		}
+		_, err = wt.Add(filepath.ToSlash(path))
+		if err != nil {
+			return "", fmt.Errorf("add %s: %w", path, err)
+		}
+	}
+
+	hash, err := wt.Commit(req.Message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  req.AuthorName,
+			Email: req.AuthorEmail,
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit manifests: %w", err)
+	}
+
+	if req.Push {
+		opts := &git.PushOptions{}
+		if req.PushUser != "" || req.PushPassword != "" {
+			opts.Auth = &http.BasicAuth{
+				Username: req.PushUser,
+				Password: req.PushPassword,
+			}
+		}
+		err = repo.PushContext(ctx, opts)
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return "", fmt.Errorf("push manifests: %w", err)
+		}
+	}
+
+	return hash.String(), nil
+}
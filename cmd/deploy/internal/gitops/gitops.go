@@ -0,0 +1,362 @@
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Image is a published image that references in manifests should be updated to.
+type Image struct {
+	// Repository is the registry path of the image, without a tag.
+	Repository string
+	// Tag is a tag of the image, used where a manifest requires one.
+	Tag string
+	// Digest is the immutable registry digest of the image.
+	Digest string
+}
+
+// UpdateDir updates image references in every YAML file in the directory
+// tree rooted at dir to pin the images to their digests. Files that cannot
+// be parsed as YAML, such as Helm templates, are skipped.
+// It returns the paths of the changed files, relative to dir.
+func UpdateDir(logger logrus.FieldLogger, dir string, images []*Image) ([]string, error) {
+	var changed []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read manifest: %w", err)
+		}
+
+		out, err := Update(src, images)
+		if err != nil {
+			logger.WithError(err).Warnf("Skipping %s", path)
+			return nil
+		}
+
+		if bytes.Equal(src, out) {
+			return nil
+		}
+
+		err = ioutil.WriteFile(path, out, info.Mode())
+		if err != nil {
+			return fmt.Errorf("write manifest: %w", err)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("get relative manifest path: %w", err)
+		}
+		changed = append(changed, rel)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk manifests: %w", err)
+	}
+
+	return changed, nil
+}
+
+// Update updates the image references in the YAML documents in src.
+// The following forms are recognised:
+//
+//	# Plain Kubernetes manifests
+//	image: registry/name:tag
+//
+//	# Kustomize images transformers
+//	images:
+//	  - name: registry/name
+//	    digest: sha256:...
+//
+//	# Helm values files
+//	image:
+//	  repository: registry/name
+//	  tag: tag
+//
+// Helm values without a digest key get the digest appended to their tag.
+// The source is edited in place rather than re-encoded, so that formatting
+// and comments are preserved.
+func Update(src []byte, images []*Image) ([]byte, error) {
+	u := &updater{
+		images: images,
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse YAML: %w", err)
+		}
+
+		err = u.walk(&doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return applyEdits(src, u.edits)
+}
+
+// edit is a change to the source of a YAML file.
+// If Old is empty, New is inserted as a line after Line,
+// otherwise Old is replaced with New at Line and Column.
+type edit struct {
+	Line   int
+	Column int
+	Old    string
+	New    string
+}
+
+type updater struct {
+	images []*Image
+	edits  []edit
+}
+
+func (u *updater) find(ref string) *Image {
+	repo := parseRepository(ref)
+	for _, img := range u.images {
+		if img.Repository == repo {
+			return img
+		}
+	}
+
+	return nil
+}
+
+func (u *updater) walk(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			err := u.walk(child)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		err := u.helmValues(node)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch {
+			case key.Value == "image" && value.Kind == yaml.ScalarNode:
+				if img := u.find(value.Value); img != nil {
+					u.replace(value, img.Repository+"@"+img.Digest)
+				}
+				continue
+			case key.Value == "images" && value.Kind == yaml.SequenceNode:
+				for _, entry := range value.Content {
+					err = u.kustomizeImage(entry)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			err = u.walk(value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// kustomizeImage updates an entry of a kustomize images list.
+func (u *updater) kustomizeImage(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	name := lookup(node, "newName")
+	if name == nil {
+		name = lookup(node, "name")
+	}
+	if name == nil {
+		return nil
+	}
+
+	img := u.find(name.Value)
+	if img == nil {
+		return nil
+	}
+
+	if digest := lookup(node, "digest"); digest != nil {
+		u.replace(digest, img.Digest)
+		return nil
+	}
+
+	return u.insert(node, "digest", img.Digest)
+}
+
+// helmValues updates a Helm values image block.
+func (u *updater) helmValues(node *yaml.Node) error {
+	repository := lookup(node, "repository")
+	if repository == nil || repository.Kind != yaml.ScalarNode {
+		return nil
+	}
+
+	ref := repository.Value
+	if registry := lookup(node, "registry"); registry != nil {
+		ref = registry.Value + "/" + ref
+	}
+
+	img := u.find(ref)
+	if img == nil {
+		return nil
+	}
+
+	if digest := lookup(node, "digest"); digest != nil {
+		u.replace(digest, img.Digest)
+		return nil
+	}
+
+	if tag := lookup(node, "tag"); tag != nil {
+		u.replace(tag, img.Tag+"@"+img.Digest)
+		return nil
+	}
+
+	return u.insert(node, "digest", img.Digest)
+}
+
+// replace replaces the value of the scalar node, preserving its quoting style.
+func (u *updater) replace(node *yaml.Node, value string) {
+	if node.Value == value {
+		return
+	}
+
+	quote := ""
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		quote = `"`
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		quote = "'"
+	}
+
+	u.edits = append(u.edits, edit{
+		Line:   node.Line,
+		Column: node.Column,
+		Old:    quote + node.Value + quote,
+		New:    quote + value + quote,
+	})
+}
+
+// insert adds a key to a block mapping, after its last entry.
+func (u *updater) insert(node *yaml.Node, key, value string) error {
+	if node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 {
+		return fmt.Errorf("cannot add %q to the mapping on line %d", key, node.Line)
+	}
+
+	last := node.Content[len(node.Content)-1]
+	if last.Kind != yaml.ScalarNode || last.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return fmt.Errorf("cannot add %q after the value on line %d", key, last.Line)
+	}
+
+	u.edits = append(u.edits, edit{
+		Line: last.Line,
+		New:  strings.Repeat(" ", node.Content[0].Column-1) + key + ": " + value,
+	})
+
+	return nil
+}
+
+// applyEdits applies the edits to the source, starting from the end
+// so that earlier positions remain valid.
+func applyEdits(src []byte, edits []edit) ([]byte, error) {
+	if len(edits) == 0 {
+		return src, nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].Line != edits[j].Line {
+			return edits[i].Line > edits[j].Line
+		}
+		return edits[i].Column > edits[j].Column
+	})
+
+	lines := strings.SplitAfter(string(src), "\n")
+	for _, e := range edits {
+		if e.Line < 1 || e.Line > len(lines) {
+			return nil, fmt.Errorf("edit out of range on line %d", e.Line)
+		}
+
+		line := lines[e.Line-1]
+		if e.Old == "" {
+			ending := "\n"
+			if strings.HasSuffix(line, "\r\n") {
+				ending = "\r\n"
+			}
+			if !strings.HasSuffix(line, "\n") {
+				line += ending
+			}
+			lines[e.Line-1] = line + e.New + ending
+			continue
+		}
+
+		runes := []rune(line)
+		start := e.Column - 1
+		end := start + len([]rune(e.Old))
+		if start < 0 || end > len(runes) || string(runes[start:end]) != e.Old {
+			return nil, fmt.Errorf("unexpected formatting of %q on line %d", e.Old, e.Line)
+		}
+		lines[e.Line-1] = string(runes[:start]) + e.New + string(runes[end:])
+	}
+
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// lookup returns the value of the key in the mapping node, if it exists.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// parseRepository strips any tag and digest from the image reference.
+func parseRepository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+
+	return ref
+}
@@ -0,0 +1,135 @@
+package gitops_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/gitops"
+)
+
+func TestUpdate(t *testing.T) {
+	images := []*gitops.Image{
+		{
+			Repository: "docker.pkg.github.com/uw-labs/go-mono/user-api",
+			Tag:        "abc123",
+			Digest:     "sha256:0123456789",
+		},
+	}
+
+	tests := []struct {
+		Name string
+		In   string
+		Out  string
+	}{
+		{
+			Name: "It pins plain Kubernetes manifests",
+			In: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: user-api
+          # The application image
+          image: docker.pkg.github.com/uw-labs/go-mono/user-api:master
+        - name: sidecar
+          image: "docker.pkg.github.com/uw-labs/go-mono/other:master"
+`,
+			Out: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: user-api
+          # The application image
+          image: docker.pkg.github.com/uw-labs/go-mono/user-api@sha256:0123456789
+        - name: sidecar
+          image: "docker.pkg.github.com/uw-labs/go-mono/other:master"
+`,
+		},
+		{
+			Name: "It preserves quoting and handles multiple documents",
+			In: `kind: Pod
+---
+kind: Pod
+spec:
+  containers:
+    - image: 'docker.pkg.github.com/uw-labs/go-mono/user-api@sha256:old'
+`,
+			Out: `kind: Pod
+---
+kind: Pod
+spec:
+  containers:
+    - image: 'docker.pkg.github.com/uw-labs/go-mono/user-api@sha256:0123456789'
+`,
+		},
+		{
+			Name: "It adds a digest to kustomize images",
+			In: `resources:
+  - deployment.yaml
+images:
+  - name: user-api
+    newName: docker.pkg.github.com/uw-labs/go-mono/user-api
+    newTag: master
+  - name: docker.pkg.github.com/uw-labs/go-mono/user-api
+    digest: sha256:old
+`,
+			Out: `resources:
+  - deployment.yaml
+images:
+  - name: user-api
+    newName: docker.pkg.github.com/uw-labs/go-mono/user-api
+    newTag: master
+    digest: sha256:0123456789
+  - name: docker.pkg.github.com/uw-labs/go-mono/user-api
+    digest: sha256:0123456789
+`,
+		},
+		{
+			Name: "It updates Helm values",
+			In: `image:
+  registry: docker.pkg.github.com
+  repository: uw-labs/go-mono/user-api
+  tag: master
+worker:
+  image:
+    repository: docker.pkg.github.com/uw-labs/go-mono/user-api
+    digest: ""
+`,
+			Out: `image:
+  registry: docker.pkg.github.com
+  repository: uw-labs/go-mono/user-api
+  tag: abc123@sha256:0123456789
+worker:
+  image:
+    repository: docker.pkg.github.com/uw-labs/go-mono/user-api
+    digest: "sha256:0123456789"
+`,
+		},
+		{
+			Name: "It leaves unrelated files untouched",
+			In: `# Just a comment
+key:   value
+`,
+			Out: `# Just a comment
+key:   value
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			out, err := gitops.Update([]byte(test.In), images)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.Out, string(out)); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
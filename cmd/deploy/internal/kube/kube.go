@@ -0,0 +1,275 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+// Labels set on every rendered object. The name label is also used to select
+// the pods of the deployment.
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelVersion   = "app.kubernetes.io/version"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// Request is the input to Render.
+type Request struct {
+	Deployment *deploy.Deployment
+	// Image is the digest pinned reference of the published image.
+	Image string
+	// GitSHA is the commit the image was built from, used as its version.
+	GitSHA string
+	// Namespace is the namespace of the objects, if set.
+	Namespace string
+	// Kustomize adds a kustomization.yaml listing the other files,
+	// so that the files can be used as a kustomize base.
+	Kustomize bool
+}
+
+// File is a rendered manifest file.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Render renders the Deployment, and the Service and ConfigMap if there are
+// ports and environment variables, of the runtime of the deployment.
+func Render(req *Request) ([]*File, error) {
+	d := req.Deployment
+	rt := d.Runtime
+	if rt == nil {
+		return nil, fmt.Errorf("%s has no runtime", d.Name)
+	}
+	if !strings.Contains(req.Image, "@sha256:") {
+		return nil, fmt.Errorf("image %q is not pinned to a digest", req.Image)
+	}
+
+	labels := map[string]string{
+		LabelName:      d.Name,
+		LabelManagedBy: "deploy",
+	}
+	if req.GitSHA != "" {
+		labels[LabelVersion] = req.GitSHA
+	}
+	meta := objectMeta{
+		Name:      d.Name,
+		Namespace: req.Namespace,
+		Labels:    labels,
+	}
+
+	var objects []*object
+	c, err := newContainer(d, req.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rt.Env) > 0 {
+		objects = append(objects, &object{File: "configmap.yaml", Value: &configMap{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   meta,
+			Data:       rt.Env,
+		}})
+		c.EnvFrom = []envFromSource{{
+			ConfigMapRef: &localObjectReference{Name: d.Name},
+		}}
+	}
+
+	selector := map[string]string{LabelName: d.Name}
+	replicas := rt.Replicas
+	objects = append(objects, &object{File: "deployment.yaml", Value: &deployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   meta,
+		Spec: deploymentSpec{
+			Replicas: &replicas,
+			Selector: labelSelector{MatchLabels: selector},
+			Template: podTemplate{
+				Metadata: objectMeta{Labels: labels},
+				Spec: podSpec{
+					Containers: []*container{c},
+				},
+			},
+		},
+	}})
+
+	if len(rt.Ports) > 0 {
+		svc := &service{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   meta,
+			Spec: serviceSpec{
+				Selector: selector,
+			},
+		}
+		for _, p := range rt.Ports {
+			svc.Spec.Ports = append(svc.Spec.Ports, servicePort{
+				Name:       p.Name,
+				Port:       p.Port,
+				TargetPort: p.Name,
+			})
+		}
+		objects = append(objects, &object{File: "service.yaml", Value: svc})
+	}
+
+	files := make([]*File, 0, len(objects)+1)
+	k := &kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+	}
+	for _, o := range objects {
+		content, err := marshal(o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", o.File, err)
+		}
+		files = append(files, &File{Name: o.File, Content: content})
+		k.Resources = append(k.Resources, o.File)
+	}
+
+	if req.Kustomize {
+		content, err := marshal(k)
+		if err != nil {
+			return nil, fmt.Errorf("render kustomization.yaml: %w", err)
+		}
+		files = append(files, &File{Name: "kustomization.yaml", Content: content})
+	}
+
+	return files, nil
+}
+
+// newContainer returns the container running the image. The ports, flags
+// and secrets with flags are passed as arguments to its entrypoint.
+func newContainer(d *deploy.Deployment, image string) (*container, error) {
+	rt := d.Runtime
+	c := &container{
+		Name:  d.Name,
+		Image: image,
+	}
+
+	ports := map[string]int{}
+	for _, p := range rt.Ports {
+		ports[p.Name] = p.Port
+		c.Ports = append(c.Ports, containerPort{
+			Name:          p.Name,
+			ContainerPort: p.Port,
+		})
+		if p.Flag != "" {
+			c.Args = append(c.Args, "--"+p.Flag+"="+strconv.Itoa(p.Port))
+		}
+	}
+
+	for _, name := range sortedKeys(rt.Flags) {
+		c.Args = append(c.Args, "--"+name+"="+rt.Flags[name])
+	}
+
+	for _, s := range rt.Secrets {
+		c.Env = append(c.Env, envVar{
+			Name: s.Env,
+			ValueFrom: &envVarSource{
+				SecretKeyRef: &secretKeySelector{
+					Name: s.Secret,
+					Key:  s.Key,
+				},
+			},
+		})
+		if s.Flag != "" {
+			// Kubernetes expands references to environment variables in arguments.
+			c.Args = append(c.Args, "--"+s.Flag+"=$("+s.Env+")")
+		}
+	}
+
+	c.Resources = resourceRequirements{
+		Requests: resourceList(rt.Resources.Requests),
+		Limits:   resourceList(rt.Resources.Limits),
+	}
+
+	var err error
+	c.LivenessProbe, err = newProbe(rt.Probes.Liveness, ports)
+	if err != nil {
+		return nil, fmt.Errorf("liveness probe: %w", err)
+	}
+	c.ReadinessProbe, err = newProbe(rt.Probes.Readiness, ports)
+	if err != nil {
+		return nil, fmt.Errorf("readiness probe: %w", err)
+	}
+	c.StartupProbe, err = newProbe(rt.Probes.Startup, ports)
+	if err != nil {
+		return nil, fmt.Errorf("startup probe: %w", err)
+	}
+
+	return c, nil
+}
+
+func newProbe(p *deploy.Probe, ports map[string]int) (*probe, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if _, ok := ports[p.Port]; !ok {
+		return nil, errors.New("unknown port " + p.Port)
+	}
+
+	pr := &probe{
+		InitialDelaySeconds: int(p.InitialDelay.Seconds()),
+		PeriodSeconds:       int(p.Period.Seconds()),
+		FailureThreshold:    p.FailureThreshold,
+	}
+	if p.Path != "" {
+		pr.HTTPGet = &httpGetAction{Path: p.Path, Port: p.Port}
+	} else {
+		pr.TCPSocket = &tcpSocketAction{Port: p.Port}
+	}
+
+	return pr, nil
+}
+
+func resourceList(r deploy.ResourceList) map[string]string {
+	l := map[string]string{}
+	if r.CPU != "" {
+		l["cpu"] = r.CPU
+	}
+	if r.Memory != "" {
+		l["memory"] = r.Memory
+	}
+	if len(l) == 0 {
+		return nil
+	}
+
+	return l
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// marshal encodes the value as YAML indented with two spaces,
+// as is conventional for Kubernetes manifests.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
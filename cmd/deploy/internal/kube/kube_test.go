@@ -0,0 +1,213 @@
+package kube_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/kube"
+)
+
+const image = "registry.example.com/api@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *kube.Request
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "worker",
+			req: &kube.Request{
+				Deployment: &deploy.Deployment{
+					Name: "worker",
+					Runtime: &deploy.Runtime{
+						Replicas: 1,
+						Flags:    map[string]string{"queue": "jobs", "batch": "10"},
+					},
+				},
+				Image: image,
+			},
+			want: map[string]string{
+				"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app.kubernetes.io/managed-by: deploy
+    app.kubernetes.io/name: worker
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: worker
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/managed-by: deploy
+        app.kubernetes.io/name: worker
+    spec:
+      containers:
+        - name: worker
+          image: ` + image + `
+          args:
+            - --batch=10
+            - --queue=jobs
+`,
+			},
+		},
+		{
+			name: "api",
+			req: &kube.Request{
+				Deployment: &deploy.Deployment{
+					Name: "api",
+					Runtime: &deploy.Runtime{
+						Replicas: 2,
+						Ports:    []*deploy.Port{{Name: "http", Port: 8080, Flag: "port"}},
+						Env:      map[string]string{"LOG_LEVEL": "info"},
+						Secrets:  []*deploy.SecretRef{{Env: "DB_URL", Secret: "api", Key: "db-url", Flag: "db-url"}},
+						Resources: deploy.Resources{
+							Limits: deploy.ResourceList{Memory: "128Mi"},
+						},
+						Probes: deploy.Probes{
+							Readiness: &deploy.Probe{Port: "http", Path: "/ready", Period: 5 * time.Second},
+						},
+					},
+				},
+				Image:     image,
+				GitSHA:    "abc123",
+				Namespace: "apps",
+				Kustomize: true,
+			},
+			want: map[string]string{
+				"configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api
+  namespace: apps
+  labels:
+    app.kubernetes.io/managed-by: deploy
+    app.kubernetes.io/name: api
+    app.kubernetes.io/version: abc123
+data:
+  LOG_LEVEL: info
+`,
+				"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: apps
+  labels:
+    app.kubernetes.io/managed-by: deploy
+    app.kubernetes.io/name: api
+    app.kubernetes.io/version: abc123
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: api
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/managed-by: deploy
+        app.kubernetes.io/name: api
+        app.kubernetes.io/version: abc123
+    spec:
+      containers:
+        - name: api
+          image: ` + image + `
+          args:
+            - --port=8080
+            - --db-url=$(DB_URL)
+          ports:
+            - name: http
+              containerPort: 8080
+          envFrom:
+            - configMapRef:
+                name: api
+          env:
+            - name: DB_URL
+              valueFrom:
+                secretKeyRef:
+                  name: api
+                  key: db-url
+          resources:
+            limits:
+              memory: 128Mi
+          readinessProbe:
+            httpGet:
+              path: /ready
+              port: http
+            periodSeconds: 5
+`,
+				"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: apps
+  labels:
+    app.kubernetes.io/managed-by: deploy
+    app.kubernetes.io/name: api
+    app.kubernetes.io/version: abc123
+spec:
+  selector:
+    app.kubernetes.io/name: api
+  ports:
+    - name: http
+      port: 8080
+      targetPort: http
+`,
+				"kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - configmap.yaml
+  - deployment.yaml
+  - service.yaml
+`,
+			},
+		},
+		{
+			name: "tag",
+			req: &kube.Request{
+				Deployment: &deploy.Deployment{Name: "api", Runtime: &deploy.Runtime{Replicas: 1}},
+				Image:      "registry.example.com/api:latest",
+			},
+			wantErr: `image "registry.example.com/api:latest" is not pinned to a digest`,
+		},
+		{
+			name: "no runtime",
+			req: &kube.Request{
+				Deployment: &deploy.Deployment{Name: "api"},
+				Image:      image,
+			},
+			wantErr: "api has no runtime",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			files, err := kube.Render(test.req)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := map[string]string{}
+			for _, f := range files {
+				got[f.Name] = string(f.Content)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected files (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
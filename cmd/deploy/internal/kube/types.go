@@ -0,0 +1,135 @@
+package kube
+
+// The subset of the Kubernetes API used by the rendered manifests.
+// See https://kubernetes.io/docs/reference/kubernetes-api/.
+
+type object struct {
+	File  string
+	Value interface{}
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type deployment struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   objectMeta     `yaml:"metadata"`
+	Spec       deploymentSpec `yaml:"spec"`
+}
+
+type deploymentSpec struct {
+	Replicas *int          `yaml:"replicas,omitempty"`
+	Selector labelSelector `yaml:"selector"`
+	Template podTemplate   `yaml:"template"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `yaml:"metadata"`
+	Spec     podSpec    `yaml:"spec"`
+}
+
+type podSpec struct {
+	Containers []*container `yaml:"containers"`
+}
+
+type container struct {
+	Name           string               `yaml:"name"`
+	Image          string               `yaml:"image"`
+	Args           []string             `yaml:"args,omitempty"`
+	Ports          []containerPort      `yaml:"ports,omitempty"`
+	EnvFrom        []envFromSource      `yaml:"envFrom,omitempty"`
+	Env            []envVar             `yaml:"env,omitempty"`
+	Resources      resourceRequirements `yaml:"resources,omitempty"`
+	LivenessProbe  *probe               `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe *probe               `yaml:"readinessProbe,omitempty"`
+	StartupProbe   *probe               `yaml:"startupProbe,omitempty"`
+}
+
+type containerPort struct {
+	Name          string `yaml:"name"`
+	ContainerPort int    `yaml:"containerPort"`
+}
+
+type envFromSource struct {
+	ConfigMapRef *localObjectReference `yaml:"configMapRef,omitempty"`
+}
+
+type localObjectReference struct {
+	Name string `yaml:"name"`
+}
+
+type envVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *envVarSource `yaml:"valueFrom,omitempty"`
+}
+
+type envVarSource struct {
+	SecretKeyRef *secretKeySelector `yaml:"secretKeyRef,omitempty"`
+}
+
+type secretKeySelector struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
+}
+
+type probe struct {
+	HTTPGet             *httpGetAction   `yaml:"httpGet,omitempty"`
+	TCPSocket           *tcpSocketAction `yaml:"tcpSocket,omitempty"`
+	InitialDelaySeconds int              `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int              `yaml:"periodSeconds,omitempty"`
+	FailureThreshold    int              `yaml:"failureThreshold,omitempty"`
+}
+
+type httpGetAction struct {
+	Path string `yaml:"path"`
+	Port string `yaml:"port"`
+}
+
+type tcpSocketAction struct {
+	Port string `yaml:"port"`
+}
+
+type service struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   objectMeta  `yaml:"metadata"`
+	Spec       serviceSpec `yaml:"spec"`
+}
+
+type serviceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []servicePort     `yaml:"ports"`
+}
+
+type servicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort string `yaml:"targetPort"`
+}
+
+type configMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   objectMeta        `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
@@ -0,0 +1,117 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Release describes a single image published by the deploy tool.
+type Release struct {
+	Service string `json:"service"`
+	// Target is the name of the target the image was pushed to.
+	Target     string   `json:"target,omitempty"`
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags"`
+	Digest     string   `json:"digest"`
+	Image      string   `json:"image"`
+	GitSHA     string   `json:"gitSHA"`
+	GitBranch  string   `json:"gitBranch"`
+	// Dirty is true if the image was built from uncommitted changes,
+	// and PullRequest is the number of the pull request built, if any.
+	Dirty       bool      `json:"dirty,omitempty"`
+	PullRequest string    `json:"pullRequest,omitempty"`
+	BuildTime   time.Time `json:"buildTime"`
+	Platforms   []string  `json:"platforms"`
+	Size        int64     `json:"size"`
+	// Signature and Attestation are the references of the image
+	// signature and provenance attestation, if the image was signed.
+	Signature   string `json:"signature,omitempty"`
+	Attestation string `json:"attestation,omitempty"`
+	// SBOM is the reference of the attached software bill of
+	// materials, and SBOMFile the path it was written to, if any.
+	SBOM     string `json:"sbom,omitempty"`
+	SBOMFile string `json:"sbomFile,omitempty"`
+	// PromotedFrom is the reference of the image a promoted image was
+	// copied from. The BuildTime of a promotion is the time it happened.
+	PromotedFrom string `json:"promotedFrom,omitempty"`
+}
+
+// Manifest describes every image published in a single run of the deploy tool.
+type Manifest struct {
+	Releases []*Release `json:"releases"`
+}
+
+// Write writes the manifest to the path as indented JSON,
+// replacing any existing file.
+func Write(path string, m *Manifest) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create manifest file: %w", err)
+	}
+	defer func() {
+		cErr := f.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(m)
+	if err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Read reads a manifest previously written with Write.
+func Read(path string) (_ *Manifest, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open manifest file: %w", err)
+	}
+	defer func() {
+		cErr := f.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	var m Manifest
+	err = json.NewDecoder(f).Decode(&m)
+	if err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// Append appends the releases to the releases file at the path, one
+// JSON object per line, creating the file if it does not exist.
+// The line-oriented format allows the releases of several runs to be
+// aggregated by simple concatenation.
+func Append(path string, releases ...*Release) (err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open releases file: %w", err)
+	}
+	defer func() {
+		cErr := f.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	enc := json.NewEncoder(f)
+	for _, r := range releases {
+		err = enc.Encode(r)
+		if err != nil {
+			return fmt.Errorf("append release: %w", err)
+		}
+	}
+
+	return nil
+}
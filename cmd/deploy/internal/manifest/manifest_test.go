@@ -0,0 +1,120 @@
+package manifest_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/manifest"
+)
+
+func newRelease(service, digest string) *manifest.Release {
+	return &manifest.Release{
+		Service:    service,
+		Target:     "primary",
+		Repository: "docker.pkg.github.com/uw-labs/go-mono/" + service,
+		Tags:       []string{"abc1234", "master"},
+		Digest:     digest,
+		Image:      "docker.pkg.github.com/uw-labs/go-mono/" + service + "@" + digest,
+		GitSHA:     "abc1234567890",
+		GitBranch:  "master",
+		BuildTime:  time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Platforms:  []string{"linux/amd64"},
+		Size:       1024,
+		Signature:  "docker.pkg.github.com/uw-labs/go-mono/" + service + ":sha256-0123.sig",
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(tempDir(t), "manifest.json")
+	want := &manifest.Manifest{
+		Releases: []*manifest.Release{
+			newRelease("user-api", "sha256:0123"),
+			newRelease("billing-api", "sha256:4567"),
+		},
+	}
+
+	// Writing again replaces the previous manifest.
+	err := manifest.Write(path, &manifest.Manifest{Releases: []*manifest.Release{newRelease("old-api", "sha256:89ab")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = manifest.Write(path, want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := manifest.Read(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected manifest (-want +got):\n%s", diff)
+	}
+
+	_, err = manifest.Read(filepath.Join(filepath.Dir(path), "missing.json"))
+	if err == nil {
+		t.Error("expected reading a missing manifest to fail")
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(tempDir(t), "releases.jsonl")
+	want := []*manifest.Release{
+		newRelease("user-api", "sha256:0123"),
+		newRelease("billing-api", "sha256:4567"),
+		newRelease("user-api", "sha256:89ab"),
+	}
+
+	// The file does not exist before the first run, so is created.
+	err := manifest.Append(path, want[:2]...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = manifest.Append(path, want[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	var got []*manifest.Release
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r manifest.Release
+		err = json.Unmarshal(sc.Bytes(), &r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, &r)
+	}
+	if err = sc.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected releases (-want +got):\n%s", diff)
+	}
+}
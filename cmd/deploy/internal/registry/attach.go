@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AttachmentTag returns the tag under which artifacts of the kind
+// (such as "sig", "att" or "sbom") are attached to the image with the digest,
+// following the conventions used by cosign.
+func AttachmentTag(digest, kind string) string {
+	return strings.Replace(digest, ":", "-", 1) + "." + kind
+}
+
+// Attachment is an artifact to attach to an image.
+type Attachment struct {
+	// Kind is the kind of the attachment, used as the tag suffix.
+	Kind        string
+	MediaType   string
+	Content     []byte
+	Annotations map[string]string
+	// Append adds the attachment to any existing attachments of the
+	// same kind, rather than replacing them. Signatures and attestations
+	// are appended, so that an image can be signed by several parties.
+	// An existing attachment with the same content, or with the same
+	// media type and annotations, is replaced, so attaching again, such
+	// as when a release is re-run, does not grow the artifact.
+	Append bool
+}
+
+// Attach stores the attachment as a single layer OCI artifact tagged in the
+// same repository as the image, where tools like cosign can discover it.
+// It returns the reference of the attachment.
+func (c *Client) Attach(ctx context.Context, repo Repository, digest string, a *Attachment) (string, error) {
+	tag := AttachmentTag(digest, a.Kind)
+
+	var layers []Descriptor
+	if a.Append {
+		existing, err := c.GetManifest(ctx, repo, tag)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return "", err
+		default:
+			var m Manifest
+			err = json.Unmarshal(existing.Body, &m)
+			if err != nil {
+				return "", fmt.Errorf("parse existing %s manifest: %w", a.Kind, err)
+			}
+			layers = m.Layers
+		}
+	}
+
+	layerDigest, err := c.PutBlob(ctx, repo, a.Content)
+	if err != nil {
+		return "", err
+	}
+
+	layer := Descriptor{
+		MediaType:   a.MediaType,
+		Digest:      layerDigest,
+		Size:        int64(len(a.Content)),
+		Annotations: a.Annotations,
+	}
+	kept := layers[:0]
+	for _, l := range layers {
+		if !replaces(layer, l) {
+			kept = append(kept, l)
+		}
+	}
+	layers = append(kept, layer)
+
+	diffIDs := make([]string, 0, len(layers))
+	for _, l := range layers {
+		diffIDs = append(diffIDs, l.Digest)
+	}
+	config, err := json.Marshal(map[string]interface{}{
+		"architecture": "",
+		"os":           "",
+		"config":       map[string]interface{}{},
+		"rootfs": map[string]interface{}{
+			"type":     "layers",
+			"diff_ids": diffIDs,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal %s config: %w", a.Kind, err)
+	}
+
+	configDigest, err := c.PutBlob(ctx, repo, config)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(&Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeOCIManifest,
+		Config: Descriptor{
+			MediaType: MediaTypeOCIConfig,
+			Digest:    configDigest,
+			Size:      int64(len(config)),
+		},
+		Layers: layers,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal %s manifest: %w", a.Kind, err)
+	}
+
+	_, err = c.PutManifest(ctx, repo, tag, &RawManifest{
+		MediaType: MediaTypeOCIManifest,
+		Body:      body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return repo.String() + ":" + tag, nil
+}
+
+// replaces reports whether the new layer replaces the existing layer, as it has
+// the same content, or the same media type and annotations.
+func replaces(layer, existing Descriptor) bool {
+	if layer.Digest == existing.Digest {
+		return true
+	}
+	if layer.MediaType != existing.MediaType || len(layer.Annotations) != len(existing.Annotations) {
+		return false
+	}
+	for k, v := range layer.Annotations {
+		if ev, ok := existing.Annotations[k]; !ok || ev != v {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,258 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+)
+
+// Copy copies the manifest identified by the tag or digest in the source
+// repository to the destination repository, tagging it with the tags.
+// Multi-platform indexes are copied with every manifest they reference.
+// The source and destination may be in different registries, accessed
+// with different clients. Manifests are copied byte for byte, so the
+// digest is the same in both repositories.
+func Copy(ctx context.Context, srcClient *Client, src Repository, ref string, dstClient *Client, dst Repository, tags []string) (*CopyResult, error) {
+	c := &copier{
+		srcClient: srcClient,
+		src:       src,
+		dstClient: dstClient,
+		dst:       dst,
+		res:       &CopyResult{},
+	}
+
+	m, err := srcClient.GetManifest(ctx, src, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.res.Manifest = m
+
+	err = c.copyContent(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) == 0 {
+		tags = []string{m.Digest}
+	}
+	for _, tag := range tags {
+		_, err = dstClient.PutManifest(ctx, dst, tag, m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.res, nil
+}
+
+// CopyAttachments copies the attachments of the kinds, such as "sig" or
+// "sbom", of the image with the digest from the source repository to the
+// destination repository. It returns the references of the copied
+// attachments by kind, skipping kinds the image has no attachment of.
+func CopyAttachments(ctx context.Context, srcClient *Client, src Repository, digest string, dstClient *Client, dst Repository, kinds []string) (map[string]string, error) {
+	refs := map[string]string{}
+	for _, kind := range kinds {
+		tag := AttachmentTag(digest, kind)
+		_, err := Copy(ctx, srcClient, src, tag, dstClient, dst, []string{tag})
+		switch {
+		case errors.Is(err, ErrNotFound):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("copy %s attachment: %w", kind, err)
+		}
+
+		refs[kind] = dst.String() + ":" + tag
+	}
+
+	return refs, nil
+}
+
+// CopyResult describes the copied image.
+type CopyResult struct {
+	Manifest *RawManifest
+	// Platforms lists the OS/architecture pairs of the copied images.
+	Platforms []string
+	// Size is the total size of the configs and layers of the copied images.
+	Size int64
+	// Labels are the labels of the copied image. For multi-platform
+	// images, they are the labels of the first platform.
+	Labels map[string]string
+}
+
+type copier struct {
+	srcClient *Client
+	src       Repository
+	dstClient *Client
+	dst       Repository
+	res       *CopyResult
+}
+
+// index is an OCI image index or Docker manifest list.
+type index struct {
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// imageConfig holds the fields of an image configuration used by the copier.
+type imageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// copyContent copies the blobs and child manifests referenced by the manifest.
+func (c *copier) copyContent(ctx context.Context, m *RawManifest) error {
+	switch m.MediaType {
+	case MediaTypeOCIIndex, MediaTypeDockerManifestList:
+		var idx index
+		err := json.Unmarshal(m.Body, &idx)
+		if err != nil {
+			return fmt.Errorf("parse index %s: %w", m.Digest, err)
+		}
+
+		for _, desc := range idx.Manifests {
+			child, err := c.srcClient.GetManifest(ctx, c.src, desc.Digest)
+			if err != nil {
+				return err
+			}
+			err = c.copyContent(ctx, child)
+			if err != nil {
+				return err
+			}
+			_, err = c.dstClient.PutManifest(ctx, c.dst, child.Digest, child)
+			if err != nil {
+				return err
+			}
+		}
+	case MediaTypeOCIManifest, MediaTypeDockerManifest:
+		var manifest Manifest
+		err := json.Unmarshal(m.Body, &manifest)
+		if err != nil {
+			return fmt.Errorf("parse manifest %s: %w", m.Digest, err)
+		}
+
+		for _, desc := range append([]Descriptor{manifest.Config}, manifest.Layers...) {
+			err = c.copyBlob(ctx, desc)
+			if err != nil {
+				return err
+			}
+			c.res.Size += desc.Size
+		}
+
+		err = c.describe(ctx, manifest.Config)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported manifest media type %q", m.MediaType)
+	}
+
+	return nil
+}
+
+// describe records the platform and labels of the image configuration.
+func (c *copier) describe(ctx context.Context, desc Descriptor) (err error) {
+	rc, err := c.srcClient.GetBlob(ctx, c.src, desc.Digest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cErr := rc.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	var config imageConfig
+	err = json.NewDecoder(rc).Decode(&config)
+	if err != nil {
+		return fmt.Errorf("parse image config %s: %w", desc.Digest, err)
+	}
+
+	if config.OS != "" {
+		platform := config.OS + "/" + config.Architecture
+		if config.Variant != "" {
+			platform += "/" + config.Variant
+		}
+		c.res.Platforms = append(c.res.Platforms, platform)
+	}
+	if c.res.Labels == nil {
+		c.res.Labels = config.Config.Labels
+	}
+
+	return nil
+}
+
+// copyBlob copies the blob, unless it already exists in the destination.
+// Blobs are mounted rather than copied within a registry where possible.
+func (c *copier) copyBlob(ctx context.Context, desc Descriptor) (err error) {
+	ok, err := c.dstClient.BlobExists(ctx, c.dst, desc.Digest)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	var query string
+	if c.src.Host == c.dst.Host {
+		query = url.Values{
+			"mount": {desc.Digest},
+			"from":  {c.src.Path},
+		}.Encode()
+	}
+
+	location, err := c.dstClient.startUpload(ctx, c.dst, query)
+	if err != nil {
+		return err
+	}
+	if location == "" {
+		// Mounted
+		return nil
+	}
+
+	// Buffer the blob on disk, so that the upload can be
+	// retried if the destination requires authentication.
+	f, err := ioutil.TempFile("", "blob")
+	if err != nil {
+		return fmt.Errorf("create blob file: %w", err)
+	}
+	defer func() {
+		cErr := f.Close()
+		rErr := os.Remove(f.Name())
+		if err == nil {
+			err = cErr
+		}
+		if err == nil {
+			err = rErr
+		}
+	}()
+
+	rc, err := c.srcClient.GetBlob(ctx, c.src, desc.Digest)
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(f, rc)
+	rc.Close()
+	if err != nil {
+		return fmt.Errorf("download blob %s: %w", desc.Digest, err)
+	}
+	if size != desc.Size {
+		return errors.New("downloaded blob " + desc.Digest + " has an unexpected size")
+	}
+
+	return c.dstClient.finishUpload(ctx, c.dst, location, desc.Digest, size, func() (io.Reader, error) {
+		_, err := f.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(f), nil
+	})
+}
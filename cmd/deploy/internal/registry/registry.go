@@ -0,0 +1,496 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Media types of the manifests and artifacts handled by the client.
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	MediaTypeOCIConfig          = "application/vnd.oci.image.config.v1+json"
+)
+
+// ErrNotFound is returned when a manifest or blob does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Descriptor describes content stored in a registry.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *Platform         `json:"platform,omitempty"`
+}
+
+// Platform describes the platform of an image in an index.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Manifest is an OCI image manifest, or a Docker V2 schema 2 manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// RawManifest is a manifest as stored in the registry.
+type RawManifest struct {
+	MediaType string
+	Digest    string
+	Body      []byte
+}
+
+// Repository is the location of a repository in a registry.
+type Repository struct {
+	// Host is the registry host, including any port.
+	Host string
+	// Path is the path of the repository within the registry.
+	Path string
+}
+
+// ParseRepository parses an image repository name, such as
+// docker.pkg.github.com/uw-labs/go-mono/user-api. Names without
+// a registry host refer to Docker Hub.
+func ParseRepository(name string) (Repository, error) {
+	if name == "" || strings.ContainsAny(name, "@") {
+		return Repository{}, fmt.Errorf("invalid repository name %q", name)
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return Repository{Host: parts[0], Path: parts[1]}, nil
+	}
+
+	if len(parts) == 1 {
+		name = "library/" + name
+	}
+
+	return Repository{Host: "registry-1.docker.io", Path: name}, nil
+}
+
+// String returns the name of the repository.
+func (r Repository) String() string {
+	return r.Host + "/" + r.Path
+}
+
+func (r Repository) baseURL() string {
+	scheme := "https"
+	host := strings.Split(r.Host, ":")[0]
+	if host == "localhost" || host == "127.0.0.1" {
+		scheme = "http"
+	}
+
+	return scheme + "://" + r.Host + "/v2/" + r.Path
+}
+
+// Client talks to registries using the Docker Registry HTTP API V2.
+// It is safe for concurrent use.
+type Client struct {
+	httpClient *http.Client
+	username   string
+	password   string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient creates a client authenticating with the username
+// and password, if set.
+func NewClient(username, password string) *Client {
+	return &Client{
+		httpClient: http.DefaultClient,
+		username:   username,
+		password:   password,
+		tokens:     map[string]string{},
+	}
+}
+
+// Digest returns the SHA256 digest of the content.
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// GetManifest fetches the manifest identified by the tag or digest.
+func (c *Client) GetManifest(ctx context.Context, repo Repository, ref string) (*RawManifest, error) {
+	resp, err := c.do(ctx, repo, "pull", func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, repo.baseURL()+"/manifests/"+ref, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join([]string{
+			MediaTypeOCIManifest,
+			MediaTypeOCIIndex,
+			MediaTypeDockerManifest,
+			MediaTypeDockerManifestList,
+		}, ", "))
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get manifest %s:%s: %w", repo, ref, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s:%s: %w", repo, ref, err)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = Digest(body)
+	}
+
+	return &RawManifest{
+		MediaType: strings.Split(resp.Header.Get("Content-Type"), ";")[0],
+		Digest:    digest,
+		Body:      body,
+	}, nil
+}
+
+// PutManifest uploads the manifest, tagging it with the reference.
+// It returns the digest of the manifest.
+func (c *Client) PutManifest(ctx context.Context, repo Repository, ref string, m *RawManifest) (string, error) {
+	resp, err := c.do(ctx, repo, "pull,push", func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPut, repo.baseURL()+"/manifests/"+ref, bytes.NewReader(m.Body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", m.MediaType)
+		return req, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("put manifest %s:%s: %w", repo, ref, err)
+	}
+	defer resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = Digest(m.Body)
+	}
+
+	return digest, nil
+}
+
+// BlobExists reports whether the blob exists in the repository.
+func (c *Client) BlobExists(ctx context.Context, repo Repository, digest string) (bool, error) {
+	resp, err := c.do(ctx, repo, "pull", func() (*http.Request, error) {
+		return http.NewRequest(http.MethodHead, repo.baseURL()+"/blobs/"+digest, nil)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check blob %s@%s: %w", repo, digest, err)
+	}
+	resp.Body.Close()
+
+	return true, nil
+}
+
+// PutBlob uploads the content as a blob, unless it already exists.
+// It returns the digest of the content.
+func (c *Client) PutBlob(ctx context.Context, repo Repository, content []byte) (string, error) {
+	digest := Digest(content)
+	ok, err := c.BlobExists(ctx, repo, digest)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return digest, nil
+	}
+
+	location, err := c.startUpload(ctx, repo, "")
+	if err != nil {
+		return "", err
+	}
+
+	err = c.finishUpload(ctx, repo, location, digest, int64(len(content)), func() (io.Reader, error) {
+		return bytes.NewReader(content), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return digest, nil
+}
+
+// GetBlob fetches the content of the blob. The caller must close it.
+func (c *Client) GetBlob(ctx context.Context, repo Repository, digest string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, repo, "pull", func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, repo.baseURL()+"/blobs/"+digest, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get blob %s@%s: %w", repo, digest, err)
+	}
+
+	return resp.Body, nil
+}
+
+// startUpload starts a blob upload, returning the upload location.
+// If query is set, it is added to the request, for example to mount a blob.
+func (c *Client) startUpload(ctx context.Context, repo Repository, query string) (string, error) {
+	resp, err := c.do(ctx, repo, "pull,push", func() (*http.Request, error) {
+		u := repo.baseURL() + "/blobs/uploads/"
+		if query != "" {
+			u += "?" + query
+		}
+		return http.NewRequest(http.MethodPost, u, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("start blob upload to %s: %w", repo, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusCreated {
+		// The blob was mounted, no upload is required
+		return "", nil
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", fmt.Errorf("parse blob upload location: %w", err)
+	}
+
+	return location.String(), nil
+}
+
+// finishUpload uploads the blob content to the location in a single request.
+// The body is called to open the content for every attempt.
+func (c *Client) finishUpload(ctx context.Context, repo Repository, location, digest string, size int64, body func() (io.Reader, error)) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("parse blob upload location: %w", err)
+	}
+	q := u.Query()
+	q.Set("digest", digest)
+	u.RawQuery = q.Encode()
+
+	resp, err := c.do(ctx, repo, "pull,push", func() (*http.Request, error) {
+		r, err := body()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodPut, u.String(), r)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("upload blob to %s: %w", repo, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// do sends the request built by newReq, authenticating against the
+// registry when challenged. The request is rebuilt for every attempt.
+func (c *Client) do(ctx context.Context, repo Repository, actions string, newReq func() (*http.Request, error)) (*http.Response, error) {
+	scope := "repository:" + repo.Path + ":" + actions
+	key := repo.Host + " " + scope
+
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		req = req.WithContext(ctx)
+
+		c.mu.Lock()
+		auth := c.tokens[key]
+		c.mu.Unlock()
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+
+			auth, err = c.authenticate(ctx, challenge, scope)
+			if err != nil {
+				return nil, fmt.Errorf("authenticate: %w", err)
+			}
+			c.mu.Lock()
+			c.tokens[key] = auth
+			c.mu.Unlock()
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+}
+
+// StatusError is returned when the registry responds with an unexpected status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Temporary reports whether the error may go away when the request is
+// retried, such as server errors, rate limiting, timeouts and network errors.
+func Temporary(err error) bool {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+// authenticate responds to the WWW-Authenticate challenge,
+// returning the value of the Authorization header to use.
+func (c *Client) authenticate(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		creds := base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))
+		return "Basic " + creds, nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	q := u.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("create token request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("request token: %w", &StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		})
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("parse token response: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header, such as
+//
+//	Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.TrimSpace(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end < 0 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:end], rest[end:]
+			}
+		}
+		params[strings.ToLower(key)] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+
+	return parts[0], params
+}
@@ -0,0 +1,334 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+)
+
+// fakeRegistry is an in-memory registry requiring token authentication.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string]*registry.RawManifest
+}
+
+func newFakeRegistry(t *testing.T) *httptest.Server {
+	reg := &fakeRegistry{
+		blobs:     map[string][]byte{},
+		manifests: map[string]*registry.RawManifest{},
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, _ := r.BasicAuth()
+			if user != "user" || pass != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="fake"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		switch {
+		case strings.Contains(path, "/blobs/uploads/") && r.Method == http.MethodPost:
+			w.Header().Set("Location", "/v2/"+path+"session")
+			w.WriteHeader(http.StatusAccepted)
+		case strings.Contains(path, "/blobs/uploads/") && r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			digest := r.URL.Query().Get("digest")
+			if registry.Digest(body) != digest {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reg.blobs[digest] = body
+			w.WriteHeader(http.StatusCreated)
+		case strings.Contains(path, "/blobs/"):
+			digest := path[strings.LastIndex(path, "/")+1:]
+			blob, ok := reg.blobs[digest]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(blob)
+		case strings.Contains(path, "/manifests/") && r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			m := &registry.RawManifest{
+				MediaType: r.Header.Get("Content-Type"),
+				Digest:    registry.Digest(body),
+				Body:      body,
+			}
+			reg.manifests[path] = m
+			w.Header().Set("Docker-Content-Digest", m.Digest)
+			w.WriteHeader(http.StatusCreated)
+		case strings.Contains(path, "/manifests/"):
+			m, ok := reg.manifests[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", m.MediaType)
+			w.Header().Set("Docker-Content-Digest", m.Digest)
+			_, _ = w.Write(m.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestAttach(t *testing.T) {
+	srv := newFakeRegistry(t)
+	ctx := context.Background()
+
+	repo, err := registry.ParseRepository(strings.TrimPrefix(srv.URL, "http://") + "/uw-labs/user-api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := registry.NewClient("user", "password")
+	digest := "sha256:0123456789"
+
+	for i, content := range []string{"first", "second"} {
+		ref, err := client.Attach(ctx, repo, digest, &registry.Attachment{
+			Kind:        "sig",
+			MediaType:   "text/plain",
+			Content:     []byte(content),
+			Annotations: map[string]string{"index": content},
+			Append:      true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := repo.String() + ":sha256-0123456789.sig"; ref != want {
+			t.Errorf("expected reference %q, got %q", want, ref)
+		}
+
+		raw, err := client.GetManifest(ctx, repo, registry.AttachmentTag(digest, "sig"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if raw.MediaType != registry.MediaTypeOCIManifest {
+			t.Errorf("expected media type %q, got %q", registry.MediaTypeOCIManifest, raw.MediaType)
+		}
+
+		var m registry.Manifest
+		err = json.Unmarshal(raw.Body, &m)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(m.Layers) != i+1 {
+			t.Fatalf("expected %d layers, got %d", i+1, len(m.Layers))
+		}
+		if m.Layers[i].Digest != registry.Digest([]byte(content)) {
+			t.Errorf("expected layer digest of %q, got %q", content, m.Layers[i].Digest)
+		}
+		if m.Layers[i].Annotations["index"] != content {
+			t.Errorf("expected annotation %q, got %q", content, m.Layers[i].Annotations["index"])
+		}
+	}
+
+	for _, test := range []struct {
+		name        string
+		content     string
+		annotations map[string]string
+		wantLayers  []string
+	}{
+		{
+			name:        "It replaces a layer with the same content",
+			content:     "first",
+			annotations: map[string]string{"index": "again"},
+			wantLayers:  []string{"second", "first"},
+		},
+		{
+			name:        "It replaces a layer with the same media type and annotations",
+			content:     "third",
+			annotations: map[string]string{"index": "second"},
+			wantLayers:  []string{"first", "third"},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.Attach(ctx, repo, digest, &registry.Attachment{
+				Kind:        "sig",
+				MediaType:   "text/plain",
+				Content:     []byte(test.content),
+				Annotations: test.annotations,
+				Append:      true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			raw, err := client.GetManifest(ctx, repo, registry.AttachmentTag(digest, "sig"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var m registry.Manifest
+			err = json.Unmarshal(raw.Body, &m)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, l := range m.Layers {
+				got = append(got, l.Digest)
+			}
+			var want []string
+			for _, content := range test.wantLayers {
+				want = append(want, registry.Digest([]byte(content)))
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unexpected layers (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	_, err = registry.NewClient("user", "wrong").GetManifest(ctx, repo, "latest")
+	if err == nil {
+		t.Error("expected authentication with the wrong password to fail")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	client := registry.NewClient("user", "password")
+
+	var repos []registry.Repository
+	for i := 0; i < 2; i++ {
+		srv := newFakeRegistry(t)
+		repo, err := registry.ParseRepository(strings.TrimPrefix(srv.URL, "http://") + "/uw-labs/user-api")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repos = append(repos, repo)
+	}
+	src, dst := repos[0], repos[1]
+
+	var children []registry.Descriptor
+	for _, arch := range []string{"amd64", "arm64"} {
+		config := []byte(`{"os":"linux","architecture":"` + arch + `","config":{"Labels":{"revision":"0123456"}}}`)
+		layer := []byte("layer for " + arch)
+
+		var descs []registry.Descriptor
+		for _, content := range [][]byte{config, layer} {
+			digest, err := client.PutBlob(ctx, src, content)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			descs = append(descs, registry.Descriptor{Digest: digest, Size: int64(len(content))})
+		}
+
+		body, err := json.Marshal(&registry.Manifest{
+			SchemaVersion: 2,
+			MediaType:     registry.MediaTypeOCIManifest,
+			Config:        descs[0],
+			Layers:        descs[1:],
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		m := &registry.RawManifest{MediaType: registry.MediaTypeOCIManifest, Digest: registry.Digest(body), Body: body}
+		_, err = client.PutManifest(ctx, src, m.Digest, m)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		children = append(children, registry.Descriptor{
+			MediaType: m.MediaType,
+			Digest:    m.Digest,
+			Size:      int64(len(body)),
+			Platform:  &registry.Platform{OS: "linux", Architecture: arch},
+		})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     registry.MediaTypeOCIIndex,
+		"manifests":     children,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	idx := &registry.RawManifest{MediaType: registry.MediaTypeOCIIndex, Digest: registry.Digest(body), Body: body}
+	_, err = client.PutManifest(ctx, src, "staging", idx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := registry.Copy(ctx, client, src, "staging", client, dst, []string{"production", "v1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Manifest.Digest != idx.Digest {
+		t.Errorf("expected digest %q, got %q", idx.Digest, res.Manifest.Digest)
+	}
+	if diff := cmp.Diff([]string{"linux/amd64", "linux/arm64"}, res.Platforms); diff != "" {
+		t.Errorf("unexpected platforms (-want +got):\n%s", diff)
+	}
+	if res.Labels["revision"] != "0123456" {
+		t.Errorf("expected revision label %q, got %q", "0123456", res.Labels["revision"])
+	}
+
+	for _, tag := range []string{"production", "v1"} {
+		m, err := client.GetManifest(ctx, dst, tag)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m.Digest != idx.Digest {
+			t.Errorf("expected %s to have digest %q, got %q", tag, idx.Digest, m.Digest)
+		}
+	}
+	for _, child := range children {
+		_, err = client.GetManifest(ctx, dst, child.Digest)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	_, err = registry.Copy(ctx, client, src, "missing", client, dst, []string{"production"})
+	if !errors.Is(err, registry.ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	_, err = client.Attach(ctx, src, idx.Digest, &registry.Attachment{
+		Kind:      "sig",
+		MediaType: "text/plain",
+		Content:   []byte("signature"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refs, err := registry.CopyAttachments(ctx, client, src, idx.Digest, client, dst, []string{"sig", "sbom"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"sig": dst.String() + ":" + registry.AttachmentTag(idx.Digest, "sig")}
+	if diff := cmp.Diff(want, refs); diff != "" {
+		t.Errorf("unexpected attachments (-want +got):\n%s", diff)
+	}
+}
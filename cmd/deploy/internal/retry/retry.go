@@ -0,0 +1,84 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Policy configures retries with exponential backoff.
+type Policy struct {
+	// Attempts is the maximum number of attempts, including the first.
+	// Values below 1 are treated as 1.
+	Attempts int
+	// Backoff is the delay before the first retry. It is doubled
+	// for every subsequent retry, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps the error so that Do returns it without retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a permanent error, the attempts
+// are exhausted or the context is done. Retries are logged with the
+// name of the operation. The last error is returned.
+func (p Policy) Do(ctx context.Context, logger logrus.FieldLogger, op string, fn func(ctx context.Context) error) error {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var perm *permanentError
+		switch {
+		case errors.As(err, &perm):
+			return perm.err
+		case ctx.Err() != nil:
+			return err
+		case attempt >= p.Attempts:
+			if attempt > 1 {
+				return fmt.Errorf("%s failed after %d attempts: %w", op, attempt, err)
+			}
+			return err
+		}
+
+		logger.WithError(err).Warnf("%s failed, retrying in %s (attempt %d of %d)", op, backoff, attempt, p.Attempts)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
+)
+
+func TestDo(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	tests := []struct {
+		Name      string
+		Errors    []error
+		WantCalls int
+		WantErr   error
+	}{
+		{
+			Name:      "It does not retry successful calls",
+			Errors:    []error{nil},
+			WantCalls: 1,
+		},
+		{
+			Name:      "It retries until the call succeeds",
+			Errors:    []error{errTransient, errTransient, nil},
+			WantCalls: 3,
+		},
+		{
+			Name:      "It returns the last error when the attempts are exhausted",
+			Errors:    []error{errTransient, errTransient, errTransient, nil},
+			WantCalls: 3,
+			WantErr:   errTransient,
+		},
+		{
+			Name:      "It does not retry permanent errors",
+			Errors:    []error{retry.Permanent(errFatal), nil},
+			WantCalls: 1,
+			WantErr:   errFatal,
+		},
+	}
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			policy := retry.Policy{
+				Attempts:   3,
+				Backoff:    time.Millisecond,
+				MaxBackoff: 2 * time.Millisecond,
+			}
+
+			var calls int
+			err := policy.Do(context.Background(), logger, "test", func(context.Context) error {
+				err := test.Errors[calls]
+				calls++
+				return err
+			})
+			if test.WantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, test.WantErr) {
+				t.Errorf("unexpected error: got %v, want %v", err, test.WantErr)
+			}
+			if calls != test.WantCalls {
+				t.Errorf("unexpected number of calls: got %d, want %d", calls, test.WantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type cdxDocument struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Scope      string        `json:"scope,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cycloneDX encodes the document as CycloneDX 1.4 JSON.
+func (d *Document) cycloneDX() ([]byte, error) {
+	serial, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: d.Created,
+			Tools: []cdxTool{
+				{Vendor: "uw-labs", Name: "deploy"},
+			},
+			Component: cdxComponent{
+				Type:    "container",
+				Name:    d.Repository,
+				Version: d.Digest,
+				PURL:    d.imagePURL(),
+			},
+		},
+		Components: []cdxComponent{},
+	}
+
+	main := cdxComponent{
+		BOMRef:  d.Main.PURL,
+		Type:    "application",
+		Name:    d.Main.Name,
+		Version: d.Main.Version,
+		PURL:    d.Main.PURL,
+	}
+	if d.GoVersion != "" {
+		main.Properties = []cdxProperty{{Name: "golang:version", Value: d.GoVersion}}
+	}
+	doc.Components = append(doc.Components, main)
+
+	for _, c := range d.Components {
+		comp := cdxComponent{
+			BOMRef:  c.PURL,
+			Type:    "library",
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		if c.Hash != "" {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "golang:hash", Value: c.Hash})
+		}
+		if !c.OS {
+			comp.Scope = "required"
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "golang:direct", Value: boolString(c.Direct)})
+		}
+		doc.Components = append(doc.Components, comp)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return "false"
+}
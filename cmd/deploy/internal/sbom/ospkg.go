@@ -0,0 +1,93 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Locations of the package databases of the supported base images.
+const (
+	apkDatabase  = "/lib/apk/db/installed"
+	dpkgDatabase = "/var/lib/dpkg/status"
+)
+
+// ReadFileFunc reads a file from an image. It returns an
+// error wrapping os.ErrNotExist if the file does not exist.
+type ReadFileFunc func(path string) ([]byte, error)
+
+// OSPackages lists the packages installed in an image with an Alpine
+// or Debian based distribution. It returns no packages if the package
+// database cannot be found, such as in scratch or distroless images.
+func OSPackages(readFile ReadFileFunc) ([]*Component, error) {
+	data, err := readFile(apkDatabase)
+	switch {
+	case err == nil:
+		return parseDatabase(data, "P", "V", "A", "apk", "alpine")
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("read apk database: %w", err)
+	}
+
+	data, err = readFile(dpkgDatabase)
+	switch {
+	case err == nil:
+		return parseDatabase(data, "Package", "Version", "Architecture", "deb", "debian")
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("read dpkg database: %w", err)
+	}
+
+	return nil, nil
+}
+
+// parseDatabase parses a package database made of blank line separated
+// stanzas of "key: value" (dpkg) or "key:value" (apk) lines.
+func parseDatabase(data []byte, nameKey, versionKey, archKey, purlType, namespace string) ([]*Component, error) {
+	var packages []*Component
+	fields := map[string]string{}
+
+	flush := func() {
+		if fields[nameKey] != "" {
+			purl := "pkg:" + purlType + "/" + namespace + "/" + url.PathEscape(fields[nameKey]) +
+				"@" + url.PathEscape(fields[versionKey])
+			if arch := fields[archKey]; arch != "" {
+				purl += "?arch=" + url.QueryEscape(arch)
+			}
+			packages = append(packages, &Component{
+				Name:    fields[nameKey],
+				Version: fields[versionKey],
+				PURL:    purl,
+				OS:      true,
+			})
+		}
+		fields = map[string]string{}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, " ") {
+			// Continuation of a multi-line dpkg field
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		fields[line[:i]] = strings.TrimSpace(line[i+1:])
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("parse %s database: %w", purlType, err)
+	}
+	flush()
+
+	return packages, nil
+}
@@ -0,0 +1,160 @@
+package sbom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+)
+
+// Formats supported by Document.Encode.
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// MediaType returns the media type of documents in the format.
+func MediaType(format string) string {
+	switch format {
+	case FormatSPDX:
+		return "text/spdx+json"
+	case FormatCycloneDX:
+		return "application/vnd.cyclonedx+json"
+	}
+
+	return ""
+}
+
+// Component is a piece of software contained in an image.
+type Component struct {
+	Name    string
+	Version string
+	// PURL is the package URL of the component.
+	PURL string
+	// Hash is the Go module hash of the component, if known.
+	Hash string
+	// Direct is true for Go modules required directly by the main module.
+	Direct bool
+	// OS is true for packages installed in the base image.
+	OS bool
+}
+
+// Document is a software bill of materials for an image.
+type Document struct {
+	// Name is the name of the service in the image.
+	Name       string
+	Repository string
+	Digest     string
+	Created    time.Time
+	// Main describes the main module the binary was built from.
+	Main       Component
+	GoVersion  string
+	Components []*Component
+}
+
+// Request is the input to New.
+type Request struct {
+	Name       string
+	Repository string
+	Digest     string
+	BuildInfo  *binary.BuildInfo
+	// Vendor lists the modules in vendor/modules.txt. It is used to
+	// identify direct dependencies, and to describe binaries
+	// without dependency build information.
+	Vendor []*VendorModule
+	// Packages are the OS packages installed in the base image.
+	Packages []*Component
+}
+
+// New creates a bill of materials describing the modules
+// and OS packages in the image.
+func New(req *Request) *Document {
+	doc := &Document{
+		Name:       req.Name,
+		Repository: req.Repository,
+		Digest:     req.Digest,
+		Created:    time.Now().UTC(),
+		GoVersion:  req.BuildInfo.GoVersion,
+		Main:       *goComponent(&req.BuildInfo.Main),
+	}
+
+	explicit := map[string]bool{}
+	for _, vm := range req.Vendor {
+		explicit[vm.Path] = vm.Explicit
+	}
+
+	seen := map[string]bool{}
+	for _, dep := range req.BuildInfo.Deps {
+		c := goComponent(dep)
+		c.Direct = explicit[dep.Path]
+		seen[dep.Path] = true
+		doc.Components = append(doc.Components, c)
+	}
+
+	if len(req.BuildInfo.Deps) == 0 {
+		// Without dependency build information, fall back to the vendored
+		// modules that provide packages.
+		for _, vm := range req.Vendor {
+			if seen[vm.Path] || len(vm.Packages) == 0 {
+				continue
+			}
+			c := goComponent(&binary.Module{
+				Path:    vm.Path,
+				Version: vm.Version,
+			})
+			c.Direct = vm.Explicit
+			doc.Components = append(doc.Components, c)
+		}
+	}
+
+	sort.Slice(doc.Components, func(i, j int) bool {
+		return doc.Components[i].PURL < doc.Components[j].PURL
+	})
+
+	doc.Components = append(doc.Components, req.Packages...)
+
+	return doc
+}
+
+// Encode encodes the document in the format.
+func (d *Document) Encode(format string) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return d.spdx()
+	case FormatCycloneDX:
+		return d.cycloneDX()
+	}
+
+	return nil, fmt.Errorf("unsupported SBOM format %q", format)
+}
+
+func goComponent(m *binary.Module) *Component {
+	if m.Replace != nil {
+		m = m.Replace
+	}
+
+	version := m.Version
+	if version == "(devel)" {
+		version = ""
+	}
+
+	purl := "pkg:golang/" + escapePath(m.Path)
+	if version != "" {
+		purl += "@" + version
+	}
+
+	return &Component{
+		Name:    m.Path,
+		Version: version,
+		PURL:    purl,
+		Hash:    m.Sum,
+	}
+}
+
+// escapePath escapes the characters of a module path that
+// are not allowed in the namespace and name of a package URL.
+func escapePath(path string) string {
+	return strings.NewReplacer("@", "%40", "?", "%3F", "#", "%23").Replace(path)
+}
@@ -0,0 +1,151 @@
+package sbom_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/sbom"
+)
+
+const modulesTxt = `# github.com/sirupsen/logrus v1.6.0
+## explicit
+github.com/sirupsen/logrus
+# golang.org/x/sys v0.0.0-20200519105757-fe76b779f299
+golang.org/x/sys/unix
+# github.com/unused/module v1.0.0
+# example.com/old v1.0.0 => example.com/new v1.1.0
+example.com/old
+`
+
+const apkInstalled = `C:Q1...
+P:musl
+V:1.1.24-r2
+A:x86_64
+
+P:ca-certificates
+V:20191127-r2
+A:x86_64
+`
+
+func TestNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sbom")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "modules.txt")
+	err = ioutil.WriteFile(path, []byte(modulesTxt), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vendor, err := sbom.ReadVendorModules(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pkgs, err := sbom.OSPackages(func(path string) ([]byte, error) {
+		if path == "/lib/apk/db/installed" {
+			return []byte(apkInstalled), nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, os.ErrNotExist)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		Name      string
+		BuildInfo *binary.BuildInfo
+		Want      []string
+	}{
+		{
+			Name: "It lists modules from the build info",
+			BuildInfo: &binary.BuildInfo{
+				GoVersion: "go1.14.3",
+				Path:      "github.com/uw-labs/go-mono/cmd/user-api",
+				Main:      binary.Module{Path: "github.com/uw-labs/go-mono", Version: "(devel)"},
+				Deps: []*binary.Module{
+					{Path: "golang.org/x/sys", Version: "v0.0.0-20200519105757-fe76b779f299"},
+					{Path: "github.com/sirupsen/logrus", Version: "v1.6.0"},
+				},
+			},
+			Want: []string{
+				"pkg:golang/github.com/sirupsen/logrus@v1.6.0 direct",
+				"pkg:golang/golang.org/x/sys@v0.0.0-20200519105757-fe76b779f299 indirect",
+				"pkg:apk/alpine/musl@1.1.24-r2?arch=x86_64 os",
+				"pkg:apk/alpine/ca-certificates@20191127-r2?arch=x86_64 os",
+			},
+		},
+		{
+			Name: "It falls back to the vendored modules",
+			BuildInfo: &binary.BuildInfo{
+				GoVersion: "go1.14.3",
+				Path:      "github.com/uw-labs/go-mono/cmd/user-api",
+				Main:      binary.Module{Path: "github.com/uw-labs/go-mono", Version: "(devel)"},
+			},
+			Want: []string{
+				"pkg:golang/example.com/old@v1.1.0 indirect",
+				"pkg:golang/github.com/sirupsen/logrus@v1.6.0 direct",
+				"pkg:golang/golang.org/x/sys@v0.0.0-20200519105757-fe76b779f299 indirect",
+				"pkg:apk/alpine/musl@1.1.24-r2?arch=x86_64 os",
+				"pkg:apk/alpine/ca-certificates@20191127-r2?arch=x86_64 os",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			doc := sbom.New(&sbom.Request{
+				Name:       "user-api",
+				Repository: "docker.pkg.github.com/uw-labs/go-mono/user-api",
+				Digest:     "sha256:0123456789",
+				BuildInfo:  test.BuildInfo,
+				Vendor:     vendor,
+				Packages:   pkgs,
+			})
+
+			var got []string
+			for _, c := range doc.Components {
+				kind := "indirect"
+				switch {
+				case c.OS:
+					kind = "os"
+				case c.Direct:
+					kind = "direct"
+				}
+				got = append(got, c.PURL+" "+kind)
+			}
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Errorf("unexpected components (-want +got):\n%s", diff)
+			}
+
+			for _, format := range []string{sbom.FormatSPDX, sbom.FormatCycloneDX} {
+				data, err := doc.Encode(format)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !json.Valid(data) {
+					t.Errorf("expected valid %s JSON", format)
+				}
+			}
+		})
+	}
+
+	_, err = sbom.OSPackages(func(path string) ([]byte, error) {
+		return nil, errors.New("docker unavailable")
+	})
+	if err == nil {
+		t.Error("expected an error when the package database cannot be read")
+	}
+}
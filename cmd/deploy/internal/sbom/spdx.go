@@ -0,0 +1,134 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  time.Time `json:"created"`
+	Creators []string  `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdx encodes the document as SPDX 2.2 JSON.
+func (d *Document) spdx() ([]byte, error) {
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Repository + "@" + d.Digest,
+		DocumentNamespace: "https://" + d.Repository + "/spdx/" + strings.TrimPrefix(d.Digest, "sha256:") + "-" + id,
+		CreationInfo: spdxCreationInfo{
+			Created:  d.Created,
+			Creators: []string{"Organization: uw-labs", "Tool: deploy"},
+		},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"},
+			{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Main"},
+		},
+	}
+
+	doc.Packages = append(doc.Packages,
+		spdxPkg("SPDXRef-Image", d.Repository, d.Digest, d.imagePURL(), ""),
+		spdxPkg("SPDXRef-Main", d.Main.Name, d.Main.Version, d.Main.PURL, "Built with "+d.GoVersion),
+	)
+
+	for i, c := range d.Components {
+		ref := "SPDXRef-Package-" + strconv.Itoa(i+1)
+		doc.Packages = append(doc.Packages, spdxPkg(ref, c.Name, c.Version, c.PURL, ""))
+
+		rel := spdxRelationship{SPDXElementID: "SPDXRef-Main", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ref}
+		if c.OS {
+			rel = spdxRelationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: ref}
+		}
+		doc.Relationships = append(doc.Relationships, rel)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func spdxPkg(id, name, version, purl, comment string) spdxPackage {
+	pkg := spdxPackage{
+		Name:             name,
+		SPDXID:           id,
+		VersionInfo:      version,
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+		Comment:          comment,
+	}
+	if purl != "" {
+		pkg.ExternalRefs = []spdxExternalRef{
+			{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl},
+		}
+	}
+
+	return pkg
+}
+
+// imagePURL returns the package URL of the image.
+func (d *Document) imagePURL() string {
+	name := d.Repository[strings.LastIndex(d.Repository, "/")+1:]
+	return "pkg:oci/" + name + "@" + url.PathEscape(d.Digest) +
+		"?repository_url=" + url.QueryEscape(d.Repository)
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", fmt.Errorf("generate UUID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,75 @@
+package sbom
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VendorModule is a module listed in vendor/modules.txt.
+type VendorModule struct {
+	Path    string
+	Version string
+	// Explicit is true for modules required directly in go.mod.
+	Explicit bool
+	// Packages lists the vendored packages of the module.
+	Packages []string
+}
+
+// ReadVendorModules parses the vendor/modules.txt file at the path.
+func ReadVendorModules(path string) (_ []*VendorModule, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open vendored modules: %w", err)
+	}
+	defer func() {
+		cErr := f.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+
+	var (
+		modules []*VendorModule
+		current *VendorModule
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if current != nil && strings.Contains(line, "explicit") {
+				current.Explicit = true
+			}
+		case strings.HasPrefix(line, "# "):
+			// # path version
+			// # path version => replacement version
+			fields := strings.Fields(strings.TrimPrefix(line, "# "))
+			current = &VendorModule{Path: fields[0]}
+			if i := indexOf(fields, "=>"); i >= 0 && len(fields) > i+2 {
+				current.Version = fields[i+2]
+			} else if len(fields) > 1 && fields[1] != "=>" {
+				current.Version = fields[1]
+			}
+			modules = append(modules, current)
+		case line != "" && current != nil:
+			current.Packages = append(current.Packages, line)
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return nil, fmt.Errorf("read vendored modules: %w", err)
+	}
+
+	return modules, nil
+}
+
+func indexOf(fields []string, s string) int {
+	for i, f := range fields {
+		if f == s {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,109 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedKey is the format of the encrypted private keys
+// created by `cosign generate-key-pair`.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadKey reads an ECDSA private key from the PEM file at the path.
+// Unencrypted SEC 1 and PKCS #8 keys are supported, as are encrypted
+// keys generated by cosign, which are decrypted with the password.
+func LoadKey(path, password string) (*ecdsa.PrivateKey, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	der := block.Bytes
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("parse signing key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+	case "ENCRYPTED COSIGN PRIVATE KEY", "ENCRYPTED SIGSTORE PRIVATE KEY":
+		der, err = decrypt(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %q", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key must be an ECDSA key, got %T", key)
+	}
+
+	return ecKey, nil
+}
+
+func decrypt(data []byte, password string) ([]byte, error) {
+	var ek encryptedKey
+	err := json.Unmarshal(data, &ek)
+	if err != nil {
+		return nil, fmt.Errorf("parse encrypted signing key: %w", err)
+	}
+
+	if ek.KDF.Name != "scrypt" || ek.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported signing key encryption %s/%s", ek.KDF.Name, ek.Cipher.Name)
+	}
+
+	secret, err := scrypt.Key([]byte(password), ek.KDF.Salt, ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive signing key secret: %w", err)
+	}
+
+	var (
+		key   [32]byte
+		nonce [24]byte
+	)
+	copy(key[:], secret)
+	copy(nonce[:], ek.Cipher.Nonce)
+
+	der, ok := secretbox.Open(nil, ek.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, errors.New("decrypt signing key: incorrect password")
+	}
+
+	return der, nil
+}
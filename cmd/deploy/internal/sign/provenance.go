@@ -0,0 +1,111 @@
+package sign
+
+import (
+	"strings"
+	"time"
+)
+
+// Types of the in-toto statements and SLSA provenance predicates.
+const (
+	StatementType       = "https://in-toto.io/Statement/v0.1"
+	ProvenancePredicate = "https://slsa.dev/provenance/v0.2"
+	BuildType           = "https://github.com/uw-labs/go-mono/cmd/deploy@v1"
+)
+
+// Statement is an in-toto attestation statement.
+type Statement struct {
+	Type          string      `json:"_type"`
+	PredicateType string      `json:"predicateType"`
+	Subject       []Subject   `json:"subject"`
+	Predicate     *Provenance `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Provenance is a SLSA v0.2 provenance predicate.
+type Provenance struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource struct {
+			URI        string            `json:"uri,omitempty"`
+			Digest     map[string]string `json:"digest,omitempty"`
+			EntryPoint string            `json:"entryPoint,omitempty"`
+		} `json:"configSource"`
+	} `json:"invocation"`
+	BuildConfig map[string]string `json:"buildConfig,omitempty"`
+	Metadata    struct {
+		BuildStartedOn  time.Time `json:"buildStartedOn"`
+		BuildFinishedOn time.Time `json:"buildFinishedOn"`
+		Reproducible    bool      `json:"reproducible"`
+	} `json:"metadata"`
+	Materials []Material `json:"materials,omitempty"`
+}
+
+// Material is an input to the build.
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// ProvenanceRequest is the input to NewProvenance.
+type ProvenanceRequest struct {
+	// Repository and Digest identify the published image.
+	Repository string
+	Digest     string
+	// BuilderID identifies the system that built the image, such as a CI job URL.
+	BuilderID string
+	// GitRemote is the URL of the source repository, if known.
+	GitRemote  string
+	GitSHA     string
+	DeployFile string
+	// DeployConfig is the contents of the deploy file.
+	DeployConfig string
+	GoVersion    string
+	Started      time.Time
+	Finished     time.Time
+}
+
+// NewProvenance creates a statement recording the provenance of the image.
+func NewProvenance(req *ProvenanceRequest) *Statement {
+	p := &Provenance{
+		BuildType: BuildType,
+		BuildConfig: map[string]string{
+			"deployFile": req.DeployFile,
+			"deployYAML": req.DeployConfig,
+			"goVersion":  req.GoVersion,
+		},
+	}
+	if req.GitRemote != "" {
+		p.Invocation.ConfigSource.URI = "git+" + req.GitRemote
+		p.Materials = []Material{
+			{
+				URI:    "git+" + req.GitRemote,
+				Digest: map[string]string{"sha1": req.GitSHA},
+			},
+		}
+	}
+	p.Builder.ID = req.BuilderID
+	p.Invocation.ConfigSource.Digest = map[string]string{"sha1": req.GitSHA}
+	p.Invocation.ConfigSource.EntryPoint = req.DeployFile
+	p.Metadata.BuildStartedOn = req.Started.UTC()
+	p.Metadata.BuildFinishedOn = req.Finished.UTC()
+
+	return &Statement{
+		Type:          StatementType,
+		PredicateType: ProvenancePredicate,
+		Subject: []Subject{
+			{
+				Name:   req.Repository,
+				Digest: map[string]string{"sha256": strings.TrimPrefix(req.Digest, "sha256:")},
+			},
+		},
+		Predicate: p,
+	}
+}
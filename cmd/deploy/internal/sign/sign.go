@@ -0,0 +1,181 @@
+package sign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+)
+
+// Media types and annotations used by cosign to store
+// signatures and attestations in a registry.
+const (
+	mediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	mediaTypeDSSE          = "application/vnd.dsse.envelope.v1+json"
+	annotationSignature    = "dev.cosignproject.cosign/signature"
+	annotationPredicate    = "predicateType"
+	payloadTypeInToto      = "application/vnd.in-toto+json"
+)
+
+// Signer signs images and attaches the signatures and attestations
+// to them in the registry, in a format that can be verified with
+// `cosign verify` and `cosign verify-attestation`.
+type Signer struct {
+	key      *ecdsa.PrivateKey
+	registry *registry.Client
+}
+
+// NewSigner creates a signer using the key, storing signatures in
+// the registries accessed by the client.
+func NewSigner(key *ecdsa.PrivateKey, client *registry.Client) *Signer {
+	return &Signer{
+		key:      key,
+		registry: client,
+	}
+}
+
+// simpleSigning is the payload signed by cosign for an image.
+type simpleSigning struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// Sign signs the image with the digest in the repository and stores the
+// signature alongside it. The annotations are included in the signed payload.
+// It returns the reference of the signature artifact.
+func (s *Signer) Sign(ctx context.Context, repository, digest string, annotations map[string]string) (string, error) {
+	repo, err := registry.ParseRepository(repository)
+	if err != nil {
+		return "", err
+	}
+
+	var payload simpleSigning
+	payload.Critical.Identity.DockerReference = repository
+	payload.Critical.Image.DockerManifestDigest = digest
+	payload.Critical.Type = "cosign container image signature"
+	payload.Optional = annotations
+
+	body, err := json.Marshal(&payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal signature payload: %w", err)
+	}
+
+	sig, err := s.sign(body)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := s.registry.Attach(ctx, repo, digest, &registry.Attachment{
+		Kind:      "sig",
+		MediaType: mediaTypeSimpleSigning,
+		Content:   body,
+		Annotations: map[string]string{
+			annotationSignature: base64.StdEncoding.EncodeToString(sig),
+		},
+		Append: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("store signature: %w", err)
+	}
+
+	return ref, nil
+}
+
+// envelope is a DSSE envelope, as used to sign in-toto statements.
+type envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []envelopeSignature `json:"signatures"`
+}
+
+type envelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Attest signs the in-toto statement and attaches it to the image with
+// the digest in the repository. It returns the reference of the attestation.
+func (s *Signer) Attest(ctx context.Context, repository, digest string, statement *Statement) (string, error) {
+	repo, err := registry.ParseRepository(repository)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return "", fmt.Errorf("marshal attestation statement: %w", err)
+	}
+
+	sig, err := s.sign(pae(payloadTypeInToto, payload))
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(&envelope{
+		PayloadType: payloadTypeInToto,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []envelopeSignature{
+			{Sig: base64.StdEncoding.EncodeToString(sig)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal attestation envelope: %w", err)
+	}
+
+	ref, err := s.registry.Attach(ctx, repo, digest, &registry.Attachment{
+		Kind:      "att",
+		MediaType: mediaTypeDSSE,
+		Content:   body,
+		Annotations: map[string]string{
+			annotationPredicate: statement.PredicateType,
+		},
+		Append: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("store attestation: %w", err)
+	}
+
+	return ref, nil
+}
+
+func (s *Signer) sign(payload []byte) ([]byte, error) {
+	sum := sha256.Sum256(payload)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign payload: %w", err)
+	}
+
+	sig, err := asn1.Marshal(struct {
+		R, S *big.Int
+	}{r, ss})
+	if err != nil {
+		return nil, fmt.Errorf("encode signature: %w", err)
+	}
+
+	return sig, nil
+}
+
+// pae implements the DSSE pre-authentication encoding.
+func pae(payloadType string, payload []byte) []byte {
+	out := "DSSEv1 " +
+		strconv.Itoa(len(payloadType)) + " " + payloadType + " " +
+		strconv.Itoa(len(payload)) + " "
+
+	return append([]byte(out), payload...)
+}
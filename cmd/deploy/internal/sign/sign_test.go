@@ -0,0 +1,267 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSign(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Signer{key: key}
+
+	payload := []byte(`{"critical":{}}`)
+	sig, err := s.sign(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rs struct {
+		R, S *big.Int
+	}
+	_, err = asn1.Unmarshal(sig, &rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	if !ecdsa.Verify(&key.PublicKey, sum[:], rs.R, rs.S) {
+		t.Error("expected the signature to verify with the public key")
+	}
+	other := sha256.Sum256([]byte(`{"critical":{"tampered":true}}`))
+	if ecdsa.Verify(&key.PublicKey, other[:], rs.R, rs.S) {
+		t.Error("expected the signature not to verify a different payload")
+	}
+}
+
+func TestPAE(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		payloadType string
+		payload     string
+		want        string
+	}{
+		{
+			name:        "It encodes the lengths of the type and payload",
+			payloadType: payloadTypeInToto,
+			payload:     `{"_type":"x"}`,
+			want:        `DSSEv1 28 application/vnd.in-toto+json 13 {"_type":"x"}`,
+		},
+		{
+			name:        "It encodes an empty payload",
+			payloadType: "http://example.com/HelloWorld",
+			payload:     "",
+			want:        "DSSEv1 29 http://example.com/HelloWorld 0 ",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := string(pae(test.payloadType, []byte(test.payload)))
+			if got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	raw, err := ioutil.ReadFile(filepath.Join("testdata", "cosign.pub"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	block, _ := pem.Decode(raw)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cosignPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		t.Fatalf("expected an ECDSA public key, got %T", pub)
+	}
+
+	dir, err := ioutil.TempDir("", "sign")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ecPath := filepath.Join(dir, "ec.key")
+	err = ioutil.WriteFile(ecPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		path     string
+		password string
+		wantPub  *ecdsa.PublicKey
+		wantErr  string
+	}{
+		{
+			name:     "It decrypts a cosign key",
+			path:     filepath.Join("testdata", "cosign.key"),
+			password: "password",
+			wantPub:  cosignPub,
+		},
+		{
+			name:     "It rejects the wrong password",
+			path:     filepath.Join("testdata", "cosign.key"),
+			password: "wrong",
+			wantErr:  "decrypt signing key: incorrect password",
+		},
+		{
+			name:    "It loads an unencrypted key",
+			path:    ecPath,
+			wantPub: &ecKey.PublicKey,
+		},
+		{
+			name:    "It rejects a public key",
+			path:    filepath.Join("testdata", "cosign.pub"),
+			wantErr: `unsupported signing key type "PUBLIC KEY"`,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			key, err := LoadKey(test.path, test.password)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key.X.Cmp(test.wantPub.X) != 0 || key.Y.Cmp(test.wantPub.Y) != 0 {
+				t.Error("expected the key to match the public key")
+			}
+		})
+	}
+}
+
+func TestNewProvenance(t *testing.T) {
+	started := time.Date(2020, 6, 1, 12, 0, 0, 0, time.FixedZone("BST", 3600))
+	req := &ProvenanceRequest{
+		Repository:   "ghcr.io/uw-labs/user-api",
+		Digest:       "sha256:0123456789",
+		BuilderID:    "https://ci.example.com/jobs/1",
+		GitSHA:       "abcdef",
+		DeployFile:   "cmd/user-api/deploy.yml",
+		DeployConfig: "name: user-api\n",
+		GoVersion:    "go1.14",
+		Started:      started,
+		Finished:     started.Add(time.Minute),
+	}
+
+	for _, test := range []struct {
+		name      string
+		gitRemote string
+		want      string
+	}{
+		{
+			name: "It records the build",
+			want: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v0.2",
+				"subject": [{"name": "ghcr.io/uw-labs/user-api", "digest": {"sha256": "0123456789"}}],
+				"predicate": {
+					"builder": {"id": "https://ci.example.com/jobs/1"},
+					"buildType": "https://github.com/uw-labs/go-mono/cmd/deploy@v1",
+					"invocation": {
+						"configSource": {"digest": {"sha1": "abcdef"}, "entryPoint": "cmd/user-api/deploy.yml"}
+					},
+					"buildConfig": {
+						"deployFile": "cmd/user-api/deploy.yml",
+						"deployYAML": "name: user-api\n",
+						"goVersion": "go1.14"
+					},
+					"metadata": {
+						"buildStartedOn": "2020-06-01T11:00:00Z",
+						"buildFinishedOn": "2020-06-01T11:01:00Z",
+						"reproducible": false
+					}
+				}
+			}`,
+		},
+		{
+			name:      "It records the source repository",
+			gitRemote: "https://github.com/uw-labs/go-mono",
+			want: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v0.2",
+				"subject": [{"name": "ghcr.io/uw-labs/user-api", "digest": {"sha256": "0123456789"}}],
+				"predicate": {
+					"builder": {"id": "https://ci.example.com/jobs/1"},
+					"buildType": "https://github.com/uw-labs/go-mono/cmd/deploy@v1",
+					"invocation": {
+						"configSource": {
+							"uri": "git+https://github.com/uw-labs/go-mono",
+							"digest": {"sha1": "abcdef"},
+							"entryPoint": "cmd/user-api/deploy.yml"
+						}
+					},
+					"buildConfig": {
+						"deployFile": "cmd/user-api/deploy.yml",
+						"deployYAML": "name: user-api\n",
+						"goVersion": "go1.14"
+					},
+					"metadata": {
+						"buildStartedOn": "2020-06-01T11:00:00Z",
+						"buildFinishedOn": "2020-06-01T11:01:00Z",
+						"reproducible": false
+					},
+					"materials": [{"uri": "git+https://github.com/uw-labs/go-mono", "digest": {"sha1": "abcdef"}}]
+				}
+			}`,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := *req
+			r.GitRemote = test.gitRemote
+			body, err := json.Marshal(NewProvenance(&r))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got, want interface{}
+			err = json.Unmarshal(body, &got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = json.NewDecoder(strings.NewReader(test.want)).Decode(&want)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unexpected provenance (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
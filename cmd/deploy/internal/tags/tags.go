@@ -0,0 +1,170 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"golang.org/x/mod/semver"
+)
+
+// MaxLength is the maximum length of a Docker tag.
+const MaxLength = 128
+
+// MaxBranchLength is the length branch names are truncated to,
+// leaving room for a prefix or suffix in the tag template.
+const MaxBranchLength = 64
+
+// Default are the tag templates used when none are configured.
+// They tag images with the full git SHA, suffixed with -dirty for
+// builds of uncommitted changes, and the branch name.
+var Default = []string{
+	"{{.SHA}}{{if .Dirty}}-dirty{{end}}",
+	"{{.Branch}}",
+}
+
+var (
+	validTag   = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	invalidTag = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// Data is the data available to tag templates.
+type Data struct {
+	// Service is the name of the service being released.
+	Service string
+	// SHA and ShortSHA are the full and 7 character git commit SHAs.
+	SHA      string
+	ShortSHA string
+	// Branch is the branch name, sanitized for use in a tag.
+	// It is empty if the branch is unknown.
+	Branch string
+	// DefaultBranch is true when building the default branch.
+	DefaultBranch bool
+	// Version is the semantic version (without a "v" prefix) of the
+	// highest <service>/vX.Y.Z git tag pointing at the commit, if any.
+	// Major and Minor are its major and major.minor components.
+	Version string
+	Major   string
+	Minor   string
+	// BuildNumber is the build number of the CI system, if any.
+	BuildNumber string
+	// PullRequest is the number of the pull request being built, if any.
+	PullRequest string
+	// Dirty is true when building uncommitted changes.
+	Dirty bool
+}
+
+// Request is the input to NewData.
+type Request struct {
+	Service       string
+	SHA           string
+	Branch        string
+	DefaultBranch string
+	// GitTags are the git tags pointing at the commit.
+	GitTags     []string
+	BuildNumber string
+	PullRequest string
+	Dirty       bool
+}
+
+// NewData creates the template data for the request.
+func NewData(req *Request) *Data {
+	d := &Data{
+		Service:       req.Service,
+		SHA:           req.SHA,
+		ShortSHA:      req.SHA,
+		Branch:        Sanitize(req.Branch, MaxBranchLength),
+		DefaultBranch: req.Branch != "" && req.Branch == req.DefaultBranch,
+		BuildNumber:   req.BuildNumber,
+		PullRequest:   req.PullRequest,
+		Dirty:         req.Dirty,
+	}
+	if len(d.ShortSHA) > 7 {
+		d.ShortSHA = d.ShortSHA[:7]
+	}
+
+	var version string
+	prefix := req.Service + "/"
+	for _, tag := range req.GitTags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		v := strings.TrimPrefix(tag, prefix)
+		if !semver.IsValid(v) {
+			continue
+		}
+		if version == "" || semver.Compare(v, version) > 0 {
+			version = v
+		}
+	}
+	if version != "" {
+		d.Version = strings.TrimPrefix(semver.Canonical(version), "v")
+		d.Major = strings.TrimPrefix(semver.Major(version), "v")
+		d.Minor = strings.TrimPrefix(semver.MajorMinor(version), "v")
+	}
+
+	return d
+}
+
+// Render renders the tag templates with the data. Templates that render
+// to an empty string are skipped, and duplicate tags are removed.
+// An error is returned if a rendered tag is not a valid Docker tag.
+func Render(templates []string, data *Data) ([]string, error) {
+	var (
+		tags []string
+		seen = map[string]bool{}
+	)
+	for _, text := range templates {
+		tmpl, err := template.New("tag").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse tag template %q: %w", text, err)
+		}
+
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, data)
+		if err != nil {
+			return nil, fmt.Errorf("render tag template %q: %w", text, err)
+		}
+
+		tag := strings.TrimSpace(buf.String())
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if err = Validate(tag); err != nil {
+			return nil, fmt.Errorf("render tag template %q: %w", text, err)
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("tag templates %q rendered no tags", templates)
+	}
+
+	return tags, nil
+}
+
+// Validate returns an error if the tag is not a valid Docker tag.
+func Validate(tag string) error {
+	if !validTag.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q: tags must be at most %d characters of letters, digits, "+
+			"underscores, periods and dashes, and must not start with a period or dash", tag, MaxLength)
+	}
+
+	return nil
+}
+
+// Sanitize replaces characters that are not allowed in
+// Docker tags with dashes, and truncates the result to max characters.
+func Sanitize(s string, max int) string {
+	s = invalidTag.ReplaceAllString(s, "-")
+	s = strings.TrimLeft(s, ".-")
+	if len(s) > max {
+		s = strings.TrimRight(s[:max], ".-")
+	}
+
+	return s
+}
@@ -0,0 +1,100 @@
+package tags_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/tags"
+)
+
+func TestRender(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+
+	tests := []struct {
+		Name      string
+		Templates []string
+		Request   *tags.Request
+		Want      []string
+		WantErr   string
+	}{
+		{
+			Name:      "It defaults to the SHA and sanitized branch",
+			Templates: tags.Default,
+			Request:   &tags.Request{SHA: sha, Branch: "feature/my:branch"},
+			Want:      []string{sha, "feature-my-branch"},
+		},
+		{
+			Name:      "It truncates long branch names",
+			Templates: []string{"branch-{{.Branch}}"},
+			Request:   &tags.Request{SHA: sha, Branch: strings.Repeat("a", 200)},
+			Want:      []string{"branch-" + strings.Repeat("a", tags.MaxBranchLength)},
+		},
+		{
+			Name:      "It tags latest on the default branch only",
+			Templates: []string{"{{.ShortSHA}}", "{{if .DefaultBranch}}latest{{end}}"},
+			Request:   &tags.Request{SHA: sha, Branch: "master", DefaultBranch: "master"},
+			Want:      []string{"0123456", "latest"},
+		},
+		{
+			Name:      "It skips empty tags",
+			Templates: []string{"{{.ShortSHA}}", "{{if .DefaultBranch}}latest{{end}}"},
+			Request:   &tags.Request{SHA: sha, Branch: "feature", DefaultBranch: "master"},
+			Want:      []string{"0123456"},
+		},
+		{
+			Name:      "It uses the highest semantic version tag of the service",
+			Templates: []string{"{{with .Version}}v{{.}}{{end}}", "{{.Minor}}", "{{.Major}}"},
+			Request: &tags.Request{
+				Service: "user-api",
+				SHA:     sha,
+				GitTags: []string{"user-api/v1.2.3", "user-api/v1.10.0", "other-api/v2.0.0", "user-api/latest"},
+			},
+			Want: []string{"v1.10.0", "1.10", "1"},
+		},
+		{
+			Name:      "It renders CI build numbers and pull requests",
+			Templates: []string{"build-{{.BuildNumber}}", "{{with .PullRequest}}pr-{{.}}{{end}}"},
+			Request:   &tags.Request{SHA: sha, BuildNumber: "42", PullRequest: "7"},
+			Want:      []string{"build-42", "pr-7"},
+		},
+		{
+			Name:      "It marks dirty builds and skips unknown branches",
+			Templates: tags.Default,
+			Request:   &tags.Request{SHA: sha, Dirty: true},
+			Want:      []string{sha + "-dirty"},
+		},
+		{
+			Name:      "It rejects invalid tags",
+			Templates: []string{"-{{.SHA}}"},
+			Request:   &tags.Request{SHA: sha},
+			WantErr:   "invalid tag",
+		},
+		{
+			Name:      "It rejects templates rendering no tags",
+			Templates: []string{"{{.Version}}"},
+			Request:   &tags.Request{SHA: sha},
+			WantErr:   "rendered no tags",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := tags.Render(test.Templates, tags.NewData(test.Request))
+			if test.WantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.WantErr) {
+					t.Fatalf("expected error containing %q, got %v", test.WantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Errorf("unexpected tags (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
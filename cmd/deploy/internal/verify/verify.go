@@ -0,0 +1,222 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+// defaultSmokeTimeout is the time a smoke run has to exit in, if not configured.
+const defaultSmokeTimeout = 10 * time.Second
+
+// Request is the input to Run.
+type Request struct {
+	RepoRoot string
+	// MainPaths are the main packages of the binaries in the image.
+	MainPaths []string
+	// BinaryPath is the path of the entrypoint binary, used by smoke runs.
+	BinaryPath string
+	Config     *deploy.Verify
+}
+
+// Gate is the outcome of a single verification gate.
+type Gate struct {
+	Name     string
+	Output   string
+	Duration time.Duration
+	Err      error
+}
+
+// Error is returned by Run when gates fail.
+type Error struct {
+	Failed []*Gate
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for _, g := range e.Failed {
+		names = append(names, g.Name)
+	}
+
+	return "verification failed: " + strings.Join(names, ", ")
+}
+
+// Run runs the verification gates configured for the deployment.
+// Every gate is run, even if an earlier one fails, and the outcome of
+// each is logged, including the output of failed gates. An *Error
+// listing the failed gates is returned if any fail.
+// The image size budget is enforced when building the image.
+func Run(ctx context.Context, logger logrus.FieldLogger, req *Request) error {
+	conf := req.Config
+	if !conf.Test && !conf.Vet && conf.Smoke == nil {
+		return nil
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("find go binary: %w", err)
+	}
+
+	var pkgs []string
+	if conf.Test || conf.Vet {
+		pkgs, err = localPackages(ctx, goBin, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	var gates []*Gate
+	if conf.Vet {
+		gates = append(gates, runGate(ctx, "go vet", req.RepoRoot, goBin, append([]string{"vet", "-mod=vendor"}, pkgs...)...))
+	}
+	if conf.Test {
+		gates = append(gates, testGate(ctx, req.RepoRoot, goBin, pkgs, conf.FailOnSkip))
+	}
+	if conf.Smoke != nil {
+		gates = append(gates, smoke(ctx, req.BinaryPath, conf.Smoke))
+	}
+
+	verr := &Error{}
+	for _, g := range gates {
+		log := logger.WithField("gate", g.Name).WithField("duration", g.Duration.Round(time.Millisecond))
+		if g.Err != nil {
+			log.WithError(g.Err).Errorf("Verification gate failed, output:\n%s", g.Output)
+			verr.Failed = append(verr.Failed, g)
+			continue
+		}
+		log.Infoln("Verification gate passed")
+	}
+	if len(verr.Failed) > 0 {
+		return verr
+	}
+
+	return nil
+}
+
+// localPackages lists the packages of the main module
+// that the main packages depend on, including themselves.
+func localPackages(ctx context.Context, goBin string, req *Request) ([]string, error) {
+	args := []string{
+		"list",
+		"-mod=vendor",
+		"-deps",
+		"-f", "{{if .Module}}{{if .Module.Main}}{{.ImportPath}}{{end}}{{end}}",
+	}
+	for _, main := range req.MainPaths {
+		args = append(args, "./"+filepath.ToSlash(main))
+	}
+
+	cmd := exec.CommandContext(ctx, goBin, args...)
+	cmd.Dir = req.RepoRoot
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("list dependencies: %w: %s", err, stderr.String())
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+func runGate(ctx context.Context, name, dir, bin string, args ...string) *Gate {
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+
+	out, err := cmd.CombinedOutput()
+
+	return &Gate{
+		Name:     name,
+		Output:   string(out),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+}
+
+// testEvent is an event printed by go test -json.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// testGate runs the tests of the packages. If failOnSkip is set, skipped
+// tests fail the gate, as tests that cannot run, such as those needing
+// Docker on an agent without it, would otherwise pass it without testing anything.
+func testGate(ctx context.Context, dir, goBin string, pkgs []string, failOnSkip bool) *Gate {
+	g := runGate(ctx, "go test", dir, goBin, append([]string{"test", "-mod=vendor", "-json"}, pkgs...)...)
+
+	var (
+		output  strings.Builder
+		skipped []string
+	)
+	for _, line := range strings.Split(g.Output, "\n") {
+		var e testEvent
+		err := json.Unmarshal([]byte(line), &e)
+		if err != nil {
+			// Such as build errors, which are not printed as events.
+			if line != "" {
+				output.WriteString(line + "\n")
+			}
+			continue
+		}
+
+		switch {
+		case e.Action == "output":
+			output.WriteString(e.Output)
+		case e.Action == "skip" && e.Test != "":
+			skipped = append(skipped, e.Package+"."+e.Test)
+		}
+	}
+
+	g.Output = output.String()
+	if g.Err == nil && failOnSkip && len(skipped) > 0 {
+		g.Err = fmt.Errorf("tests skipped: %s", strings.Join(skipped, ", "))
+	}
+
+	return g
+}
+
+// smoke runs the binary and checks that it exits with the
+// expected exit code before the timeout.
+func smoke(ctx context.Context, binPath string, conf *deploy.Smoke) *Gate {
+	timeout := conf.Timeout
+	if timeout == 0 {
+		timeout = defaultSmokeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	g := runGate(ctx, "smoke run", filepath.Dir(binPath), binPath, conf.Args...)
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		g.Err = fmt.Errorf("binary did not exit within %s", timeout)
+	case errors.As(g.Err, &exitErr):
+		if exitErr.ExitCode() == conf.ExitCode {
+			g.Err = nil
+		} else {
+			g.Err = fmt.Errorf("binary exited with code %d, expected %d", exitErr.ExitCode(), conf.ExitCode)
+		}
+	case g.Err == nil && conf.ExitCode != 0:
+		g.Err = fmt.Errorf("binary exited with code 0, expected %d", conf.ExitCode)
+	}
+
+	return g
+}
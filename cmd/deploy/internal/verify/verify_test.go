@@ -0,0 +1,177 @@
+package verify_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/verify"
+)
+
+const mainGo = `package main
+
+import "example.com/app/internal/lib"
+
+func main() {
+	println(lib.Answer())
+}
+`
+
+const libGo = `package lib
+
+func Answer() int {
+	return 42
+}
+`
+
+func TestRun(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	for _, test := range []struct {
+		name       string
+		libTest    string
+		config     *deploy.Verify
+		wantFailed []string
+		wantErr    string
+	}{
+		{
+			name: "It passes when the tests pass",
+			libTest: `package lib
+
+import "testing"
+
+func TestAnswer(t *testing.T) {
+	if Answer() != 42 {
+		t.Fatal("wrong answer")
+	}
+}
+`,
+			config: &deploy.Verify{Test: true, Vet: true},
+		},
+		{
+			name: "It fails when a test fails",
+			libTest: `package lib
+
+import "testing"
+
+func TestAnswer(t *testing.T) {
+	t.Fatal("wrong answer")
+}
+`,
+			config:     &deploy.Verify{Test: true, Vet: true},
+			wantFailed: []string{"go test"},
+			wantErr:    "verification failed: go test",
+		},
+		{
+			name: "It passes when a test is skipped",
+			libTest: `package lib
+
+import "testing"
+
+func TestAnswer(t *testing.T) {
+	t.Skip("database container could not be started")
+}
+`,
+			config: &deploy.Verify{Test: true},
+		},
+		{
+			name: "It fails when a test is skipped if configured",
+			libTest: `package lib
+
+import "testing"
+
+func TestAnswer(t *testing.T) {
+	t.Skip("database container could not be started")
+}
+`,
+			config:     &deploy.Verify{Test: true, FailOnSkip: true},
+			wantFailed: []string{"go test"},
+			wantErr:    "verification failed: go test",
+		},
+		{
+			name: "It runs every gate when one fails",
+			libTest: `package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAnswer(t *testing.T) {
+	fmt.Printf("%d\n", "not a number")
+}
+`,
+			config:     &deploy.Verify{Test: true, Vet: true},
+			wantFailed: []string{"go vet", "go test"},
+			wantErr:    "verification failed: go vet, go test",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "verify")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			// The unused package does not compile, but is not a dependency
+			// of the main package, so is not verified.
+			for path, content := range map[string]string{
+				"go.mod":                      "module example.com/app\n\ngo 1.14\n",
+				"cmd/app/main.go":             mainGo,
+				"internal/lib/lib.go":         libGo,
+				"internal/lib/lib_test.go":    test.libTest,
+				"internal/unused/unused.go":   "package unused\n",
+				"internal/unused/bad_test.go": "package unused\n\nfunc TestBroken(",
+			} {
+				path = filepath.Join(dir, path)
+				err = os.MkdirAll(filepath.Dir(path), 0o755)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				err = ioutil.WriteFile(path, []byte(content), 0o600)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			err = verify.Run(context.Background(), logger, &verify.Request{
+				RepoRoot:  dir,
+				MainPaths: []string{"cmd/app"},
+				Config:    test.config,
+			})
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.wantErr {
+				t.Fatalf("expected error %q, got %v", test.wantErr, err)
+			}
+
+			var verr *verify.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a *verify.Error, got %T", err)
+			}
+			var failed []string
+			for _, g := range verr.Failed {
+				failed = append(failed, g.Name)
+				if g.Output == "" {
+					t.Errorf("expected the output of the %s gate", g.Name)
+				}
+			}
+			if diff := cmp.Diff(test.wantFailed, failed); diff != "" {
+				t.Errorf("unexpected failed gates (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package vuln
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// cvss3Weights are the weights of the CVSS v3 base metric values.
+// See https://www.first.org/cvss/v3.1/specification-document.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3Score calculates the base score of a CVSS v3 vector,
+// such as CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H.
+func cvss3Score(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, fmt.Errorf("unsupported CVSS vector %q", vector)
+	}
+
+	metrics := map[string]string{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return 0, fmt.Errorf("invalid CVSS vector %q", vector)
+		}
+		metrics[kv[0]] = kv[1]
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, fmt.Errorf("invalid scope in CVSS vector %q", vector)
+	}
+
+	w := map[string]float64{}
+	for metric, values := range cvss3Weights {
+		weight, ok := values[metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("invalid %s in CVSS vector %q", metric, vector)
+		}
+		w[metric] = weight
+	}
+	// Privileges matter less when the scope changes.
+	if scope == "C" {
+		switch metrics["PR"] {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if scope == "C" {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if scope == "C" {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp rounds up to one decimal place, as defined by CVSS v3.1.
+func roundUp(f float64) float64 {
+	i := math.Round(f * 100000)
+	if math.Mod(i, 10000) == 0 {
+		return i / 100000
+	}
+
+	return (math.Floor(i/10000) + 1) / 10
+}
+
+// scoreSeverity returns the qualitative severity of the CVSS score.
+func scoreSeverity(score float64) Severity {
+	switch {
+	case score >= 9:
+		return Critical
+	case score >= 7:
+		return High
+	case score >= 4:
+		return Moderate
+	case score > 0:
+		return Low
+	default:
+		return Unknown
+	}
+}
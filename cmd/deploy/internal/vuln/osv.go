@@ -0,0 +1,183 @@
+package vuln
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ecosystemGo is the OSV ecosystem of Go modules.
+const ecosystemGo = "Go"
+
+// stdlib is the package name OSV databases use for the Go standard library.
+const stdlib = "stdlib"
+
+// entry is a vulnerability in the OSV format.
+// See https://ossf.github.io/osv-schema/.
+type entry struct {
+	ID               string           `json:"id"`
+	Aliases          []string         `json:"aliases"`
+	Summary          string           `json:"summary"`
+	Details          string           `json:"details"`
+	Withdrawn        string           `json:"withdrawn"`
+	Severity         []osvSeverity    `json:"severity"`
+	Affected         []affected       `json:"affected"`
+	DatabaseSpecific databaseSpecific `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges           []affectedRange  `json:"ranges"`
+	Versions         []string         `json:"versions"`
+	DatabaseSpecific databaseSpecific `json:"database_specific"`
+}
+
+type affectedRange struct {
+	Type   string  `json:"type"`
+	Events []event `json:"events"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// databaseSpecific holds the severity recorded by databases such as
+// the GitHub Advisory Database.
+type databaseSpecific struct {
+	Severity string `json:"severity"`
+}
+
+// severity returns the severity of the entry, preferring CVSS scores
+// over the severity recorded by the database.
+func (e *entry) severity(a *affected) Severity {
+	sev := Unknown
+	for _, s := range e.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		score, err := cvss3Score(s.Score)
+		if err != nil {
+			continue
+		}
+		if scored := scoreSeverity(score); scored > sev {
+			sev = scored
+		}
+	}
+	if sev != Unknown {
+		return sev
+	}
+
+	for _, s := range []string{a.DatabaseSpecific.Severity, e.DatabaseSpecific.Severity} {
+		parsed, err := ParseSeverity(s)
+		if err == nil && parsed != Unknown {
+			return parsed
+		}
+	}
+
+	return Unknown
+}
+
+// affects reports whether the version of the package is affected,
+// returning the version the vulnerability is fixed in, if known.
+func (a *affected) affects(version string) (fixed string, ok bool) {
+	for _, v := range a.Versions {
+		if canonical(v) == version {
+			return "", true
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if fixed, ok := r.affects(version); ok {
+			return fixed, true
+		}
+	}
+
+	return "", false
+}
+
+// affects evaluates the events of the range in version order,
+// as described in the OSV schema.
+func (r *affectedRange) affects(version string) (fixed string, ok bool) {
+	events := make([]event, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return compare(events[i].version(), events[j].version()) < 0
+	})
+
+	var affected bool
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if compare(version, e.Introduced) >= 0 {
+				affected = true
+				fixed = ""
+			}
+		case e.Fixed != "":
+			if compare(version, e.Fixed) >= 0 {
+				affected = false
+			} else if affected && fixed == "" {
+				fixed = e.Fixed
+			}
+		case e.LastAffected != "":
+			if compare(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			if compare(version, e.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+
+	return fixed, affected
+}
+
+func (e event) version() string {
+	for _, v := range []string{e.Introduced, e.Fixed, e.LastAffected, e.Limit} {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// compare compares the versions as semantic versions.
+// The introduced version "0" is lower than every other version.
+func compare(v, w string) int {
+	switch {
+	case v == w:
+		return 0
+	case v == "0":
+		return -1
+	case w == "0":
+		return 1
+	}
+
+	return semver.Compare(canonical(v), canonical(w))
+}
+
+// canonical adds the v prefix Go module versions have,
+// but OSV versions do not.
+func canonical(v string) string {
+	if v == "" || v == "0" || strings.HasPrefix(v, "v") {
+		return v
+	}
+
+	return "v" + v
+}
@@ -0,0 +1,302 @@
+package vuln
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+// Severity is the qualitative severity of a vulnerability.
+type Severity int
+
+// The severities, in increasing order.
+const (
+	Unknown Severity = iota
+	Low
+	Moderate
+	High
+	Critical
+)
+
+var severityNames = []string{"unknown", "low", "moderate", "high", "critical"}
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// ParseSeverity parses a severity, ignoring case.
+// Medium is accepted as an alias of moderate.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return Unknown, nil
+	case "medium":
+		return Moderate, nil
+	}
+	for i, name := range severityNames {
+		if s == name {
+			return Severity(i), nil
+		}
+	}
+
+	return Unknown, fmt.Errorf("unknown severity %q", s)
+}
+
+// Database is a vulnerability database in the OSV format,
+// indexed by the affected Go module.
+type Database struct {
+	entries map[string][]*entry
+	size    int
+}
+
+// Load loads the database from an OSV JSON file, or a directory of them,
+// such as an extracted export of https://osv.dev or the Go vulnerability
+// database. Files may hold a single entry or an array of entries.
+// Withdrawn entries and entries for other ecosystems are ignored.
+func Load(path string) (*Database, error) {
+	db := &Database{
+		entries: map[string][]*entry{},
+	}
+
+	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		return db.loadFile(path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load vulnerability database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *Database) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var entries []*entry
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		var e entry
+		err = json.Unmarshal(data, &e)
+		entries = []*entry{&e}
+	}
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for _, e := range entries {
+		if e.ID == "" || e.Withdrawn != "" {
+			continue
+		}
+		db.size++
+
+		seen := map[string]bool{}
+		for _, a := range e.Affected {
+			name := a.Package.Name
+			if a.Package.Ecosystem != ecosystemGo || seen[name] {
+				continue
+			}
+			seen[name] = true
+			db.entries[name] = append(db.entries[name], e)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of vulnerabilities in the database.
+func (db *Database) Len() int {
+	return db.size
+}
+
+// Finding is a vulnerability affecting a module of a binary.
+type Finding struct {
+	ID      string
+	Aliases []string
+	Summary string
+	Module  string
+	Version string
+	// Fixed is the version the vulnerability is fixed in, if known.
+	Fixed    string
+	Severity Severity
+}
+
+// String describes the finding in a single line.
+func (f *Finding) String() string {
+	s := f.ID
+	if len(f.Aliases) > 0 {
+		s += " (" + strings.Join(f.Aliases, ", ") + ")"
+	}
+	s += fmt.Sprintf(" in %s@%s, severity %s", f.Module, f.Version, f.Severity)
+	if f.Fixed != "" {
+		s += ", fixed in " + canonical(f.Fixed)
+	}
+	if f.Summary != "" {
+		s += ": " + f.Summary
+	}
+
+	return s
+}
+
+// Request is the input to Check.
+type Request struct {
+	// BuildInfo lists the modules built into the image.
+	BuildInfo *binary.BuildInfo
+	Config    *deploy.Vulnerabilities
+}
+
+// Error is returned by Check when vulnerabilities block the release.
+type Error struct {
+	Blocking []*Finding
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	ids := make([]string, 0, len(e.Blocking))
+	for _, f := range e.Blocking {
+		ids = append(ids, f.ID)
+	}
+
+	return "vulnerable modules: " + strings.Join(ids, ", ")
+}
+
+// Check matches the modules of the build info, and the standard library of
+// the Go version it was built with, against the database. Every finding is
+// logged and returned, with an *Error listing the findings at or above the
+// configured severity if there are any. Ignored vulnerabilities are logged,
+// but never block.
+func (db *Database) Check(logger logrus.FieldLogger, req *Request) ([]*Finding, error) {
+	conf := req.Config
+	threshold, blockUnknown := Critical, conf.BlockUnknown
+	if conf.Severity == "none" {
+		threshold, blockUnknown = Critical+1, false
+	} else if conf.Severity != "" {
+		var err error
+		threshold, err = ParseSeverity(conf.Severity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ignored := map[string]bool{}
+	for _, id := range conf.Ignore {
+		ignored[id] = true
+	}
+
+	findings := db.find(modules(req.BuildInfo))
+
+	verr := &Error{}
+	for _, f := range findings {
+		log := logger.WithField("vulnerability", f.ID)
+		switch {
+		case f.ignored(ignored):
+			log.Infof("Ignoring vulnerability %s", f)
+		case f.Severity >= threshold || f.Severity == Unknown && blockUnknown:
+			log.Errorf("Vulnerability %s", f)
+			verr.Blocking = append(verr.Blocking, f)
+		default:
+			log.Warnf("Vulnerability %s", f)
+		}
+	}
+	if len(verr.Blocking) > 0 {
+		return findings, verr
+	}
+
+	return findings, nil
+}
+
+func (f *Finding) ignored(ignored map[string]bool) bool {
+	if ignored[f.ID] {
+		return true
+	}
+	for _, alias := range f.Aliases {
+		if ignored[alias] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// find returns the findings for the modules, most severe first.
+func (db *Database) find(mods []*binary.Module) []*Finding {
+	var findings []*Finding
+	for _, mod := range mods {
+		for _, e := range db.entries[mod.Path] {
+			for i := range e.Affected {
+				a := &e.Affected[i]
+				if a.Package.Ecosystem != ecosystemGo || a.Package.Name != mod.Path {
+					continue
+				}
+				fixed, ok := a.affects(mod.Version)
+				if !ok {
+					continue
+				}
+				findings = append(findings, &Finding{
+					ID:       e.ID,
+					Aliases:  e.Aliases,
+					Summary:  e.Summary,
+					Module:   mod.Path,
+					Version:  mod.Version,
+					Fixed:    fixed,
+					Severity: e.severity(a),
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].ID < findings[j].ID
+	})
+
+	return findings
+}
+
+// modules returns the modules built into the binary, using replacements
+// where they have a version, and the standard library.
+func modules(bi *binary.BuildInfo) []*binary.Module {
+	mods := make([]*binary.Module, 0, len(bi.Deps)+1)
+	if v := "v" + strings.TrimPrefix(bi.GoVersion, "go"); semver.IsValid(v) {
+		mods = append(mods, &binary.Module{Path: stdlib, Version: v})
+	}
+
+	for _, dep := range bi.Deps {
+		mod := dep
+		if dep.Replace != nil {
+			// Local replacements have no version to check.
+			if dep.Replace.Version == "" {
+				continue
+			}
+			mod = dep.Replace
+		}
+		mods = append(mods, mod)
+	}
+
+	return mods
+}
@@ -0,0 +1,177 @@
+package vuln_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/vuln"
+)
+
+const entries = `[
+  {
+    "id": "GHSA-yaml",
+    "aliases": ["CVE-2019-11254"],
+    "summary": "Excessive CPU usage",
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}],
+    "affected": [{
+      "package": {"ecosystem": "Go", "name": "gopkg.in/yaml.v2"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "2.2.8"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-crypto",
+    "summary": "Authentication bypass",
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+    "affected": [{
+      "package": {"ecosystem": "Go", "name": "golang.org/x/crypto"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0.0.0-20200101000000-000000000000"}, {"fixed": "0.0.0-20201216223049-8b5274cf687f"}]}]
+    }]
+  },
+  {
+    "id": "GO-stdlib",
+    "summary": "Request smuggling",
+    "affected": [{
+      "package": {"ecosystem": "Go", "name": "stdlib"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.14.0"}, {"fixed": "1.14.12"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-withdrawn",
+    "withdrawn": "2021-01-01T00:00:00Z",
+    "database_specific": {"severity": "CRITICAL"},
+    "affected": [{
+      "package": {"ecosystem": "Go", "name": "gopkg.in/yaml.v2"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+    }]
+  },
+  {
+    "id": "PYSEC-other",
+    "database_specific": {"severity": "CRITICAL"},
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "gopkg.in/yaml.v2"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+    }]
+  }
+]`
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vuln")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "osv.json"), []byte(entries), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	db, err := vuln.Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bi := &binary.BuildInfo{
+		GoVersion: "go1.14.2",
+		Deps: []*binary.Module{
+			{Path: "gopkg.in/yaml.v2", Version: "v2.2.2"},
+			{Path: "golang.org/x/crypto", Version: "v0.0.0-20200622213623-75b288015ac9"},
+			{Path: "github.com/sirupsen/logrus", Version: "v1.6.0"},
+		},
+	}
+
+	yaml := &vuln.Finding{
+		ID:       "GHSA-yaml",
+		Aliases:  []string{"CVE-2019-11254"},
+		Summary:  "Excessive CPU usage",
+		Module:   "gopkg.in/yaml.v2",
+		Version:  "v2.2.2",
+		Fixed:    "2.2.8",
+		Severity: vuln.High,
+	}
+	crypto := &vuln.Finding{
+		ID:       "GHSA-crypto",
+		Summary:  "Authentication bypass",
+		Module:   "golang.org/x/crypto",
+		Version:  "v0.0.0-20200622213623-75b288015ac9",
+		Fixed:    "0.0.0-20201216223049-8b5274cf687f",
+		Severity: vuln.Critical,
+	}
+	stdlib := &vuln.Finding{
+		ID:       "GO-stdlib",
+		Summary:  "Request smuggling",
+		Module:   "stdlib",
+		Version:  "v1.14.2",
+		Fixed:    "1.14.12",
+		Severity: vuln.Unknown,
+	}
+	all := []*vuln.Finding{crypto, yaml, stdlib}
+
+	tests := []struct {
+		Name         string
+		Config       *deploy.Vulnerabilities
+		WantBlocking []*vuln.Finding
+	}{
+		{
+			Name:         "It blocks critical vulnerabilities by default",
+			Config:       &deploy.Vulnerabilities{},
+			WantBlocking: []*vuln.Finding{crypto},
+		},
+		{
+			Name:         "It blocks vulnerabilities at or above the severity",
+			Config:       &deploy.Vulnerabilities{Severity: "high"},
+			WantBlocking: []*vuln.Finding{crypto, yaml},
+		},
+		{
+			Name:         "It blocks vulnerabilities without a severity if configured",
+			Config:       &deploy.Vulnerabilities{BlockUnknown: true},
+			WantBlocking: []*vuln.Finding{crypto, stdlib},
+		},
+		{
+			Name:   "It does not block ignored vulnerabilities",
+			Config: &deploy.Vulnerabilities{Severity: "high", Ignore: []string{"GHSA-crypto", "CVE-2019-11254"}},
+		},
+		{
+			Name:   "It reports every vulnerability without blocking for none",
+			Config: &deploy.Vulnerabilities{Severity: "none", BlockUnknown: true},
+		},
+	}
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			findings, err := db.Check(logger, &vuln.Request{
+				BuildInfo: bi,
+				Config:    test.Config,
+			})
+			if diff := cmp.Diff(all, findings); diff != "" {
+				t.Errorf("unexpected findings (-want +got):\n%s", diff)
+			}
+
+			var verr *vuln.Error
+			if test.WantBlocking == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a vulnerability error, got %v", err)
+			}
+			if diff := cmp.Diff(test.WantBlocking, verr.Blocking); diff != "" {
+				t.Errorf("unexpected blocking findings (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
+)
+
+// The events webhooks are notified of.
+const (
+	EventPublished = "published"
+	EventFailed    = "failed"
+)
+
+// The headers sent with every notification.
+const (
+	// HeaderEvent holds the event of the payload.
+	HeaderEvent = "X-Deploy-Event"
+	// HeaderDelivery holds a random identifier of the notification,
+	// which is the same for every attempt at delivering it.
+	HeaderDelivery = "X-Deploy-Delivery"
+	// HeaderSignature holds the hex encoded HMAC-SHA256 of the
+	// body, keyed with the secret of the hook, as sha256=<hmac>.
+	HeaderSignature = "X-Deploy-Signature"
+)
+
+// Payload is the JSON body posted to webhooks.
+type Payload struct {
+	Event   string `json:"event"`
+	Service string `json:"service"`
+	// Repository, Digest, Image and Tags describe the image
+	// pushed to the first target, if it was published.
+	Repository string   `json:"repository,omitempty"`
+	Digest     string   `json:"digest,omitempty"`
+	Image      string   `json:"image,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Images are the images published to every target.
+	Images        []*Image  `json:"images,omitempty"`
+	GitSHA        string    `json:"gitSHA"`
+	GitBranch     string    `json:"gitBranch"`
+	Author        string    `json:"author"`
+	CommitMessage string    `json:"commitMessage"`
+	BuildURL      string    `json:"buildURL,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Image is an image published to a target.
+type Image struct {
+	Target string   `json:"target"`
+	Image  string   `json:"image"`
+	Tags   []string `json:"tags"`
+}
+
+// Hook is a URL notified of releases.
+type Hook struct {
+	// URL is the URL to post to. Environment variables are expanded
+	// when notifying the hook, which is skipped if the URL expands to nothing.
+	URL string
+	// Secret is the key the payload is signed with, if set.
+	Secret string
+	// Events are the events the hook is notified of. Defaults to every event.
+	Events []string
+}
+
+// wants returns true if the hook should be notified of the event.
+func (h *Hook) wants(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeout limits each attempt at delivering a notification.
+const timeout = 30 * time.Second
+
+// Client posts payloads to webhooks.
+type Client struct {
+	client *http.Client
+	retry  retry.Policy
+}
+
+// NewClient returns a client retrying failed deliveries with the policy.
+func NewClient(policy retry.Policy) *Client {
+	return &Client{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		retry: policy,
+	}
+}
+
+// Notify posts the payload to every hook that wants its event. Failures
+// are retried, unless the hook responds with a client error, and logged.
+// It returns the number of hooks that could not be notified.
+func (c *Client) Notify(ctx context.Context, logger logrus.FieldLogger, hooks []*Hook, payload *Payload) int {
+	var failed int
+	for _, h := range hooks {
+		if !h.wants(payload.Event) {
+			continue
+		}
+
+		expanded := *h
+		expanded.URL = os.ExpandEnv(h.URL)
+		if expanded.URL == "" {
+			logger.Warnf("Skipping webhook %s, whose URL is empty", h.URL)
+			continue
+		}
+
+		err := c.Send(ctx, logger, &expanded, payload)
+		if err != nil {
+			logger.WithError(err).Warnf("Notify webhook %s", redact(expanded.URL))
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// Send posts the payload to the hook, signed with its secret.
+func (c *Client) Send(ctx context.Context, logger logrus.FieldLogger, h *Hook, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	delivery, err := newDeliveryID()
+	if err != nil {
+		return err
+	}
+
+	return c.retry.Do(ctx, logger, "Notify webhook "+redact(h.URL), func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("create request: %w", err))
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "deploy")
+		req.Header.Set(HeaderEvent, payload.Event)
+		req.Header.Set(HeaderDelivery, delivery)
+		if h.Secret != "" {
+			req.Header.Set(HeaderSignature, Sign(h.Secret, body))
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		err = fmt.Errorf("unexpected status %d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), strings.TrimSpace(string(msg)))
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
+
+// Sign returns the value of the signature header for the body.
+// Receivers should compare it to the header with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", fmt.Errorf("generate delivery ID: %w", err)
+	}
+
+	return hex.EncodeToString(b[:]), nil
+}
+
+// redact returns the scheme and host of the URL, since chat
+// webhooks often embed their token in the path or query.
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,147 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/webhook"
+)
+
+func TestNotify(t *testing.T) {
+	payload := &webhook.Payload{
+		Event:         webhook.EventPublished,
+		Service:       "user-api",
+		Digest:        "sha256:0123",
+		Tags:          []string{"0123456", "master"},
+		GitSHA:        "0123456789",
+		Author:        "Jane Doe <jane@example.com>",
+		CommitMessage: "Add users",
+		Timestamp:     time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		Name string
+		// Statuses are the statuses the hook responds with, in order.
+		Statuses   []int
+		Hook       *webhook.Hook
+		WantCalls  int
+		WantFailed int
+	}{
+		{
+			Name:      "It posts the signed payload",
+			Statuses:  []int{http.StatusOK},
+			Hook:      &webhook.Hook{Secret: "secret"},
+			WantCalls: 1,
+		},
+		{
+			Name:      "It retries server errors",
+			Statuses:  []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusNoContent},
+			Hook:      &webhook.Hook{Secret: "secret"},
+			WantCalls: 3,
+		},
+		{
+			Name:       "It does not retry client errors",
+			Statuses:   []int{http.StatusNotFound, http.StatusOK},
+			Hook:       &webhook.Hook{},
+			WantCalls:  1,
+			WantFailed: 1,
+		},
+		{
+			Name:       "It fails when the attempts are exhausted",
+			Statuses:   []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			Hook:       &webhook.Hook{},
+			WantCalls:  3,
+			WantFailed: 1,
+		},
+		{
+			Name:      "It expands environment variables in the URL",
+			Statuses:  []int{http.StatusOK},
+			Hook:      &webhook.Hook{URL: "$WEBHOOK_TEST_URL"},
+			WantCalls: 1,
+		},
+		{
+			Name:     "It skips hooks whose URL is empty",
+			Statuses: []int{http.StatusOK},
+			Hook:     &webhook.Hook{URL: "$WEBHOOK_TEST_UNSET_URL"},
+		},
+		{
+			Name:     "It skips hooks that do not want the event",
+			Statuses: []int{http.StatusOK},
+			Hook:     &webhook.Hook{Events: []string{webhook.EventFailed}},
+		},
+	}
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			var calls int
+			deliveries := map[string]bool{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				if test.Hook.Secret != "" {
+					sig := webhook.Sign(test.Hook.Secret, body)
+					if !hmac.Equal([]byte(sig), []byte(r.Header.Get(webhook.HeaderSignature))) {
+						t.Errorf("unexpected signature %q", r.Header.Get(webhook.HeaderSignature))
+					}
+				}
+				if got := r.Header.Get(webhook.HeaderEvent); got != payload.Event {
+					t.Errorf("unexpected event %q", got)
+				}
+				deliveries[r.Header.Get(webhook.HeaderDelivery)] = true
+
+				var got webhook.Payload
+				err = json.Unmarshal(body, &got)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(payload, &got); diff != "" {
+					t.Errorf("unexpected payload (-want +got):\n%s", diff)
+				}
+
+				w.WriteHeader(test.Statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			if test.Hook.URL == "" {
+				test.Hook.URL = srv.URL
+			}
+			err := os.Setenv("WEBHOOK_TEST_URL", srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.Unsetenv("WEBHOOK_TEST_URL")
+
+			client := webhook.NewClient(retry.Policy{Attempts: 3, Backoff: time.Millisecond})
+			failed := client.Notify(context.Background(), logger, []*webhook.Hook{test.Hook}, payload)
+
+			if failed != test.WantFailed {
+				t.Errorf("unexpected number of failed hooks: got %d, want %d", failed, test.WantFailed)
+			}
+			if calls != test.WantCalls {
+				t.Errorf("unexpected number of calls: got %d, want %d", calls, test.WantCalls)
+			}
+			if calls > 0 && len(deliveries) != 1 {
+				t.Errorf("expected every attempt to use the same delivery ID, got %d", len(deliveries))
+			}
+		})
+	}
+}
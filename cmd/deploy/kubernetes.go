@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/kube"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/manifest"
+)
+
+// kubernetesOptions configures rendering Kubernetes manifests.
+type kubernetesOptions struct {
+	RepoRoot     string
+	ManifestFile string
+	Image        string
+	Target       string
+	Namespace    string
+	OutputDir    string
+	Kustomize    bool
+}
+
+// kubernetesMain runs the kubernetes subcommand, which renders Kubernetes
+// manifests for the runtime of the deploy files given as arguments.
+func kubernetesMain(logger *logrus.Logger, args []string) {
+	fs := flag.NewFlagSet("kubernetes", flag.ExitOnError)
+	repoRoot := fs.String("repo-root", ".", "The root of the repo, which the deploy files are relative to.")
+	manifestFile := fs.String("manifest-file", "", "The release manifest written by deploy, to read the digests of the published images from.")
+	image := fs.String("image", "", "The digest pinned reference of the image to deploy, instead of reading it from the manifest file. Requires a single deploy file.")
+	target := fs.String("target", "", "The name of the target to deploy the image of, if images were published to several targets. Defaults to the first.")
+	namespace := fs.String("namespace", "", "If set, the namespace of the rendered objects.")
+	outputDir := fs.String("output-dir", "", "If set, the directory to write the manifests of each service to, in a directory named after it. Otherwise they are written to stdout.")
+	kustomize := fs.Bool("kustomize", false, "Also write a kustomization.yaml listing the manifests of each service, to use them as kustomize bases. Requires an output directory.")
+	_ = fs.Parse(args)
+
+	opts := &kubernetesOptions{
+		RepoRoot:     *repoRoot,
+		ManifestFile: *manifestFile,
+		Image:        *image,
+		Target:       *target,
+		Namespace:    *namespace,
+		OutputDir:    *outputDir,
+		Kustomize:    *kustomize,
+	}
+	deployFiles := fs.Args()
+	switch {
+	case len(deployFiles) == 0:
+		logger.Fatal("at least one deploy file must be specified")
+	case opts.Image == "" && opts.ManifestFile == "":
+		logger.Fatal("image or manifest-file must be specified")
+	case opts.Image != "" && len(deployFiles) > 1:
+		logger.Fatal("image can only be specified with a single deploy file")
+	case opts.Kustomize && opts.OutputDir == "":
+		logger.Fatal("kustomize requires an output-dir")
+	}
+
+	err := renderKubernetes(os.Stdout, logger, opts, deployFiles)
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+}
+
+// renderKubernetes renders the manifests of the deploy files, writing them
+// to the output directory, or to w as a multi-document YAML stream.
+func renderKubernetes(w io.Writer, logger logrus.FieldLogger, opts *kubernetesOptions, deployFiles []string) error {
+	var releases []*manifest.Release
+	if opts.ManifestFile != "" {
+		m, err := manifest.Read(opts.ManifestFile)
+		if err != nil {
+			return err
+		}
+		releases = m.Releases
+	}
+
+	for _, file := range deployFiles {
+		conf, err := deploy.Parse(opts.RepoRoot, file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if conf.Runtime == nil {
+			logger.Warnf("Skipping %s, which has no runtime", file)
+			continue
+		}
+
+		req := &kube.Request{
+			Deployment: conf,
+			Image:      opts.Image,
+			Namespace:  opts.Namespace,
+			Kustomize:  opts.Kustomize,
+		}
+		if req.Image == "" {
+			rel := findRelease(releases, conf.Name, opts.Target)
+			if rel == nil {
+				return fmt.Errorf("%s: no release of %s in %s", file, conf.Name, opts.ManifestFile)
+			}
+			req.Image = rel.Image
+			req.GitSHA = rel.GitSHA
+		}
+
+		files, err := kube.Render(req)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		if opts.OutputDir == "" {
+			for _, f := range files {
+				_, err = fmt.Fprintf(w, "---\n# Source: %s/%s\n%s", conf.Name, f.Name, f.Content)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		dir := filepath.Join(opts.OutputDir, conf.Name)
+		err = os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+		for _, f := range files {
+			err = ioutil.WriteFile(filepath.Join(dir, f.Name), f.Content, 0o644)
+			if err != nil {
+				return fmt.Errorf("write %s: %w", f.Name, err)
+			}
+		}
+		logger.Infof("Wrote the manifests of %s to %s", conf.Name, dir)
+	}
+
+	return nil
+}
+
+// findRelease returns the release of the service to the target,
+// or to the first target if none is given.
+func findRelease(releases []*manifest.Release, service, target string) *manifest.Release {
+	for _, rel := range releases {
+		if rel.Service == service && (target == "" || rel.Target == target) {
+			return rel
+		}
+	}
+
+	return nil
+}
@@ -1,20 +1,31 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
-	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
 	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker"
 	"github.com/uw-labs/go-mono/cmd/deploy/internal/git"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/sbom"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/sign"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/tags"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/vuln"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/webhook"
 	pkgcontext "github.com/uw-labs/go-mono/pkg/context"
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
 )
 
 var (
@@ -23,74 +34,387 @@ var (
 	dockerPassword = flag.String("docker-password", "", "The password to use when authenticating the user against the registry.")
 	dockerRegistry = flag.String("docker-registry", "docker.pkg.github.com/uw-labs/go-mono", "The registry to push images to. Can include any subpaths.")
 	deployFile     = flag.String("deploy-file", "", "The deploy file to read deployment configuration from.")
+	buildFile      = flag.String("build-file", "", "A file listing deploy files to release, one per line, such as the output of calculate-releases.")
+	concurrency    = flag.Int("concurrency", 4, "The maximum number of deployments to release at the same time.")
+	manifestFile   = flag.String("manifest-file", "", "If set, the path to write a JSON manifest of the published releases to.")
+	releasesFile   = flag.String("releases-file", "", "If set, the path of a repository-level releases file to append the published releases to.")
+	gitopsDir      = flag.String("gitops-dir", "", "If set, the path to a local manifests checkout in which to pin references to published images to their digests.")
+	gitopsName     = flag.String("gitops-author-name", "deploy", "The author name to use when committing manifest changes.")
+	gitopsEmail    = flag.String("gitops-author-email", "deploy@localhost", "The author email to use when committing manifest changes.")
+	gitopsPush     = flag.Bool("gitops-push", false, "Push the manifest changes to the origin remote after committing them.")
+	gitopsUser     = flag.String("gitops-user", "", "The user to use when pushing manifest changes over HTTPS.")
+	gitopsPassword = flag.String("gitops-password", "", "The password or token to use when pushing manifest changes over HTTPS.")
+	signingKey     = flag.String("signing-key", "", "If set, the path to a PEM encoded ECDSA private key (such as a cosign.key) to sign published images with. The password of encrypted keys is read from COSIGN_PASSWORD.")
+	sbomFormat     = flag.String("sbom-format", "", `If set, the format of a software bill of materials to attach to published images, either "spdx" or "cyclonedx". SBOMs are also written next to the manifest file, if set.`)
+	defaultBranch  = flag.String("default-branch", "master", "The default branch of the repository, on which the DefaultBranch tag template field is true.")
+	builderID      = flag.String("builder-id", "", "The identifier of the build system recorded in provenance attestations. Defaults to the build URL of the CI system.")
+	webhookSecret  = flag.String("webhook-secret", "", "If set, the secret to sign the payloads posted to the webhooks passed with --webhook with.")
+	vulnDB         = flag.String("vuln-db", "", "If set, the path of a vulnerability database in the OSV format, either a JSON file or a directory of them, to check the modules built into images against.")
+	allowDirty     = flag.Bool("allow-dirty", false, "Allow releasing from a working tree with uncommitted changes. Such releases are marked as dirty.")
+
+	buildTimeout     = flag.Duration("build-timeout", 10*time.Minute, "The time building the binaries of a deployment may take. Zero disables the timeout.")
+	verifyTimeout    = flag.Duration("verify-timeout", 30*time.Minute, "The time the verification gates of a deployment may take. Zero disables the timeout.")
+	imageTimeout     = flag.Duration("image-timeout", 15*time.Minute, "The time pulling the base images and building a Docker image may take. Zero disables the timeout.")
+	pushTimeout      = flag.Duration("push-timeout", 10*time.Minute, "The time each attempt to push an image or copy it to a target may take. Zero disables the timeout.")
+	retries          = flag.Int("retries", 3, "The number of attempts at pulling base images, pushing images and copying them to targets.")
+	retryBackoff     = flag.Duration("retry-backoff", 5*time.Second, "The delay before retrying a failed attempt, doubled on every retry up to one minute.")
+	progressInterval = flag.Duration("progress-interval", 10*time.Second, "The interval between progress lines while pulling and pushing images.")
+)
+
+var (
+	tagTemplates stringsFlag
+	webhookURLs  stringsFlag
 )
 
+func init() {
+	flag.Var(&tagTemplates, "tag", "A template for a tag to push images with. Can be repeated. "+
+		"Overridden by the tags in a deploy file. Defaults to the git SHA and branch.")
+	flag.Var(&webhookURLs, "webhook", "A URL to post a JSON payload to when a release is published or fails. Can be repeated. "+
+		"Deploy files can configure further webhooks.")
+}
+
 func main() {
+	logger, err := pkglog.New(pkglog.ConfigFromEnv())
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "promote":
+			promoteMain(logger, os.Args[2:])
+			return
+		case "validate":
+			validateMain(logger, os.Args[2:])
+			return
+		case "kubernetes":
+			kubernetesMain(logger, os.Args[2:])
+			return
+		case "dev":
+			devMain(logger, os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 
-	logger := logrus.New()
-	logger.Formatter = &logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: time.StampMilli,
+	deployFiles, err := collectDeployFiles(*deployFile, *buildFile, flag.Args())
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+
+	if len(deployFiles) == 0 {
+		logger.Fatal("deploy-file, build-file or a list of deploy files must be specified")
+	}
+
+	if *concurrency < 1 {
+		logger.Fatal("concurrency must be at least 1")
 	}
 
-	if *deployFile == "" {
-		logger.Fatal("deploy-file must be specified")
+	if *retries < 1 {
+		logger.Fatal("retries must be at least 1")
 	}
 
-	err := run(logger, *repoRoot, *dockerUser, *dockerPassword, *dockerRegistry, *deployFile)
+	switch *sbomFormat {
+	case "", sbom.FormatSPDX, sbom.FormatCycloneDX:
+	default:
+		logger.Fatalf("unsupported sbom-format %q", *sbomFormat)
+	}
+
+	err = run(logger, &options{
+		RepoRoot:       *repoRoot,
+		DockerUser:     *dockerUser,
+		DockerPassword: *dockerPassword,
+		DockerRegistry: *dockerRegistry,
+		Concurrency:    *concurrency,
+		ManifestFile:   *manifestFile,
+		ReleasesFile:   *releasesFile,
+		SigningKey:     *signingKey,
+		BuilderID:      *builderID,
+		SBOMFormat:     *sbomFormat,
+		VulnDB:         *vulnDB,
+		Tags:           tagTemplates.valuesOr(tags.Default),
+		DefaultBranch:  *defaultBranch,
+		AllowDirty:     *allowDirty,
+		Timeouts: timeoutOptions{
+			Build:  *buildTimeout,
+			Verify: *verifyTimeout,
+			Image:  *imageTimeout,
+			Push:   *pushTimeout,
+		},
+		Retry: retry.Policy{
+			Attempts:   *retries,
+			Backoff:    *retryBackoff,
+			MaxBackoff: time.Minute,
+		},
+		ProgressInterval: *progressInterval,
+		Webhooks:         webhookHooks(webhookURLs, *webhookSecret),
+		GitOps: gitopsOptions{
+			Dir:         *gitopsDir,
+			AuthorName:  *gitopsName,
+			AuthorEmail: *gitopsEmail,
+			Push:        *gitopsPush,
+			User:        *gitopsUser,
+			Password:    *gitopsPassword,
+		},
+	}, deployFiles)
 	if err != nil {
 		logger.WithError(err).Fatal()
 	}
 }
 
-func run(logger *logrus.Logger, repoRoot, dockerUser, dockerPassword, dockerRegistry, deployFile string) error {
+// options holds the configuration shared by every release in a run.
+type options struct {
+	RepoRoot         string
+	DockerUser       string
+	DockerPassword   string
+	DockerRegistry   string
+	Concurrency      int
+	ManifestFile     string
+	ReleasesFile     string
+	SigningKey       string
+	BuilderID        string
+	SBOMFormat       string
+	VulnDB           string
+	Tags             []string
+	DefaultBranch    string
+	AllowDirty       bool
+	Timeouts         timeoutOptions
+	Retry            retry.Policy
+	ProgressInterval time.Duration
+	// Webhooks are notified of every release.
+	Webhooks []*webhook.Hook
+	GitOps   gitopsOptions
+}
+
+// timeoutOptions limits the time the stages of a release may take.
+// Zero values disable the timeouts.
+type timeoutOptions struct {
+	Build  time.Duration
+	Verify time.Duration
+	Image  time.Duration
+	// Push limits each attempt to push or copy an image.
+	Push time.Duration
+}
+
+// gitopsOptions configures updating a manifests checkout after publishing.
+type gitopsOptions struct {
+	Dir         string
+	AuthorName  string
+	AuthorEmail string
+	Push        bool
+	User        string
+	Password    string
+}
+
+// stringsFlag is a flag that can be repeated to build a list of values.
+type stringsFlag []string
+
+// String implements flag.Value.
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set implements flag.Value.
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func (s stringsFlag) valuesOr(def []string) []string {
+	if len(s) == 0 {
+		return def
+	}
+
+	return s
+}
+
+// webhookHooks returns the webhooks passed on the command line.
+func webhookHooks(urls []string, secret string) []*webhook.Hook {
+	hooks := make([]*webhook.Hook, 0, len(urls))
+	for _, u := range urls {
+		hooks = append(hooks, &webhook.Hook{
+			URL:    u,
+			Secret: secret,
+		})
+	}
+
+	return hooks
+}
+
+// collectDeployFiles merges the deploy files given via flags, the build file
+// and positional arguments, dropping blank lines and duplicates, including
+// those written differently, such as with a leading ./.
+func collectDeployFiles(deployFile, buildFile string, args []string) (_ []string, err error) {
+	candidates := append([]string{deployFile}, args...)
+
+	if buildFile != "" {
+		f, err := os.Open(buildFile)
+		if err != nil {
+			return nil, fmt.Errorf("open build file: %w", err)
+		}
+		defer func() {
+			cErr := f.Close()
+			if err == nil {
+				err = cErr
+			}
+		}()
+
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			candidates = append(candidates, sc.Text())
+		}
+		if err = sc.Err(); err != nil {
+			return nil, fmt.Errorf("read build file: %w", err)
+		}
+	}
+
+	set := map[string]struct{}{}
+	var files []string // nolint: prealloc
+	for _, file := range candidates {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		file = filepath.Clean(file)
+		if _, ok := set[file]; ok {
+			continue
+		}
+		set[file] = struct{}{}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func run(logger *logrus.Logger, opts *options, deployFiles []string) (err error) {
 	ctx := pkgcontext.WithSignalHandler(context.Background())
 
-	md, err := git.GetMetadata(repoRoot)
+	ci := git.DetectCI(os.Getenv)
+	if ci != nil {
+		logger.Infof("Detected %s build", ci.Provider)
+	}
+
+	md, err := git.GetMetadata(opts.RepoRoot, ci)
 	if err != nil {
 		return fmt.Errorf("get git metadata: %w", err)
 	}
+	if md.GitBranch == "" {
+		logger.Warnln("Could not determine the branch being built")
+	}
+	if md.Dirty {
+		if !opts.AllowDirty {
+			return errors.New("the working tree has uncommitted changes, commit them or pass --allow-dirty")
+		}
+		logger.Warnln("Releasing uncommitted changes")
+	}
+	if opts.BuilderID == "" {
+		opts.BuilderID = md.BuildURL
+	}
 
-	conf, err := deploy.Parse(repoRoot, deployFile)
+	client, err := docker.NewClient()
 	if err != nil {
-		return fmt.Errorf("parse deployment: %w", err)
+		return err
 	}
+	defer func() {
+		cErr := client.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
 
-	logger.Infoln("Deploying", conf.Name)
+	r := &releaser{
+		logger:   logger,
+		opts:     opts,
+		md:       md,
+		docker:   client,
+		registry: registry.NewClient(opts.DockerUser, opts.DockerPassword),
+		webhooks: webhook.NewClient(opts.Retry),
+	}
 
-	logger.Infoln("Building binary")
-	binPath, err := binary.Build(ctx, logger, &binary.Request{
-		Name:     conf.Name,
-		RepoRoot: repoRoot,
-		MainPath: conf.Main,
-	})
-	if err != nil {
-		return fmt.Errorf("build binary: %w", err)
+	if opts.SBOMFormat != "" {
+		r.vendor, err = sbom.ReadVendorModules(filepath.Join(opts.RepoRoot, "vendor", "modules.txt"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
 	}
-	defer func() {
-		err := os.RemoveAll(filepath.Dir(binPath))
+
+	if opts.VulnDB != "" {
+		r.vulnDB, err = vuln.Load(opts.VulnDB)
 		if err != nil {
-			logger.WithError(err).Infof("remove binary directory (%s)", filepath.Dir(binPath))
+			return err
 		}
-	}()
+		logger.Infof("Loaded %d vulnerabilities from %s", r.vulnDB.Len(), opts.VulnDB)
+	}
 
-	logger.Infoln("Building Docker image")
-	digest, err := docker.BuildAndPushImage(ctx, logger, &docker.Request{
-		RepoRoot:         repoRoot,
-		BinaryPath:       binPath,
-		Registry:         dockerRegistry,
-		RegistryUser:     dockerUser,
-		RegistryPassword: dockerPassword,
-		GitSHA:           md.GitSHA,
-		Name:             conf.Name,
-		Tag:              md.GitBranch,
-	})
+	if opts.SigningKey != "" {
+		r.signingKey, err = sign.LoadKey(opts.SigningKey, os.Getenv("COSIGN_PASSWORD"))
+		if err != nil {
+			return err
+		}
+
+		r.goVersion, err = binary.GoVersion(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	results := releaseAll(ctx, opts.Concurrency, deployFiles, r.release)
+
+	err = printSummary(os.Stdout, results)
+	if err != nil {
+		return fmt.Errorf("print summary: %w", err)
+	}
+
+	err = writeManifest(opts, md, results)
 	if err != nil {
-		return fmt.Errorf("build Docker image: %w", err)
+		return err
 	}
 
-	logger.Infof("Published %s", digest)
+	err = updateManifests(ctx, logger, &opts.GitOps, md, results)
+	if err != nil {
+		return err
+	}
+
+	return releaseError(results)
+}
+
+// releaseAll releases the deploy files, at most concurrency at a time.
+// Every deploy file is released, even if others fail, and the results
+// are in the order of the deploy files.
+func releaseAll(ctx context.Context, concurrency int, deployFiles []string, release func(ctx context.Context, deployFile string) *result) []*result {
+	results := make([]*result, len(deployFiles))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(deployFiles); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j] = release(ctx, deployFiles[j])
+			}
+		}()
+	}
+	for i := range deployFiles {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// releaseError returns an error counting the failed releases, if any failed.
+func releaseError(results []*result) error {
+	var failed int
+	for _, res := range results {
+		if res.failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d releases failed", failed, len(results))
+	}
 
 	return nil
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker"
+)
+
+func TestCollectDeployFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deploy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A build file listing every deploy file of the repository, as
+	// written by calculate-releases, with blank lines and duplicates.
+	buildFile := filepath.Join(dir, "builds.txt")
+	err = ioutil.WriteFile(buildFile, []byte("cmd/user-api/deploy.yml\n\n  cmd/billing-api/deploy.yml  \n./cmd/user-api/deploy.yml\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		name       string
+		deployFile string
+		buildFile  string
+		args       []string
+		want       []string
+		wantErr    string
+	}{
+		{
+			name:       "It collects the deploy file and arguments",
+			deployFile: "cmd/user-api/deploy.yml",
+			args:       []string{"cmd/billing-api/deploy.yml", "cmd/stock-service/deploy.yml"},
+			want:       []string{"cmd/user-api/deploy.yml", "cmd/billing-api/deploy.yml", "cmd/stock-service/deploy.yml"},
+		},
+		{
+			name:      "It collects the deploy files of the build file",
+			buildFile: buildFile,
+			want:      []string{"cmd/user-api/deploy.yml", "cmd/billing-api/deploy.yml"},
+		},
+		{
+			name:       "It drops duplicates across the flags, build file and arguments",
+			deployFile: "./cmd/billing-api/deploy.yml",
+			buildFile:  buildFile,
+			args:       []string{"cmd/user-api/deploy.yml", "cmd/stock-service/deploy.yml", "cmd/stock-service//deploy.yml"},
+			want:       []string{"cmd/billing-api/deploy.yml", "cmd/user-api/deploy.yml", "cmd/stock-service/deploy.yml"},
+		},
+		{
+			name: "It collects nothing when nothing is given",
+		},
+		{
+			name:      "It fails when the build file does not exist",
+			buildFile: filepath.Join(dir, "missing.txt"),
+			wantErr:   "open build file: open " + filepath.Join(dir, "missing.txt") + ": no such file or directory",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := collectDeployFiles(test.deployFile, test.buildFile, test.args)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected deploy files (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReleaseAll(t *testing.T) {
+	deployFiles := []string{"a/deploy.yml", "b/deploy.yml", "c/deploy.yml", "d/deploy.yml", "e/deploy.yml"}
+	failing := map[string]bool{"a/deploy.yml": true, "d/deploy.yml": true}
+
+	var (
+		mu               sync.Mutex
+		running, maxSeen int
+	)
+	release := func(ctx context.Context, deployFile string) *result {
+		mu.Lock()
+		running++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		name := filepath.Dir(deployFile)
+		res := &result{DeployFile: deployFile, Name: name}
+		if failing[deployFile] {
+			res.Err = errors.New("build " + name + " failed")
+			return res
+		}
+		res.Image = &docker.Image{Repository: "registry.example.com/" + name, Digest: "sha256:" + name}
+		return res
+	}
+
+	results := releaseAll(context.Background(), 2, deployFiles, release)
+
+	var released []string
+	for _, res := range results {
+		released = append(released, res.DeployFile)
+	}
+	if diff := cmp.Diff(deployFiles, released); diff != "" {
+		t.Errorf("unexpected releases (-want +got):\n%s", diff)
+	}
+	if maxSeen > 2 {
+		t.Errorf("expected at most 2 concurrent releases, got %d", maxSeen)
+	}
+
+	err := releaseError(results)
+	if want := "2 of 5 releases failed"; err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+
+	var buf bytes.Buffer
+	err = printSummary(&buf, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	summary := buf.String()
+	for _, want := range []string{
+		"build a failed",
+		"build d failed",
+		"registry.example.com/b@sha256:b",
+		"registry.example.com/e@sha256:e",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected the summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/git"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/gitops"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/manifest"
+)
+
+// writeManifest records the published releases in the manifest
+// and releases files, if they have been configured.
+func writeManifest(opts *options, md *git.Metadata, results []*result) error {
+	if opts.ManifestFile == "" && opts.ReleasesFile == "" {
+		return nil
+	}
+
+	m := &manifest.Manifest{
+		Releases: []*manifest.Release{},
+	}
+	for _, res := range results {
+		if res.Err != nil {
+			continue
+		}
+		m.Releases = append(m.Releases, &manifest.Release{
+			Service:     res.Name,
+			Target:      res.Target.Name,
+			Repository:  res.Image.Repository,
+			Tags:        res.Image.Tags,
+			Digest:      res.Image.Digest,
+			Image:       res.Image.Reference(),
+			GitSHA:      md.GitSHA,
+			GitBranch:   md.GitBranch,
+			Dirty:       md.Dirty,
+			PullRequest: md.PullRequest,
+			BuildTime:   md.BuildTime,
+			Platforms:   res.Image.Platforms,
+			Size:        res.Image.Size,
+			Signature:   res.Signature,
+			Attestation: res.Attestation,
+			SBOM:        res.SBOM,
+			SBOMFile:    res.SBOMFile,
+		})
+		for _, tr := range res.Targets {
+			if tr.Err != nil {
+				continue
+			}
+			m.Releases = append(m.Releases, &manifest.Release{
+				Service:     res.Name,
+				Target:      tr.Name,
+				Repository:  tr.Image.Repository,
+				Tags:        tr.Image.Tags,
+				Digest:      tr.Image.Digest,
+				Image:       tr.Image.Reference(),
+				GitSHA:      md.GitSHA,
+				GitBranch:   md.GitBranch,
+				Dirty:       md.Dirty,
+				PullRequest: md.PullRequest,
+				BuildTime:   md.BuildTime,
+				Platforms:   tr.Image.Platforms,
+				Size:        tr.Image.Size,
+				Signature:   tr.Signature,
+				Attestation: tr.Attestation,
+				SBOM:        tr.SBOM,
+				SBOMFile:    res.SBOMFile,
+			})
+		}
+	}
+
+	if opts.ManifestFile != "" {
+		err := manifest.Write(opts.ManifestFile, m)
+		if err != nil {
+			return fmt.Errorf("write release manifest: %w", err)
+		}
+	}
+
+	if opts.ReleasesFile != "" && len(m.Releases) > 0 {
+		err := manifest.Append(opts.ReleasesFile, m.Releases...)
+		if err != nil {
+			return fmt.Errorf("append to releases file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateManifests pins references to the published images in the
+// manifests checkout to their digests and commits the change.
+func updateManifests(ctx context.Context, logger *logrus.Logger, opts *gitopsOptions, md *git.Metadata, results []*result) error {
+	if opts.Dir == "" {
+		return nil
+	}
+
+	var images []*gitops.Image
+	msg := strings.Builder{}
+	for _, res := range results {
+		if res.Err != nil {
+			continue
+		}
+		published := []*docker.Image{res.Image}
+		for _, tr := range res.Targets {
+			if tr.Err == nil {
+				published = append(published, tr.Image)
+			}
+		}
+		for _, img := range published {
+			images = append(images, &gitops.Image{
+				Repository: img.Repository,
+				Tag:        img.Tags[0],
+				Digest:     img.Digest,
+			})
+			_, _ = fmt.Fprintf(&msg, "\n* %s", img.Reference())
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	files, err := gitops.UpdateDir(logger, opts.Dir, images)
+	if err != nil {
+		return fmt.Errorf("update manifests: %w", err)
+	}
+	if len(files) == 0 {
+		logger.Infoln("No manifests reference the published images")
+		return nil
+	}
+
+	hash, err := gitops.Commit(ctx, &gitops.CommitRequest{
+		Dir:          opts.Dir,
+		Files:        files,
+		Message:      fmt.Sprintf("Release %s\n\nPublished images:%s\n", md.GitSHA, msg.String()),
+		AuthorName:   opts.AuthorName,
+		AuthorEmail:  opts.AuthorEmail,
+		Push:         opts.Push,
+		PushUser:     opts.User,
+		PushPassword: opts.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Committed manifest changes as %s", hash)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/webhook"
+)
+
+// notify notifies the webhooks on the command line and in the deploy file
+// of the outcome of the release. Failures to notify are logged, but do not
+// fail the release.
+func (r *releaser) notify(ctx context.Context, logger logrus.FieldLogger, res *result) {
+	hooks := append([]*webhook.Hook{}, r.opts.Webhooks...)
+	for _, w := range res.Webhooks {
+		h := &webhook.Hook{
+			URL:    w.URL,
+			Events: w.Events,
+		}
+		if w.Secret != "" {
+			h.Secret = os.Getenv(w.Secret)
+			if h.Secret == "" {
+				logger.Warnf("Secret %s of a webhook is not set, notifying it unsigned", w.Secret)
+			}
+		}
+		hooks = append(hooks, h)
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	// Notify even if the run was cancelled, so that failures are reported.
+	if ctx.Err() != nil {
+		ctx = context.Background()
+	}
+
+	payload := r.payload(res)
+	failed := r.webhooks.Notify(ctx, logger, hooks, payload)
+	if failed == 0 {
+		logger.Infof("Notified %d webhooks of the %s release", len(hooks), payload.Event)
+	}
+}
+
+// payload returns the payload describing the outcome of the release.
+func (r *releaser) payload(res *result) *webhook.Payload {
+	p := &webhook.Payload{
+		Event:         webhook.EventPublished,
+		Service:       res.Name,
+		GitSHA:        r.md.GitSHA,
+		GitBranch:     r.md.GitBranch,
+		Author:        r.md.Author,
+		CommitMessage: r.md.CommitMessage,
+		BuildURL:      r.md.BuildURL,
+		Timestamp:     time.Now().UTC(),
+	}
+	if res.failed() {
+		p.Event = webhook.EventFailed
+	}
+	if res.Err != nil {
+		p.Error = res.Err.Error()
+		return p
+	}
+
+	img := res.Image
+	p.Repository = img.Repository
+	p.Digest = img.Digest
+	p.Image = img.Reference()
+	p.Tags = img.Tags
+	p.Images = append(p.Images, &webhook.Image{
+		Target: res.Target.Name,
+		Image:  img.Reference(),
+		Tags:   img.Tags,
+	})
+	var errs []string
+	for _, tr := range res.Targets {
+		if tr.Err != nil {
+			errs = append(errs, tr.Name+": "+tr.Err.Error())
+			continue
+		}
+		p.Images = append(p.Images, &webhook.Image{
+			Target: tr.Name,
+			Image:  tr.Image.Reference(),
+			Tags:   tr.Image.Tags,
+		})
+	}
+	p.Error = strings.Join(errs, "; ")
+
+	return p
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/manifest"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/tags"
+	pkgcontext "github.com/uw-labs/go-mono/pkg/context"
+)
+
+// promoteOptions configures the promotion of an image.
+type promoteOptions struct {
+	Service        string
+	Source         string
+	SourceRegistry string
+	SourceUser     string
+	SourcePassword string
+	TargetRegistry string
+	TargetUser     string
+	TargetPassword string
+	Tags           []string
+	ManifestFile   string
+	ReleasesFile   string
+}
+
+// promoteMain runs the promote subcommand, which copies an existing
+// image to new tags or another registry without rebuilding it.
+func promoteMain(logger *logrus.Logger, args []string) {
+	fs := flag.NewFlagSet("promote", flag.ExitOnError)
+	service := fs.String("service", "", "The name of the service to promote.")
+	source := fs.String("source", "", "The tag or digest of the image to promote.")
+	sourceRegistry := fs.String("docker-registry", "docker.pkg.github.com/uw-labs/go-mono", "The registry to promote the image from. Can include any subpaths.")
+	sourceUser := fs.String("docker-user", "", "The docker user to use when authenticating against the registry.")
+	sourcePassword := fs.String("docker-password", "", "The password to use when authenticating the user against the registry.")
+	targetRegistry := fs.String("target-registry", "", "If set, the registry to promote the image to. Defaults to the source registry.")
+	targetUser := fs.String("target-user", "", "The docker user to use when authenticating against the target registry. Defaults to the docker user.")
+	targetPassword := fs.String("target-password", "", "The password to use when authenticating against the target registry. Defaults to the docker password.")
+	manifestFile := fs.String("manifest-file", "", "If set, the path to write a JSON manifest of the promoted image to.")
+	releasesFile := fs.String("releases-file", "", "If set, the path of a repository-level releases file to append the promotion to.")
+	var targetTags stringsFlag
+	fs.Var(&targetTags, "tag", "A tag to push the promoted image with. Can be repeated. "+
+		"Defaults to the source tag when promoting to another registry.")
+	_ = fs.Parse(args)
+
+	if *service == "" || *source == "" {
+		logger.Fatal("service and source must be specified")
+	}
+
+	opts := &promoteOptions{
+		Service:        *service,
+		Source:         *source,
+		SourceRegistry: *sourceRegistry,
+		SourceUser:     *sourceUser,
+		SourcePassword: *sourcePassword,
+		TargetRegistry: *targetRegistry,
+		TargetUser:     *targetUser,
+		TargetPassword: *targetPassword,
+		Tags:           targetTags,
+		ManifestFile:   *manifestFile,
+		ReleasesFile:   *releasesFile,
+	}
+	if opts.TargetRegistry == "" {
+		opts.TargetRegistry = opts.SourceRegistry
+	}
+	if opts.TargetUser == "" && opts.TargetPassword == "" {
+		opts.TargetUser, opts.TargetPassword = opts.SourceUser, opts.SourcePassword
+	}
+
+	isDigest := strings.HasPrefix(opts.Source, "sha256:")
+	if len(opts.Tags) == 0 {
+		if isDigest || opts.TargetRegistry == opts.SourceRegistry {
+			logger.Fatal("at least one tag must be specified")
+		}
+		opts.Tags = []string{opts.Source}
+	}
+	for _, tag := range opts.Tags {
+		if err := tags.Validate(tag); err != nil {
+			logger.WithError(err).Fatal()
+		}
+	}
+
+	err := promote(pkgcontext.WithSignalHandler(context.Background()), logger, opts)
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+}
+
+func promote(ctx context.Context, logger logrus.FieldLogger, opts *promoteOptions) error {
+	src, err := registry.ParseRepository(opts.SourceRegistry + "/" + opts.Service)
+	if err != nil {
+		return err
+	}
+	dst, err := registry.ParseRepository(opts.TargetRegistry + "/" + opts.Service)
+	if err != nil {
+		return err
+	}
+
+	srcClient := registry.NewClient(opts.SourceUser, opts.SourcePassword)
+	dstClient := registry.NewClient(opts.TargetUser, opts.TargetPassword)
+
+	sourceRef := src.String() + ":" + opts.Source
+	if strings.HasPrefix(opts.Source, "sha256:") {
+		sourceRef = src.String() + "@" + opts.Source
+	}
+
+	logger = logger.WithField("service", opts.Service)
+	logger.Infof("Promoting %s to %s with tags %s", sourceRef, dst, strings.Join(opts.Tags, ", "))
+
+	res, err := registry.Copy(ctx, srcClient, src, opts.Source, dstClient, dst, opts.Tags)
+	if err != nil {
+		return fmt.Errorf("promote %s: %w", sourceRef, err)
+	}
+
+	digest := res.Manifest.Digest
+	release := &manifest.Release{
+		Service:      opts.Service,
+		Repository:   dst.String(),
+		Tags:         opts.Tags,
+		Digest:       digest,
+		Image:        dst.String() + "@" + digest,
+		GitSHA:       res.Labels["revision"],
+		BuildTime:    time.Now(),
+		Platforms:    res.Platforms,
+		Size:         res.Size,
+		PromotedFrom: src.String() + "@" + digest,
+	}
+
+	// Signatures, attestations and SBOMs already
+	// share the repository with the image.
+	if src != dst {
+		refs, err := registry.CopyAttachments(ctx, srcClient, src, digest, dstClient, dst, attachmentKinds)
+		if err != nil {
+			return err
+		}
+		release.Signature = refs["sig"]
+		release.Attestation = refs["att"]
+		release.SBOM = refs["sbom"]
+	}
+
+	logger.Infof("Promoted image %s", release.Image)
+
+	if opts.ManifestFile != "" {
+		err = manifest.Write(opts.ManifestFile, &manifest.Manifest{
+			Releases: []*manifest.Release{release},
+		})
+		if err != nil {
+			return fmt.Errorf("write release manifest: %w", err)
+		}
+	}
+
+	if opts.ReleasesFile != "" {
+		err = manifest.Append(opts.ReleasesFile, release)
+		if err != nil {
+			return fmt.Errorf("append to releases file: %w", err)
+		}
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, release.Image)
+	return err
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/binary"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/git"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/sbom"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/sign"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/tags"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/verify"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/vuln"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/webhook"
+)
+
+// result records the outcome of releasing a single deploy file.
+type result struct {
+	DeployFile string
+	Name       string
+	// Target is the target the image was built and pushed to,
+	// and Image the image pushed to it.
+	Target      *target
+	Image       *docker.Image
+	Signature   string
+	Attestation string
+	SBOM        string
+	SBOMFile    string
+	// Targets are the outcomes of copying the image to the other targets.
+	Targets []*targetResult
+	// Webhooks are the webhooks configured in the deploy file.
+	Webhooks []*deploy.Webhook
+	Duration time.Duration
+	Err      error
+}
+
+// failed returns true if the release or any copy to a target failed.
+func (res *result) failed() bool {
+	if res.Err != nil {
+		return true
+	}
+	for _, tr := range res.Targets {
+		if tr.Err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// releaser holds the dependencies shared by every release in a run.
+type releaser struct {
+	logger   *logrus.Logger
+	opts     *options
+	md       *git.Metadata
+	docker   *docker.Client
+	registry *registry.Client
+	// signingKey is the key to sign images with, if any.
+	signingKey *ecdsa.PrivateKey
+	goVersion  string
+	vendor     []*sbom.VendorModule
+	// vulnDB is the database to check the modules of images against, if any.
+	vulnDB   *vuln.Database
+	webhooks *webhook.Client
+}
+
+// release builds and publishes a single deploy file. Failures are recorded
+// on the result rather than returned, so that the remaining releases continue.
+func (r *releaser) release(ctx context.Context, deployFile string) *result {
+	start := time.Now()
+	res := &result{
+		DeployFile: deployFile,
+		Name:       filepath.Dir(deployFile),
+	}
+
+	res.Err = r.publish(ctx, res)
+	res.Duration = time.Since(start).Round(time.Millisecond)
+
+	log := r.logger.WithField("service", res.Name)
+	if res.Err != nil {
+		log.WithError(res.Err).Error("Release failed")
+	} else {
+		log.Infof("Published %s", res.Image.Reference())
+	}
+
+	r.notify(ctx, log, res)
+
+	return res
+}
+
+// publish performs the release, recording its outputs on the result.
+func (r *releaser) publish(ctx context.Context, res *result) error {
+	start := time.Now()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conf, err := deploy.Parse(r.opts.RepoRoot, res.DeployFile)
+	if err != nil {
+		return fmt.Errorf("parse deployment: %w", err)
+	}
+
+	res.Name = conf.Name
+	res.Webhooks = conf.Webhooks
+	logger := r.logger.WithField("service", conf.Name)
+
+	logger.Infoln("Deploying", conf.Name)
+
+	buildCtx, cancel := withTimeout(ctx, r.opts.Timeouts.Build)
+	defer cancel()
+
+	var entrypoint string
+	binaries := make([]*docker.Binary, 0, len(conf.Binaries))
+	for _, b := range conf.Binaries {
+		logger.Infoln("Building binary", b.Main)
+		binPath, err := binary.Build(buildCtx, logger, &binary.Request{
+			Name:     conf.Name,
+			RepoRoot: r.opts.RepoRoot,
+			MainPath: b.Main,
+		})
+		if err != nil {
+			return fmt.Errorf("build binary %s: %w", b.Main, err)
+		}
+		defer func() {
+			err := os.RemoveAll(filepath.Dir(binPath))
+			if err != nil {
+				logger.WithError(err).Infof("remove binary directory (%s)", filepath.Dir(binPath))
+			}
+		}()
+
+		binaries = append(binaries, &docker.Binary{
+			LocalPath:  binPath,
+			Path:       b.Path,
+			Entrypoint: b.Entrypoint,
+		})
+		if b.Entrypoint {
+			entrypoint = binPath
+		}
+	}
+
+	verifyCtx, cancel := withTimeout(ctx, r.opts.Timeouts.Verify)
+	defer cancel()
+
+	err = verify.Run(verifyCtx, logger, &verify.Request{
+		RepoRoot:   r.opts.RepoRoot,
+		MainPaths:  conf.Mains(),
+		BinaryPath: entrypoint,
+		Config:     &conf.Verify,
+	})
+	if err != nil {
+		return err
+	}
+
+	var bi *binary.BuildInfo
+	if r.opts.SBOMFormat != "" || r.vulnDB != nil {
+		for _, b := range binaries {
+			info, err := binary.ReadBuildInfo(ctx, b.LocalPath)
+			if err != nil {
+				return err
+			}
+			bi = mergeBuildInfo(bi, info)
+		}
+	}
+
+	if r.vulnDB != nil {
+		logger.Infoln("Checking for vulnerable modules")
+		_, err = r.vulnDB.Check(logger, &vuln.Request{
+			BuildInfo: bi,
+			Config:    &conf.Verify.Vulnerabilities,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	targets, err := r.resolveTargets(conf, tags.NewData(&tags.Request{
+		Service:       conf.Name,
+		SHA:           r.md.GitSHA,
+		Branch:        r.md.GitBranch,
+		DefaultBranch: r.opts.DefaultBranch,
+		GitTags:       r.md.GitTags,
+		BuildNumber:   r.md.BuildNumber,
+		PullRequest:   r.md.PullRequest,
+		Dirty:         r.md.Dirty,
+	}))
+	if err != nil {
+		return err
+	}
+	res.Target = targets[0]
+	client := r.client(res.Target)
+
+	logger.Infoln("Building Docker image")
+	img, err := r.docker.BuildAndPushImage(ctx, logger, &docker.Request{
+		RepoRoot:         r.opts.RepoRoot,
+		Binaries:         binaries,
+		Registry:         res.Target.Registry,
+		RegistryUser:     res.Target.User,
+		RegistryPassword: res.Target.Password,
+		GitSHA:           r.md.GitSHA,
+		Name:             conf.Name,
+		Tags:             res.Target.Tags,
+		MaxSize:          int64(conf.Verify.MaxImageSize),
+		BuildTimeout:     r.opts.Timeouts.Image,
+		PushTimeout:      r.opts.Timeouts.Push,
+		Retry:            r.opts.Retry,
+		ProgressInterval: r.opts.ProgressInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("build Docker image: %w", err)
+	}
+	if img.Digest == "" {
+		return errors.New("registry did not report a digest for the pushed image")
+	}
+	res.Image = img
+
+	if r.opts.SBOMFormat != "" {
+		err = r.attachSBOM(ctx, logger, client, res, bi)
+		if err != nil {
+			return fmt.Errorf("attach SBOM: %w", err)
+		}
+	}
+
+	if r.signingKey != nil {
+		err = r.sign(ctx, logger, sign.NewSigner(r.signingKey, client), res, start)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The image is signed before being copied, so that
+	// the attachments are copied along with it.
+	for _, t := range targets[1:] {
+		res.Targets = append(res.Targets, r.copyToTarget(ctx, logger, res, t))
+	}
+
+	return nil
+}
+
+// withTimeout returns a copy of the context limited to the timeout,
+// or the context itself if the timeout is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// attachSBOM generates a bill of materials for the published image from
+// the binaries' build information and the base image's OS packages, and
+// attaches it to the image.
+func (r *releaser) attachSBOM(ctx context.Context, logger logrus.FieldLogger, client *registry.Client, res *result, bi *binary.BuildInfo) error {
+	logger.Infoln("Generating SBOM")
+	img := res.Image
+	pkgs, err := sbom.OSPackages(func(path string) ([]byte, error) {
+		return r.docker.ReadFile(ctx, img.Repository+":"+img.Tags[0], path)
+	})
+	if err != nil {
+		return err
+	}
+
+	doc := sbom.New(&sbom.Request{
+		Name:       res.Name,
+		Repository: img.Repository,
+		Digest:     img.Digest,
+		BuildInfo:  bi,
+		Vendor:     r.vendor,
+		Packages:   pkgs,
+	})
+	data, err := doc.Encode(r.opts.SBOMFormat)
+	if err != nil {
+		return err
+	}
+
+	repo, err := registry.ParseRepository(img.Repository)
+	if err != nil {
+		return err
+	}
+
+	res.SBOM, err = client.Attach(ctx, repo, img.Digest, &registry.Attachment{
+		Kind:      "sbom",
+		MediaType: sbom.MediaType(r.opts.SBOMFormat),
+		Content:   data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.opts.ManifestFile != "" {
+		res.SBOMFile = filepath.Join(filepath.Dir(r.opts.ManifestFile), res.Name+".sbom.json")
+		err = ioutil.WriteFile(res.SBOMFile, data, 0o644)
+		if err != nil {
+			return fmt.Errorf("write SBOM: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeBuildInfo adds the dependencies of the build info of another
+// binary in the image to the build info of the image.
+func mergeBuildInfo(bi, other *binary.BuildInfo) *binary.BuildInfo {
+	if bi == nil {
+		return other
+	}
+
+	seen := map[string]bool{}
+	for _, dep := range bi.Deps {
+		seen[dep.Path+"@"+dep.Version] = true
+	}
+	for _, dep := range other.Deps {
+		if !seen[dep.Path+"@"+dep.Version] {
+			seen[dep.Path+"@"+dep.Version] = true
+			bi.Deps = append(bi.Deps, dep)
+		}
+	}
+
+	return bi
+}
+
+// sign signs the published image and attaches a provenance attestation to it.
+func (r *releaser) sign(ctx context.Context, logger logrus.FieldLogger, signer *sign.Signer, res *result, start time.Time) error {
+	img := res.Image
+
+	logger.Infoln("Signing image")
+	var err error
+	res.Signature, err = signer.Sign(ctx, img.Repository, img.Digest, map[string]string{
+		"revision": r.md.GitSHA,
+	})
+	if err != nil {
+		return fmt.Errorf("sign image: %w", err)
+	}
+
+	deployConfig, err := ioutil.ReadFile(res.DeployFile)
+	if err != nil {
+		return fmt.Errorf("read deploy file: %w", err)
+	}
+
+	logger.Infoln("Attaching provenance attestation")
+	res.Attestation, err = signer.Attest(ctx, img.Repository, img.Digest, sign.NewProvenance(&sign.ProvenanceRequest{
+		Repository:   img.Repository,
+		Digest:       img.Digest,
+		BuilderID:    r.opts.BuilderID,
+		GitRemote:    r.md.RemoteURL,
+		GitSHA:       r.md.GitSHA,
+		DeployFile:   res.DeployFile,
+		DeployConfig: string(deployConfig),
+		GoVersion:    r.goVersion,
+		Started:      start,
+		Finished:     time.Now(),
+	}))
+	if err != nil {
+		return fmt.Errorf("attest image provenance: %w", err)
+	}
+
+	return nil
+}
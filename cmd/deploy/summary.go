@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// printSummary writes a table of the outcome of every release in the run.
+func printSummary(w io.Writer, results []*result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	_, err := fmt.Fprintln(tw, "SERVICE\tTARGET\tSTATUS\tDURATION\tDIGEST/ERROR")
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		var status, detail, target string
+		if res.Target != nil {
+			target = res.Target.Name
+		}
+		if res.Err != nil {
+			status, detail = "failed", res.Err.Error()
+		} else {
+			status, detail = "published", res.Image.Reference()
+		}
+
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", res.Name, target, status, res.Duration, detail)
+		if err != nil {
+			return err
+		}
+
+		for _, tr := range res.Targets {
+			if tr.Err != nil {
+				status, detail = "failed", tr.Err.Error()
+			} else {
+				status, detail = "published", tr.Image.Reference()
+			}
+
+			_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", res.Name, tr.Name, status, tr.Duration, detail)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tw.Flush()
+}
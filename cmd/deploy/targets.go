@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/docker"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/registry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/retry"
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/tags"
+)
+
+// attachmentKinds are the kinds of attachments copied with images.
+var attachmentKinds = []string{"sig", "att", "sbom"}
+
+// target is a registry an image is pushed to, with its tags rendered
+// and its credentials resolved.
+type target struct {
+	Name     string
+	Registry string
+	Tags     []string
+	User     string
+	Password string
+}
+
+// targetResult records the outcome of copying an image to a target.
+type targetResult struct {
+	Name        string
+	Image       *docker.Image
+	Signature   string
+	Attestation string
+	SBOM        string
+	Duration    time.Duration
+	Err         error
+}
+
+// resolveTargets returns the targets of the deployment, rendering their tag
+// templates with the data. The registry and credentials on the command line
+// are used for deployments without targets.
+func (r *releaser) resolveTargets(conf *deploy.Deployment, data *tags.Data) ([]*target, error) {
+	templates := r.opts.Tags
+	if len(conf.Tags) > 0 {
+		templates = conf.Tags
+	}
+
+	confs := conf.Targets
+	if len(confs) == 0 {
+		confs = []*deploy.Target{{
+			Name:     r.opts.DockerRegistry,
+			Registry: r.opts.DockerRegistry,
+		}}
+	}
+
+	targets := make([]*target, 0, len(confs))
+	for _, c := range confs {
+		t := &target{
+			Name:     c.Name,
+			Registry: c.Registry,
+			User:     r.opts.DockerUser,
+			Password: r.opts.DockerPassword,
+		}
+		if c.Credentials != "" {
+			t.User = os.Getenv(c.Credentials + "_USER")
+			t.Password = os.Getenv(c.Credentials + "_PASSWORD")
+		}
+
+		targetTemplates := templates
+		if len(c.Tags) > 0 {
+			targetTemplates = c.Tags
+		}
+		var err error
+		t.Tags, err = tags.Render(targetTemplates, data)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
+
+// client returns a registry client authenticating with the credentials of
+// the target, sharing the client of the run for the default credentials.
+func (r *releaser) client(t *target) *registry.Client {
+	if t.User == r.opts.DockerUser && t.Password == r.opts.DockerPassword {
+		return r.registry
+	}
+
+	return registry.NewClient(t.User, t.Password)
+}
+
+// copyToTarget copies the published image to the target. Failures are
+// recorded on the result, so that the remaining targets continue.
+func (r *releaser) copyToTarget(ctx context.Context, logger logrus.FieldLogger, res *result, t *target) *targetResult {
+	start := time.Now()
+	tr := &targetResult{
+		Name: t.Name,
+	}
+
+	tr.Err = r.copyImage(ctx, logger, res, t, tr)
+	tr.Duration = time.Since(start).Round(time.Millisecond)
+
+	log := logger.WithField("target", t.Name)
+	if tr.Err != nil {
+		log.WithError(tr.Err).Error("Copy to target failed")
+		return tr
+	}
+
+	log.Infof("Published %s", tr.Image.Reference())
+
+	return tr
+}
+
+// retry calls fn with the retry policy of the run, limiting each attempt
+// to the push timeout. Errors that are not temporary are not retried.
+func (r *releaser) retry(ctx context.Context, logger logrus.FieldLogger, op string, fn func(ctx context.Context) error) error {
+	return r.opts.Retry.Do(ctx, logger, op, func(ctx context.Context) error {
+		ctx, cancel := withTimeout(ctx, r.opts.Timeouts.Push)
+		defer cancel()
+
+		err := fn(ctx)
+		if err != nil && !registry.Temporary(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
+
+// copyImage copies the image and its attachments, recording
+// the copies on the target result.
+func (r *releaser) copyImage(ctx context.Context, logger logrus.FieldLogger, res *result, t *target, tr *targetResult) error {
+	src, err := registry.ParseRepository(res.Image.Repository)
+	if err != nil {
+		return err
+	}
+	dst, err := registry.ParseRepository(t.Registry + "/" + res.Name)
+	if err != nil {
+		return err
+	}
+
+	srcClient := r.client(res.Target)
+	dstClient := r.client(t)
+
+	logger.Infof("Copying image to %s", t.Name)
+	var copied *registry.CopyResult
+	err = r.retry(ctx, logger, "Copy to "+t.Name, func(ctx context.Context) (err error) {
+		copied, err = registry.Copy(ctx, srcClient, src, res.Image.Digest, dstClient, dst, t.Tags)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("copy image: %w", err)
+	}
+
+	var refs map[string]string
+	err = r.retry(ctx, logger, "Copy attachments to "+t.Name, func(ctx context.Context) (err error) {
+		refs, err = registry.CopyAttachments(ctx, srcClient, src, res.Image.Digest, dstClient, dst, attachmentKinds)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	tr.Image = &docker.Image{
+		Repository: t.Registry + "/" + res.Name,
+		Tags:       t.Tags,
+		Digest:     copied.Manifest.Digest,
+		Platforms:  res.Image.Platforms,
+		Size:       res.Image.Size,
+	}
+	tr.Signature = refs["sig"]
+	tr.Attestation = refs["att"]
+	tr.SBOM = refs["sbom"]
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/deploy/internal/deploy"
+)
+
+// validateMain runs the validate subcommand, which checks the deploy files
+// given as arguments, or every deploy file in the repository, and exits with
+// a non-zero status if any has problems.
+func validateMain(logger *logrus.Logger, args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	repoRoot := fs.String("repo-root", ".", "The root of the repo, to find deploy files and main packages in.")
+	printSchema := fs.Bool("print-schema", false, "Print the JSON schema of deploy files and exit.")
+	_ = fs.Parse(args)
+
+	if *printSchema {
+		_, err := os.Stdout.Write(deploy.Schema())
+		if err != nil {
+			logger.WithError(err).Fatal()
+		}
+		return
+	}
+
+	problems, err := validate(os.Stdout, *repoRoot, fs.Args())
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+	if problems > 0 {
+		logger.Fatalf("found %d problems in deploy files", problems)
+	}
+}
+
+// validate validates the deploy files, relative to the repository root,
+// or every deploy file in the repository if there are none, printing the
+// problems found to w. It returns the number of problems.
+func validate(w io.Writer, repoRoot string, files []string) (int, error) {
+	if len(files) == 0 {
+		var err error
+		files, err = deploy.FindFiles(repoRoot)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	problems, err := deploy.Validate(repoRoot, files)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range problems {
+		_, err = fmt.Fprintln(w, p)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(problems) == 0 {
+		_, err = fmt.Fprintf(w, "No problems found in %d deploy files\n", len(files))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(problems), nil
+}
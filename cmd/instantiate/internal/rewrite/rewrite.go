@@ -0,0 +1,214 @@
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Replacement replaces a module path, registry or proto package name.
+type Replacement struct {
+	Old string
+	New string
+	// Identifier replaces whole identifiers, such as proto package names,
+	// rather than whole paths. Paths such as module paths end at a character
+	// that cannot appear in a path, so that github.com/org/repo is not
+	// replaced in github.com/org/repo-other or docker.github.com/org/repo.
+	Identifier bool
+}
+
+// Request is the input to Rewrite.
+type Request struct {
+	RepoRoot string
+	// Replacements are applied in order, so registries containing
+	// the module path should come before the module path.
+	Replacements []*Replacement
+	// Dirs are the directory names to rename, such as the proto package
+	// directories. Directories are renamed with the first replacement
+	// whose old value is their name.
+	Dirs []*Replacement
+	// Generated are the directories of generated code, relative to the
+	// repository root, which are removed so that they can be regenerated.
+	Generated []string
+	// Ignored are the files and directories to leave alone, relative to the
+	// repository root, such as those ignored by git.
+	Ignored []string
+	// DryRun reports the changes without making them.
+	DryRun bool
+}
+
+// Result lists the changes made by Rewrite,
+// with paths relative to the repository root.
+type Result struct {
+	// Files are the rewritten files, at their paths before renaming directories.
+	Files []string
+	// Renamed maps the old paths of the renamed directories to their new paths.
+	Renamed map[string]string
+	// Removed are the removed directories of generated code.
+	Removed []string
+}
+
+// generated matches the comment marking generated Go files,
+// which are left to be regenerated.
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// Rewrite applies the replacements to the files of the repository, skipping
+// the vendor and git directories, ignored files, binary files and generated files, renames
+// the directories and removes the directories of generated code.
+func Rewrite(req *Request) (*Result, error) {
+	res := &Result{
+		Renamed: map[string]string{},
+	}
+	skip := map[string]bool{}
+	for _, dir := range req.Generated {
+		skip[filepath.Clean(dir)] = true
+	}
+	ignored := map[string]bool{}
+	for _, path := range req.Ignored {
+		ignored[filepath.Clean(path)] = true
+	}
+
+	var dirs []string
+	err := filepath.Walk(req.RepoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(req.RepoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			switch {
+			case rel == ".":
+				return nil
+			case info.Name() == ".git" || info.Name() == "vendor" || ignored[rel]:
+				return filepath.SkipDir
+			case skip[rel]:
+				_, err = os.Stat(path)
+				if err == nil {
+					res.Removed = append(res.Removed, rel)
+				}
+				return filepath.SkipDir
+			}
+			if r := findReplacement(req.Dirs, info.Name()); r != nil && r.New != r.Old {
+				dirs = append(dirs, rel)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || ignored[rel] {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 || strings.HasSuffix(path, ".go") && generated.Match(data) {
+			return nil
+		}
+
+		replaced := data
+		for _, r := range req.Replacements {
+			replaced = replace(replaced, r)
+		}
+		if bytes.Equal(replaced, data) {
+			return nil
+		}
+
+		res.Files = append(res.Files, rel)
+		if req.DryRun {
+			return nil
+		}
+		return ioutil.WriteFile(path, replaced, info.Mode())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rewrite files: %w", err)
+	}
+
+	for _, rel := range res.Removed {
+		if req.DryRun {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(req.RepoRoot, rel))
+		if err != nil {
+			return nil, fmt.Errorf("remove generated code: %w", err)
+		}
+	}
+
+	// Rename the deepest directories first, so that the paths
+	// of the directories still to be renamed stay valid.
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], string(filepath.Separator)) > strings.Count(dirs[j], string(filepath.Separator))
+	})
+	for _, rel := range dirs {
+		r := findReplacement(req.Dirs, filepath.Base(rel))
+		renamed := filepath.Join(filepath.Dir(rel), r.New)
+		res.Renamed[rel] = renamed
+		if req.DryRun {
+			continue
+		}
+		err = os.Rename(filepath.Join(req.RepoRoot, rel), filepath.Join(req.RepoRoot, renamed))
+		if err != nil {
+			return nil, fmt.Errorf("rename directory: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+func findReplacement(rs []*Replacement, old string) *Replacement {
+	for _, r := range rs {
+		if r.Old == old {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// replace replaces the occurrences of the old value that are not part of a
+// longer path or identifier.
+func replace(data []byte, r *Replacement) []byte {
+	inWord := isPathChar
+	if r.Identifier {
+		inWord = isIdentChar
+	}
+
+	old := []byte(r.Old)
+	var buf bytes.Buffer
+	for {
+		i := bytes.Index(data, old)
+		if i < 0 {
+			buf.Write(data)
+			return buf.Bytes()
+		}
+
+		end := i + len(old)
+		next := data[end:]
+		// Paths can be followed by punctuation, such as a full stop.
+		if len(next) > 0 && next[0] == '.' && !r.Identifier && (len(next) == 1 || !isIdentChar(next[1])) {
+			next = nil
+		}
+		if i > 0 && inWord(data[i-1]) || len(next) > 0 && inWord(next[0]) {
+			buf.Write(data[:end])
+		} else {
+			buf.Write(data[:i])
+			buf.WriteString(r.New)
+		}
+		data = data[end:]
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isPathChar(c byte) bool {
+	return isIdentChar(c) || c == '.' || c == '-'
+}
@@ -0,0 +1,122 @@
+package rewrite_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/instantiate/internal/rewrite"
+)
+
+func TestRewrite(t *testing.T) {
+	files := map[string]string{
+		"go.mod": "module github.com/old-org/old-repo\n",
+		"main.go": `import (
+	"github.com/old-org/old-repo-other/pkg"
+	userspb "github.com/old-org/old-repo/proto/gen/go/oldorg/users/v1"
+)
+`,
+		"gen.go":                      "// Code generated by tool. DO NOT EDIT.\n\nimport \"github.com/old-org/old-repo/pkg\"\n",
+		"vendor/pkg/pkg.go":           "import \"github.com/old-org/old-repo/pkg\"\n",
+		".circleci/config.yml":        "--docker-registry docker.pkg.github.com/old-org/old-repo \\\n--module-name github.com/old-org/old-repo\n",
+		"README.md":                   "Clone github.com/old-org/old-repo. The oldorgextra package is kept.\n",
+		"proto/oldorg/users/v1.proto": "package oldorg.users.v1;\noption go_package = \"github.com/old-org/old-repo/proto/gen/go/oldorg/users/v1;userspb\";\n",
+		"proto/gen/go/oldorg/users/v1/users.pb.go": "package userspb\n",
+		"bin/config.yml":    "registry: docker.pkg.github.com/old-org/old-repo\n",
+		"local.env":         "MODULE=github.com/old-org/old-repo\n",
+		"deploy/values.yml": "repository: old-org/old-repo/user-api\nbuildURL: https://circleci.com/gh/old-org/old-repo/34\n",
+	}
+
+	dir, err := ioutil.TempDir("", "rewrite")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	res, err := rewrite.Rewrite(&rewrite.Request{
+		RepoRoot: dir,
+		Replacements: []*rewrite.Replacement{
+			{Old: "docker.pkg.github.com/old-org/old-repo", New: "registry.example.com/acme/mono"},
+			{Old: "github.com/old-org/old-repo", New: "example.com/acme/mono"},
+			{Old: "old-org/old-repo", New: "acme/mono"},
+			{Old: "oldorg", New: "acme", Identifier: true},
+		},
+		Dirs:      []*rewrite.Replacement{{Old: "oldorg", New: "acme"}},
+		Generated: []string{filepath.Join("proto", "gen", "go", "oldorg")},
+		Ignored:   []string{"bin", "local.env"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantResult := &rewrite.Result{
+		Files: []string{
+			filepath.Join(".circleci", "config.yml"),
+			"README.md",
+			filepath.Join("deploy", "values.yml"),
+			"go.mod",
+			"main.go",
+			filepath.Join("proto", "oldorg", "users", "v1.proto"),
+		},
+		Renamed: map[string]string{
+			filepath.Join("proto", "oldorg"): filepath.Join("proto", "acme"),
+		},
+		Removed: []string{filepath.Join("proto", "gen", "go", "oldorg")},
+	}
+	if diff := cmp.Diff(wantResult, res); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+
+	want := map[string]string{
+		"go.mod": "module example.com/acme/mono\n",
+		"main.go": `import (
+	"github.com/old-org/old-repo-other/pkg"
+	userspb "example.com/acme/mono/proto/gen/go/acme/users/v1"
+)
+`,
+		"gen.go":                    files["gen.go"],
+		"vendor/pkg/pkg.go":         files["vendor/pkg/pkg.go"],
+		".circleci/config.yml":      "--docker-registry registry.example.com/acme/mono \\\n--module-name example.com/acme/mono\n",
+		"README.md":                 "Clone example.com/acme/mono. The oldorgextra package is kept.\n",
+		"proto/acme/users/v1.proto": "package acme.users.v1;\noption go_package = \"example.com/acme/mono/proto/gen/go/acme/users/v1;userspb\";\n",
+		"bin/config.yml":            files["bin/config.yml"],
+		"local.env":                 files["local.env"],
+		"deploy/values.yml":         "repository: acme/mono/user-api\nbuildURL: https://circleci.com/gh/acme/mono/34\n",
+	}
+	got := map[string]string{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		got[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/uw-labs/go-mono/cmd/instantiate/internal/rewrite"
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
+)
+
+var (
+	repoRoot    = flag.String("repo-root", ".", "The root of the repo to instantiate.")
+	moduleName  = flag.String("module-name", "", "The new module path, such as github.com/yourorg/yourrepo.")
+	protoOrg    = flag.String("proto-org", "", "The new root proto package. Defaults to the organisation of the module path, without dashes.")
+	registry    = flag.String("docker-registry", "", "The new registry to push images to. Defaults to the GitHub package registry of GitHub module paths.")
+	oldProtoOrg = flag.String("old-proto-org", "", "The root proto package to replace. Defaults to the organisation of the module path in go.mod, without dashes.")
+	oldRegistry = flag.String("old-docker-registry", "", "The registry to replace. Defaults to the GitHub package registry of the module path in go.mod.")
+	dryRun      = flag.Bool("dry-run", false, "Print the files that would change, without changing them.")
+	generate    = flag.Bool("generate", true, "Regenerate the code of the proto packages and the bindata, which requires protoc and the generators of make install-generators.")
+	verify      = flag.Bool("verify", true, "Check that the repository builds after instantiating it.")
+	logConfig   = pkglog.RegisterFlags(flag.CommandLine)
+)
+
+// protoPackage matches valid proto package name components.
+var protoPackage = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func main() {
+	flag.Parse()
+
+	logger, err := pkglog.New(logConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	if *moduleName == "" {
+		logger.Fatal("module-name must be specified")
+	}
+
+	err = run(logger)
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+}
+
+func run(logger *logrus.Logger) error {
+	goMod, err := ioutil.ReadFile(filepath.Join(*repoRoot, "go.mod"))
+	if err != nil {
+		return fmt.Errorf("read go.mod: %w", err)
+	}
+	oldModule := modfile.ModulePath(goMod)
+	if oldModule == "" {
+		return errors.New("go.mod does not declare a module path")
+	}
+
+	err = module.CheckPath(*moduleName)
+	if err != nil {
+		return err
+	}
+	// The old values default to those of the module path in go.mod rather than
+	// to literal values, which would be rewritten along with the rest of the repository.
+	if *oldProtoOrg == "" {
+		*oldProtoOrg = defaultProtoOrg(oldModule)
+	}
+	if *protoOrg == "" {
+		*protoOrg = defaultProtoOrg(*moduleName)
+	}
+	if !protoPackage.MatchString(*protoOrg) {
+		return fmt.Errorf("%q is not a valid proto package name", *protoOrg)
+	}
+	// The generated code imports the packages at their old paths until it is regenerated.
+	if !*generate && *verify {
+		return errors.New("the repository cannot build until its code is regenerated, pass --verify=false with --generate=false")
+	}
+	if *oldRegistry == "" {
+		*oldRegistry = githubRegistry(oldModule)
+		if *oldRegistry == "" {
+			return errors.New("old-docker-registry must be specified for modules not hosted on GitHub")
+		}
+	}
+	if *registry == "" {
+		*registry = githubRegistry(*moduleName)
+		if *registry == "" {
+			return errors.New("docker-registry must be specified for modules not hosted on GitHub")
+		}
+	}
+
+	ctx := pkgctx.WithSignalHandler(context.Background())
+	ignored, err := ignoredFiles(ctx, *repoRoot)
+	if err != nil {
+		return err
+	}
+
+	req := &rewrite.Request{
+		RepoRoot: *repoRoot,
+		Replacements: []*rewrite.Replacement{
+			// The registry contains the module path, so it is replaced first.
+			{Old: *oldRegistry, New: *registry},
+			{Old: oldModule, New: *moduleName},
+			// The organisation and repository, as in CI build URLs
+			// and registry paths without the registry host.
+			{Old: repoPath(oldModule), New: repoPath(*moduleName)},
+			{Old: *oldProtoOrg, New: *protoOrg, Identifier: true},
+		},
+		Dirs: []*rewrite.Replacement{
+			{Old: *oldProtoOrg, New: *protoOrg},
+		},
+		Ignored: ignored,
+		DryRun:  *dryRun,
+	}
+	// The generated code is only removed when it is regenerated,
+	// so that the repository is not left without it.
+	if *generate {
+		req.Generated = []string{
+			filepath.Join("proto", "gen", "go", *oldProtoOrg),
+			filepath.Join("proto", "gen", "openapiv2", *oldProtoOrg),
+		}
+	}
+	res, err := rewrite.Rewrite(req)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range res.Files {
+		logger.Infoln("Rewrote", f)
+	}
+	renamed := make([]string, 0, len(res.Renamed))
+	for dir := range res.Renamed {
+		renamed = append(renamed, dir)
+	}
+	sort.Strings(renamed)
+	for _, dir := range renamed {
+		logger.Infof("Renamed %s to %s", dir, res.Renamed[dir])
+	}
+	for _, dir := range res.Removed {
+		logger.Infoln("Removed generated code in", dir)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	var commands [][]string
+	if *generate {
+		commands = append(commands,
+			[]string{filepath.Join(".", "proto", "generate.sh")},
+			[]string{"go", "generate", "-x", "./..."},
+		)
+	} else {
+		logger.Warnln("Run make generate to regenerate the code of the proto packages and the bindata")
+	}
+	if *verify {
+		commands = append(commands, []string{"go", "build", "./..."})
+	}
+	for _, args := range commands {
+		logger.Infoln("Running", args)
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = *repoRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("run %s: %w", strings.Join(args, " "), err)
+		}
+	}
+
+	logger.Infof("Instantiated %s", *moduleName)
+	return nil
+}
+
+// ignoredFiles returns the untracked files and directories ignored by git,
+// such as build outputs, which are left alone.
+func ignoredFiles(ctx context.Context, repoRoot string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	cmd.Dir = repoRoot
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("list files ignored by git: %w", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			paths = append(paths, filepath.FromSlash(path))
+		}
+	}
+	return paths, nil
+}
+
+// githubRegistry returns the GitHub package registry of the module path,
+// or an empty string if the module is not hosted on GitHub.
+func githubRegistry(modulePath string) string {
+	if !strings.HasPrefix(modulePath, "github.com/") {
+		return ""
+	}
+
+	return "docker.pkg.github.com/" + strings.ToLower(strings.TrimPrefix(modulePath, "github.com/"))
+}
+
+// repoPath returns the module path without its host, such as
+// yourorg/yourrepo for github.com/yourorg/yourrepo.
+func repoPath(modulePath string) string {
+	i := strings.IndexByte(modulePath, '/')
+	return modulePath[i+1:]
+}
+
+// defaultProtoOrg returns the organisation of the module path, such as
+// yourorg for github.com/your-org/yourrepo, as a proto package name.
+func defaultProtoOrg(modulePath string) string {
+	parts := strings.Split(modulePath, "/")
+	org := parts[0]
+	if len(parts) > 1 {
+		org = parts[1]
+	}
+	org = strings.ToLower(org)
+
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, org)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstantiate(t *testing.T) {
+	// The instantiated repository runs its tests, including this one, with -short.
+	if testing.Short() {
+		t.Skip("instantiating the repository is slow")
+	}
+
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "instantiate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Untracked files are copied too, so that changes are tested before they are committed.
+	files := runCommand(t, repoRoot, nil, "git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	for _, name := range strings.Split(strings.TrimSuffix(files, "\x00"), "\x00") {
+		copyFile(t, filepath.Join(repoRoot, name), filepath.Join(dir, name))
+	}
+	runCommand(t, dir, nil, "git", "init", "-q")
+	// Ignored files are left alone.
+	ignored := filepath.Join(dir, ".dev", "config.yml")
+	copyFile(t, filepath.Join(repoRoot, "go.mod"), ignored)
+
+	runCommand(t, ".", nil, "go", "run", ".",
+		"--repo-root", dir,
+		"--module-name", "github.com/acme/mono",
+		"--generate=false",
+		"--verify=false",
+	)
+
+	got, err := ioutil.ReadFile(ignored)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(repoRoot, "go.mod"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ignored file was rewritten:\n%s", got)
+	}
+
+	// Regenerating the proto code needs protoc, so the generated code
+	// is updated to its new import paths instead, which is all that
+	// regenerating it would change for the tests.
+	genDir := filepath.Join(dir, "proto", "gen", "go", "acme")
+	err = filepath.Walk(genDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data = bytes.ReplaceAll(data, []byte(`"github.com/uw-labs/go-mono/proto/gen/go/uwlabs/`), []byte(`"github.com/acme/mono/proto/gen/go/acme/`))
+		return ioutil.WriteFile(path, data, info.Mode())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	binDir := filepath.Join(dir, ".bin")
+	runCommand(t, repoRoot, nil, "go", "build", "-o", filepath.Join(binDir, "go-bindata"), "github.com/tmthrgd/go-bindata/go-bindata")
+	env := []string{"PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")}
+	runCommand(t, dir, env, "go", "generate", "./...")
+	runCommand(t, dir, env, "go", "test", "-short", "./...")
+}
+
+func runCommand(t *testing.T, dir string, env []string, name string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run %s %s: %v\n%s%s", name, strings.Join(args, " "), err, out, stderr.Bytes())
+	}
+
+	return string(out)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		// The file is tracked, but has been deleted.
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = os.MkdirAll(filepath.Dir(dst), 0o755)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = ioutil.WriteFile(dst, data, info.Mode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
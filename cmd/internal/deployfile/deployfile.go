@@ -0,0 +1,12 @@
+// Package deployfile identifies the deploy files describing how the
+// services of the repository are released.
+package deployfile
+
+// FileName is the name of new deploy files.
+const FileName = "deploy.yml"
+
+// IsFileName returns true if name is the name of a deploy file,
+// deploy.yml or deploy.yaml.
+func IsFileName(name string) bool {
+	return name == FileName || name == "deploy.yaml"
+}
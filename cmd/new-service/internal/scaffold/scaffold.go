@@ -0,0 +1,229 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/uw-labs/go-mono/cmd/new-service/internal/scaffold/static"
+)
+
+//go:generate go-bindata -pkg static -prefix static -nometadata -ignore bindata -o ./static/bindata.go ./static
+
+// Request is the input to Generate.
+type Request struct {
+	RepoRoot string
+	// Module is the path of the module at the repository root.
+	Module string
+	// Name is the name of the service, and of its directory in cmd, such as billing-api.
+	Name string
+	// Package is the name of the proto package of the service, such as billing.
+	Package string
+	// Resource is the singular name of the resource managed by the service, such as invoice.
+	Resource string
+	// Postgres stores the resources in Postgres, rather than in memory.
+	Postgres    bool
+	GRPCPort    int
+	GatewayPort int
+	AdminPort   int
+}
+
+// file is a file rendered from a template.
+type file struct {
+	Template string
+	// Path is a template of the path of the file, relative to the repository root.
+	Path string
+}
+
+var (
+	commonFiles = []file{
+		{"main.go.tmpl", "cmd/{{.Name}}/main.go"},
+		{"deploy.yml.tmpl", "cmd/{{.Name}}/deploy.yml"},
+		{"server.go.tmpl", "cmd/{{.Name}}/internal/server/server.go"},
+		{"server_test.go.tmpl", "cmd/{{.Name}}/internal/server/server_test.go"},
+		{"model.go.tmpl", "cmd/{{.Name}}/internal/repo/model.go"},
+		{"messages.proto.tmpl", "proto/uwlabs/{{.Package}}/v1/{{.Package}}.proto"},
+		{"service.proto.tmpl", "proto/uwlabs/{{.Package}}/service/v1/service.proto"},
+	}
+	memoryFiles = []file{
+		{"repo_memory.go.tmpl", "cmd/{{.Name}}/internal/repo/repo.go"},
+	}
+	postgresFiles = []file{
+		{"repo_postgres.go.tmpl", "cmd/{{.Name}}/internal/repo/repo.go"},
+		{"001_setup.up.sql.tmpl", "cmd/{{.Name}}/internal/repo/migrations/001_setup.up.sql"},
+		{"001_setup.down.sql.tmpl", "cmd/{{.Name}}/internal/repo/migrations/001_setup.down.sql"},
+	}
+)
+
+// templateData is the data the templates are rendered with.
+type templateData struct {
+	*Request
+	// Service is the name of the gRPC service, such as BillingService.
+	Service string
+	// Resources is the plural of the resource.
+	Resources string
+	// Type and Types are the Go and proto type names of the resource and its plural.
+	Type  string
+	Types string
+	// EnvPrefix is the prefix of the environment variables configuring the service, such as BILLING_API.
+	EnvPrefix string
+}
+
+var (
+	serviceName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+	identifier  = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// reserved are the identifiers used by the templates,
+// which the resource cannot be named after.
+var reserved = map[string]bool{
+	"codes": true, "context": true, "ct": true, "ctx": true, "err": true,
+	"errors": true, "f": true, "fmt": true, "id": true, "iq": true,
+	"list": true, "logrus": true, "name": true, "ok": true, "q": true,
+	"r": true, "rand": true, "repo": true, "req": true, "rows": true,
+	"s": true, "server": true, "sort": true, "sql": true, "status": true,
+	"sync": true, "t": true, "testing": true, "time": true, "url": true,
+	"created": true, "got": true, "migrations": true,
+}
+
+// DefaultPackage returns the default proto package name of the service,
+// its name without any -api or -service suffix or dashes.
+func DefaultPackage(name string) string {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "-api"), "-service")
+	return strings.Replace(name, "-", "", -1)
+}
+
+// Generate renders the files of a new service into the repository, returning
+// their paths relative to the repository root. It refuses to overwrite the
+// directories of an existing service or proto package.
+func Generate(req *Request) ([]string, error) {
+	data, err := newTemplateData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range []string{
+		filepath.Join("cmd", req.Name),
+		filepath.Join("proto", "uwlabs", req.Package),
+	} {
+		_, err := os.Stat(filepath.Join(req.RepoRoot, dir))
+		if err == nil {
+			return nil, fmt.Errorf("%s already exists", dir)
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	files := append([]file(nil), commonFiles...)
+	if req.Postgres {
+		files = append(files, postgresFiles...)
+	} else {
+		files = append(files, memoryFiles...)
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		rendered, err := render(f.Path, f.Path, data)
+		if err != nil {
+			return nil, err
+		}
+		path := string(rendered)
+		content, err := render(f.Template, string(static.MustAsset(f.Template)), data)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(path, ".go") {
+			content, err = format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("format %s: %w", path, err)
+			}
+		}
+
+		fullPath := filepath.Join(req.RepoRoot, filepath.FromSlash(path))
+		err = os.MkdirAll(filepath.Dir(fullPath), 0o755)
+		if err != nil {
+			return nil, fmt.Errorf("create directory: %w", err)
+		}
+		err = ioutil.WriteFile(fullPath, content, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func newTemplateData(req *Request) (*templateData, error) {
+	switch {
+	case req.Module == "":
+		return nil, errors.New("module must be specified")
+	case !serviceName.MatchString(req.Name):
+		return nil, fmt.Errorf("name %q must be lower case words separated by dashes", req.Name)
+	case !identifier.MatchString(req.Package):
+		return nil, fmt.Errorf("package %q must be a lower case word", req.Package)
+	case !identifier.MatchString(req.Resource):
+		return nil, fmt.Errorf("resource %q must be a lower case word", req.Resource)
+	case !validPort(req.GRPCPort) || !validPort(req.GatewayPort) || !validPort(req.AdminPort):
+		return nil, errors.New("ports must be between 1 and 65535")
+	case req.GRPCPort == req.GatewayPort || req.GRPCPort == req.AdminPort || req.GatewayPort == req.AdminPort:
+		return nil, errors.New("the gRPC, gateway and admin ports must differ")
+	}
+
+	resources := plural(req.Resource)
+	for _, name := range []string{req.Resource, resources} {
+		if reserved[name] || token.IsKeyword(name) {
+			return nil, fmt.Errorf("resource %q clashes with an identifier of the generated code", name)
+		}
+	}
+
+	return &templateData{
+		Request:   req,
+		Service:   strings.Title(req.Package) + "Service",
+		Resources: resources,
+		Type:      strings.Title(req.Resource),
+		Types:     strings.Title(resources),
+		EnvPrefix: strings.ToUpper(strings.Replace(req.Name, "-", "_", -1)),
+	}, nil
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// plural returns the plural of the English noun.
+func plural(noun string) string {
+	switch {
+	case strings.HasSuffix(noun, "s"), strings.HasSuffix(noun, "x"),
+		strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "sh"):
+		return noun + "es"
+	case strings.HasSuffix(noun, "y") && len(noun) > 1 && !strings.ContainsAny(noun[len(noun)-2:len(noun)-1], "aeiou"):
+		return noun[:len(noun)-1] + "ies"
+	default:
+		return noun + "s"
+	}
+}
+
+func render(name, text string, data *templateData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("render template %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,182 @@
+package scaffold_test
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/uw-labs/go-mono/cmd/new-service/internal/scaffold"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *scaffold.Request
+		existing  string
+		wantFiles []string
+		wantErr   string
+	}{
+		{
+			name: "memory",
+			req: &scaffold.Request{
+				Name:     "stock-service",
+				Package:  "stock",
+				Resource: "entry",
+			},
+			wantFiles: []string{
+				"cmd/stock-service/main.go",
+				"cmd/stock-service/deploy.yml",
+				"cmd/stock-service/internal/server/server.go",
+				"cmd/stock-service/internal/server/server_test.go",
+				"cmd/stock-service/internal/repo/model.go",
+				"proto/uwlabs/stock/v1/stock.proto",
+				"proto/uwlabs/stock/service/v1/service.proto",
+				"cmd/stock-service/internal/repo/repo.go",
+			},
+		},
+		{
+			name: "postgres",
+			req: &scaffold.Request{
+				Name:     "billing-api",
+				Package:  "billing",
+				Resource: "invoice",
+				Postgres: true,
+			},
+			wantFiles: []string{
+				"cmd/billing-api/main.go",
+				"cmd/billing-api/deploy.yml",
+				"cmd/billing-api/internal/server/server.go",
+				"cmd/billing-api/internal/server/server_test.go",
+				"cmd/billing-api/internal/repo/model.go",
+				"proto/uwlabs/billing/v1/billing.proto",
+				"proto/uwlabs/billing/service/v1/service.proto",
+				"cmd/billing-api/internal/repo/repo.go",
+				"cmd/billing-api/internal/repo/migrations/001_setup.up.sql",
+				"cmd/billing-api/internal/repo/migrations/001_setup.down.sql",
+			},
+		},
+		{
+			name: "existing service",
+			req: &scaffold.Request{
+				Name:     "user-api",
+				Package:  "accounts",
+				Resource: "account",
+			},
+			existing: "cmd/user-api",
+			wantErr:  filepath.Join("cmd", "user-api") + " already exists",
+		},
+		{
+			name: "reserved resource",
+			req: &scaffold.Request{
+				Name:     "status-api",
+				Package:  "status",
+				Resource: "status",
+			},
+			wantErr: `resource "status" clashes with an identifier of the generated code`,
+		},
+		{
+			name: "invalid name",
+			req: &scaffold.Request{
+				Name:     "Billing_API",
+				Package:  "billing",
+				Resource: "invoice",
+			},
+			wantErr: `name "Billing_API" must be lower case words separated by dashes`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "scaffold")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			if test.existing != "" {
+				err = os.MkdirAll(filepath.Join(dir, test.existing), 0o755)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			test.req.RepoRoot = dir
+			test.req.Module = "github.com/uw-labs/go-mono"
+			test.req.GRPCPort = 8080
+			test.req.GatewayPort = 8081
+			test.req.AdminPort = 8082
+			files, err := scaffold.Generate(test.req)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.wantFiles, files); diff != "" {
+				t.Errorf("unexpected files (-want +got):\n%s", diff)
+			}
+
+			// Building the generated code requires its proto code to be
+			// generated, so the files are checked to parse as their packages.
+			fset := token.NewFileSet()
+			for _, file := range files {
+				if filepath.Ext(file) != ".go" {
+					continue
+				}
+				f, err := parser.ParseFile(fset, filepath.Join(dir, file), nil, parser.AllErrors)
+				if err != nil {
+					t.Errorf("unexpected error parsing %s: %v", file, err)
+					continue
+				}
+				want := path.Base(path.Dir(file))
+				if want == test.req.Name {
+					want = "main"
+				}
+				if got := strings.TrimSuffix(f.Name.Name, "_test"); got != want {
+					t.Errorf("expected %s to be package %s, got %s", file, want, f.Name.Name)
+				}
+			}
+
+			// The deploy package is internal to the deploy tool, so it validates the deploy file.
+			deployFile := path.Join("cmd", test.req.Name, "deploy.yml")
+			out, err := exec.Command("go", "run", "github.com/uw-labs/go-mono/cmd/deploy",
+				"validate", "--repo-root", dir, deployFile).CombinedOutput()
+			if err != nil {
+				t.Errorf("expected %s to be valid: %v\n%s", deployFile, err, out)
+			}
+
+			main, err := ioutil.ReadFile(filepath.Join(dir, "cmd", test.req.Name, "main.go"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			register := "servicepb.Register" + strings.Title(test.req.Package) + "ServiceServer(srv, backend)"
+			if !strings.Contains(string(main), register) {
+				t.Errorf("expected main.go to call %s", register)
+			}
+		})
+	}
+}
+
+func TestDefaultPackage(t *testing.T) {
+	for name, want := range map[string]string{
+		"billing-api":      "billing",
+		"stock-service":    "stock",
+		"payment-gateway":  "paymentgateway",
+		"user-preferences": "userpreferences",
+	} {
+		if got := scaffold.DefaultPackage(name); got != want {
+			t.Errorf("DefaultPackage(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
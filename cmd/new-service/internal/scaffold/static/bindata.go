@@ -0,0 +1,573 @@
+// Code generated by go-bindata. DO NOT EDIT.
+//  memcopy: true
+//  compress: true
+//  decompress: once
+//  asset-dir: true
+//  restore: true
+// sources:
+//  static/001_setup.down.sql.tmpl
+//  static/001_setup.up.sql.tmpl
+//  static/deploy.yml.tmpl
+//  static/main.go.tmpl
+//  static/messages.proto.tmpl
+//  static/model.go.tmpl
+//  static/repo_memory.go.tmpl
+//  static/repo_postgres.go.tmpl
+//  static/server.go.tmpl
+//  static/server_test.go.tmpl
+//  static/service.proto.tmpl
+
+package static
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmthrgd/go-bindata/restore"
+)
+
+type asset struct {
+	name string
+	data string
+	size int64
+
+	once  sync.Once
+	bytes []byte
+	err   error
+}
+
+func (a *asset) Name() string {
+	return a.name
+}
+
+func (a *asset) Size() int64 {
+	return a.size
+}
+
+func (a *asset) Mode() os.FileMode {
+	return 0
+}
+
+func (a *asset) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (*asset) IsDir() bool {
+	return false
+}
+
+func (*asset) Sys() interface{} {
+	return nil
+}
+
+// _bindata is a table, holding each asset generator, mapped to its name.
+var _bindata = map[string]*asset{
+	"001_setup.down.sql.tmpl": &asset{
+		name: "001_setup.down.sql.tmpl",
+		data: "" +
+			"\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xa8\xae\xd6" +
+			"\x0b\x4a\x2d\xce\x2f\x2d\x4a\x4e\x2d\xae\xad\xb5\xe6\x02\x0c\x00",
+		size: 37,
+	},
+	"001_setup.up.sql.tmpl": &asset{
+		name: "001_setup.up.sql.tmpl",
+		data: "" +
+			"\x5c\xcc\xb1\x6a\xc3\x30\x10\xc6\xf1\x5d\x4f\xf1\x8d\x36\x94\xbe\x40\x26\xb5\xb9\x80\xa8\xac\x04" +
+			"\xe9\x04\x4e\x17\x63\x6c\x11\x3c\x44\x0a\xb2\x4d\x29\xc6\xef\x5e\xea\x16\x0f\x59\xef\x7e\xdf\xff" +
+			"\xdd\x92\x64\x02\xd5\x4c\xc6\xa9\xb3\x81\x3a\xc1\x9c\x19\x54\x2b\xc7\x0e\x8f\x5b\x97\xbf\x1f\x53" +
+			"\x3a\x08\xf1\x2f\x59\xbe\x69\x7a\x52\xcb\xf2\x6a\xc3\x98\xe6\xdc\x85\x71\x5d\x51\x08\x00\x18\x7a" +
+			"\x78\xaf\x8e\xb8\x58\x55\x49\x7b\xc5\x07\x5d\x71\xa4\x93\xf4\x9a\x71\x0b\xb1\xc9\x6d\xec\xd3\xbd" +
+			"\x99\xe7\xa1\x2f\xca\x97\x6d\x12\xdb\x7b\x00\x53\xcd\x5b\xdc\x78\xad\xff\xee\x5d\x0e\xed\x14\x9a" +
+			"\x69\xf8\x7d\xab\x8a\x1c\xcb\xea\xc2\x9f\xbb\xda\xbb\x31\x7d\x15\xa5\x28\x0f\xe2\x67\x00",
+		size: 215,
+	},
+	"deploy.yml.tmpl": &asset{
+		name: "deploy.yml.tmpl",
+		data: "" +
+			"\x8c\x90\xc1\x4e\xeb\x40\x0c\x45\xf7\xf9\x0a\xff\x40\xfa\xf6\xd9\x3d\xa1\xaa\x1b\x04\x51\x0b\xeb" +
+			"\x6a\x68\x6e\x92\x11\xc9\x64\xe4\x71\x02\x69\x94\x7f\x47\x33\x93\xd2\x96\x82\x60\x67\x5d\x1f\x1f" +
+			"\xcb\x36\xaa\x45\x46\xd3\xb4\x7a\x50\x2d\xe6\x39\x19\xc0\xba\x1c\xb3\x84\x48\xe0\x24\x23\xe1\x1e" +
+			"\x09\xd1\x80\x53\xcd\xbd\x11\xdd\xc2\x13\xb6\x63\x71\xbe\x20\x4a\x29\x8a\x2a\xb6\x87\x10\xc4\x6e" +
+			"\x30\x6f\xb6\xf9\x5d\xde\xb1\xcc\xf3\xd2\x29\x1b\x55\x45\x34\xf5\xd0\x95\xa0\x16\xb1\x37\x02\x25" +
+			"\x78\x53\xe3\x4f\x8e\x2a\xb6\x6f\x5d\xaa\x68\xb5\xf9\x2a\xfb\xef\xc3\x6f\x54\x01\x8e\x8e\x69\x4a" +
+			"\x49\x97\xb4\xca\x3b\x27\x15\xc3\x05\xd2\xe1\xc0\x38\x9f\x0b\x33\x04\xdd\xda\x0c\x39\xa3\xd4\xef" +
+			"\xf3\xbc\xcf\x1f\x77\x4f\x9b\xed\x7a\xb7\x7f\xde\xde\x2f\xee\x38\x75\xf9\xe0\x98\xbf\x62\xcc\xc8" +
+			"\x2e\xfe\xb4\xe7\x26\x21\x2a\x60\x61\x0a\x98\x83\xc6\xe7\x1a\x19\x2d\xce\xe0\x32\xfc\xeb\x6e\x7f" +
+			"\x00\x4c\x11\xb6\x59\xee\x5e\x4e\xbe\x46\x0f\x30\x70\x2e\xbb\x7a\xca\xe5\xcb\x95\xd4\x19\xfd\xab" +
+			"\xa1\x1a\xa9\x8f\x21\x64\xa8\x42\xff\x65\xc8\x83\xe3\x31\xf9\x18\x00",
+		size: 593,
+	},
+	"main.go.tmpl": &asset{
+		name: "main.go.tmpl",
+		data: "" +
+			"\xac\x18\x5b\x6f\xdb\xbc\xf5\x59\xfa\x15\x67\x02\x36\x48\x9d\x2a\xad\x03\xf6\x62\xa0\x0f\x6d\x7a" +
+			"\xcb\x90\xb4\x46\x92\x0f\x7d\xfc\x4a\x53\xc7\x12\x17\x8a\xd4\x48\xca\x49\x66\xf8\xbf\x0f\x87\xa4" +
+			"\x64\x3b\x97\xc2\xdf\x87\xe6\x25\x22\x79\xee\xf7\xe3\x81\xf1\x5b\xd6\x22\xf4\x4c\xa8\x34\x15\xfd" +
+			"\xa0\x8d\x83\x3c\x4d\x32\xae\x95\xc3\x7b\x97\xa5\x49\xb6\x96\xac\xf5\xff\x7b\x7f\x54\x38\xfd\xab" +
+			"\x3b\xe7\x06\xfa\xb6\xce\x70\xad\x36\xf4\xe9\x44\x8f\x59\x9a\x26\x59\x2b\x5c\x37\xae\x2a\xae\xfb" +
+			"\xba\x35\x03\x7f\x8d\x5c\xdb\x07\xeb\x30\x1e\x5b\xe6\xf0\x8e\x3d\xd4\x9b\x7f\xd6\x66\x54\x01\xeb" +
+			"\x08\xc9\x0a\x33\x0e\x16\x55\x2d\x75\x6b\x46\xeb\x5f\xb5\x6e\x25\x56\xad\x96\x4c\xb5\x95\x36\xad" +
+			"\x27\xf5\xfc\xcb\x60\xb4\xd3\xab\x71\x5d\xa3\xe2\xba\x11\x2a\xde\xfc\xc7\x6a\xe5\xc5\xdb\x6e\xab" +
+			"\x4b\xdd\x8c\x12\x77\xbb\x9a\xf7\x4d\xbd\xdd\x56\x5f\x59\x4f\x27\xa1\x1c\x1a\xc5\x64\x6d\x70\xd0" +
+			"\xd9\x69\xa0\x16\xcd\x06\x4d\x96\x26\xc3\x6d\xcb\xb5\x5a\x8b\x16\x8e\xd0\x86\xdb\xb6\x0e\xf7\x01" +
+			"\xa6\x43\x26\x5d\xf7\x14\x26\xdc\x07\x18\xa9\x9f\x21\x22\x75\xa4\xd0\xa3\x33\x82\xdb\xa7\x10\xf1" +
+			"\x21\x40\x99\x51\x3d\x85\x30\xa3\xca\xd2\x64\xbb\xad\x96\xc1\xf9\xbb\x1d\x89\x2f\x38\x0e\xab\x47" +
+			"\xc0\x64\xb1\xba\x45\x55\xb7\xba\x1e\xef\x24\x5b\xd9\xfa\x10\xad\x8e\x78\xf5\xe6\x4d\x96\x16\x69" +
+			"\x5a\xd7\x10\x95\x17\x16\x5c\x87\xf1\x34\x1a\xe6\x84\x56\xa0\xd7\xfe\x32\x22\x95\x60\xd1\xc1\xea" +
+			"\x01\x28\xbc\x2c\x68\x43\xdf\xa8\x36\xc2\x68\xd5\xa3\x72\x44\x6d\xc3\x8c\x60\x2b\x89\x16\x06\x83" +
+			"\x6b\x71\x8f\x0d\xdc\x09\xd7\xc1\x76\x5b\x7d\x54\x9b\xa5\xbf\xdb\xed\x7e\x2f\xc1\x8e\xbc\x03\x66" +
+			"\x1f\x3f\x7c\xbe\x5a\x9e\xfd\xbe\xfc\x76\x75\x53\xa5\xee\x61\x98\xe4\x01\xeb\xcc\xc8\x1d\x6c\xd3" +
+			"\xed\xf6\x35\x88\x35\x54\x4b\x6d\x5d\x6b\xd0\xee\x76\x69\x32\x7d\xff\x76\x75\x41\x80\x42\xb5\x10" +
+			"\xff\x7e\x04\xf4\x45\x36\x44\x90\xd7\xa3\x91\x19\x8c\x96\xb5\xb8\xc8\x6e\x3a\x04\xc2\x89\x5a\x4e" +
+			"\x30\xd0\x30\xc7\x56\xcc\x22\x38\x4d\xfc\x15\x72\x07\x4e\x57\x19\x18\xfc\xef\x28\x0c\x36\x8b\xcc" +
+			"\x99\x11\x33\xb0\xc8\x0d\xba\x78\xfa\xe1\x65\x43\xd5\x90\x48\xa4\xc6\x92\x12\x13\x00\x46\xa1\x1c" +
+			"\xc0\x13\x91\x7c\x4e\x51\xf2\x1e\xc9\x43\x17\xc4\xd7\x87\xa7\x17\xab\xbd\x5a\x9e\x85\xa3\x01\xad" +
+			"\xaa\xec\x47\x9a\x7c\x0e\x99\xe8\x19\xfc\x8c\x7a\xcc\xd8\x53\xb9\xbc\x8e\x74\x27\x36\xef\x9a\x5e" +
+			"\xa8\xa8\xc5\x0b\x6c\x18\x81\x9c\x42\xdf\x03\x92\x75\x06\x2d\x94\xb3\x7b\xff\xc7\xe0\x2f\x27\xa6" +
+			"\x1f\x0c\x13\xea\x03\x4a\xf6\x00\x40\x35\xa6\xfa\x30\x05\xe3\xcc\xb4\x21\x90\xd7\x0d\xc1\xcc\x5c" +
+			"\xbf\xe8\x3b\x90\x5a\xb5\xc4\x95\xaa\x80\x71\xa0\xb4\x03\x83\xac\x79\x80\x35\x85\x2a\xae\xb5\x41" +
+			"\xb0\xdd\xe8\x1c\x45\x48\xa3\xef\x54\x09\x56\x83\xd4\xac\x81\x15\x93\x4c\x71\x34\x16\xac\xd3\x03" +
+			"\x58\x54\x54\x80\xbc\xc3\xd1\x3a\x4b\x92\xed\xd2\x74\xc3\x0c\x48\xdd\x9e\x85\x98\x7c\x0b\x21\xe5" +
+			"\xab\x2b\x6c\x85\x75\x68\x3e\x51\x5a\xe4\x94\x1c\xd5\x99\xee\x7b\xa6\x9a\x0b\xa1\xb0\x48\xd3\xf5" +
+			"\xa8\xb8\xaf\xd7\x79\x01\xdb\x34\xe1\xeb\x16\x16\x6f\xe1\x6f\x41\x9f\x6d\x9a\xcc\xe1\xb2\x00\x00" +
+			"\xca\x88\xe9\xbc\xdb\x95\xf4\xba\x77\xf7\xc2\xbf\xee\xcf\x01\x60\x76\xd4\x22\xa0\xcf\xe7\xf0\xbc" +
+			"\x37\xe9\x02\xe0\xcd\x3f\xe0\x55\x30\xec\x35\x72\xad\x9a\x32\x4d\x76\x69\xb2\x12\x5e\xe1\x12\xd0" +
+			"\x18\x92\x6d\x2e\x8a\xd5\x7b\xa1\x9a\x27\x3a\x95\x90\x1d\xe7\x6d\x56\x02\x5f\xb7\x45\x9a\x88\xb5" +
+			"\x27\xf1\x97\xb7\xa0\x84\x24\x65\x93\xd0\x0c\xaa\xef\xc2\x75\x1f\x8d\xd1\x26\x47\x63\x8a\xea\x13" +
+			"\x73\x4c\xe6\x05\x31\x4f\x13\x4f\x7e\xc9\x8c\xc5\xbc\x48\x53\xc2\x68\xd1\x1c\xca\x42\x56\xfe\x8a" +
+			"\x77\xf9\x6c\xfc\x3f\xcb\x89\x30\xde\x42\xd4\xb6\xba\xd0\xac\xc9\x5f\x22\xd5\xa2\x79\x9e\x54\x76" +
+			"\xae\x36\x4c\x8a\xe6\xb8\x56\x66\xc5\xa1\x21\xab\x0b\xdd\xe6\x81\x48\x31\xb3\x35\xa3\xca\x27\xdd" +
+			"\x7e\x62\xad\x17\x19\x7b\x0e\xbb\x18\x4e\x7b\x62\xf0\x2a\x2a\x7e\xb1\xa7\x0d\xaf\x82\x70\x05\x10" +
+			"\x01\x62\xa2\x4d\x88\x3d\x77\x4f\x36\x8d\xb3\x42\xf5\x9e\xf1\xdb\xd6\xe8\x51\x79\x3b\xf4\xd1\xdc" +
+			"\x31\x27\xbd\xc9\x8b\x74\xbb\x7d\x5c\x70\xcd\x30\x3b\x87\x72\x8d\xe0\xae\x70\xd0\x56\x38\x6d\x1e" +
+			"\x72\xbe\x6e\xab\x83\x92\x5c\xc2\x64\x87\x67\xd4\x35\xe8\x46\xa3\x60\xdd\xbb\xca\xab\xbb\xce\x33" +
+			"\x6e\x90\x39\x04\x33\x13\x5c\xc0\x5f\xef\x32\xcf\x30\x98\xb8\xc1\x35\x1a\x20\x23\x84\x74\x4a\xf8" +
+			"\xc7\x28\xcb\x50\x9d\x49\xed\x83\x28\x99\x78\xbd\xdd\xf3\x8a\x5e\x20\xe8\x34\x21\x42\xbb\x7c\xef" +
+			"\x9b\x7e\xce\xe2\x0f\xef\xf3\xec\xb0\x65\x66\x25\x98\xe1\x54\xe1\x4d\x24\xb2\xef\x21\xd1\x96\x8f" +
+			"\x94\xd8\x6e\x51\x5a\x0c\xb6\x7c\xc1\x8c\x64\xf8\xd8\x4d\xa8\x98\xbf\x6b\x1a\xaf\x64\xb6\xc8\xe0" +
+			"\xef\x10\xc7\xb7\xea\xdc\x69\x96\x0b\xe5\xbc\xd1\xa7\xaa\x51\x14\x69\x22\x85\x9d\x7d\xa4\xd0\x55" +
+			"\x17\x24\x96\xca\x33\xc7\x87\xac\x84\x89\xe0\xa9\x6a\x59\xc7\x8c\xaf\x9a\x37\x67\x4b\x90\x9e\x14" +
+			"\x9a\x47\x2a\xa5\x49\x6c\x37\xa7\x48\xba\xaf\x60\x24\x6c\x7b\x77\xf1\x73\x71\xf7\x84\x7f\xad\xc4" +
+			"\xbe\x2b\x9d\x20\xef\x5c\x50\x8b\x22\x22\xfd\x5c\xe0\x99\xee\xaf\x15\xd7\x9a\x0d\xf1\x23\xef\x51" +
+			"\xb0\x5c\xfb\xa1\x20\x4f\x13\x1f\x20\xd5\x59\xc7\x84\xfa\x4d\x31\xf3\x70\xae\x1c\x1a\x8e\x83\xd3" +
+			"\x26\x8f\xd5\xd3\xdf\x07\x84\xc3\xd7\x98\x9a\x25\xf4\x2f\x41\x14\x45\x79\xc4\xe0\xda\x19\x64\xfd" +
+			"\x33\x1c\xc2\xc3\x4f\x59\xbc\x04\xe2\x79\x50\x2e\xae\x18\xbf\x45\xd5\xf8\xee\x18\x46\x9e\x2a\x40" +
+			"\x93\xd9\x42\x79\x5b\xc4\x72\x42\x52\x51\xb6\x2c\x00\xc0\x0c\x65\x30\xd0\xb3\x13\xf2\x9c\xd9\xdb" +
+			"\xad\xa7\x26\x38\xee\x76\xd1\x76\xd6\x6c\x4a\x88\x5c\x49\x80\x8e\x7b\xde\xf3\xbc\x5f\x7d\xf1\xff" +
+			"\x9e\x65\xbf\x7b\x76\x18\xed\x78\xf5\xae\x69\xf2\x79\xe2\xcc\x4a\xd8\x53\x3b\xeb\x90\xdf\xa2\xf9" +
+			"\x44\xb5\xcb\x0c\xd5\x52\xa8\xb6\x28\x0e\xe7\xc6\x8e\xcf\xd2\x52\x36\x93\x7c\x24\x56\x5d\xc3\x4d" +
+			"\x87\xd3\x30\x4a\xc3\x90\xb0\x80\xd6\xb1\x95\x14\xb6\xc3\x06\x24\xfb\x9f\x90\x0f\x34\x44\xf1\xa7" +
+			"\x53\xa3\xb0\xfe\x8b\xda\x52\x9a\x70\x3e\xc7\xad\x77\xea\x07\xc1\x64\x3e\xd5\x83\xd9\xd5\xd4\x7f" +
+			"\xce\x95\x45\x3e\x1a\xcc\x8b\xa3\xeb\xe7\xc3\x2c\xc6\xcf\x99\x14\xa8\xdc\x0b\xf1\x33\x63\x3f\x8d" +
+			"\xa1\x29\x36\x5e\xc0\x3f\x35\x8d\x1a\xc1\xa4\xa4\x34\x3a\x50\xff\xe4\xf6\xc1\xf9\x9f\x69\x1f\xb4" +
+			"\x9e\x0e\x2b\x1f\x35\x71\x2b\xae\xfe\x7d\xfd\xed\xeb\x72\x45\x38\x97\xcc\xd8\x8e\xc9\x6f\x03\xf9" +
+			"\xcc\x2e\x60\x5e\x68\xab\xe3\x17\x4f\xff\x5c\x35\xa8\xdc\x02\x32\x80\x8c\x6c\xb6\x0b\xa3\x59\x7b" +
+			"\xd7\x8f\xbe\x67\x4f\xe4\xa7\xcc\xbf\x1c\xef\x29\xf7\xa7\x6b\xb2\x6e\xa4\x8a\x26\xd0\xcd\xa7\xb7" +
+			"\xcb\xf3\xcb\x8f\xdf\x85\x6c\x38\x33\x4d\x09\x41\x64\xef\x97\x23\x64\x74\x8c\xda\xd6\x94\xd0\xb1" +
+			"\x48\x4f\xd7\xd1\x0f\xc1\x0a\xa7\x27\xda\x17\xa6\x1a\x89\x26\xe7\xee\xbe\x04\xaf\x4b\x09\x9c\xff" +
+			"\xe1\x7e\x1a\x5b\xc0\x93\x92\x18\x6d\x43\x3f\x6b\x1c\x19\xa6\x98\x93\x26\x2e\xee\xf3\xea\x01\xcc" +
+			"\xa0\xdf\x0d\x7c\x22\x37\x25\x30\xbf\xf9\x3e\xf8\xfb\x41\x4b\x89\x0d\xac\xfd\xe0\xaf\x9c\x7c\xa8" +
+			"\x8e\x72\x32\xef\xc7\xfb\xc2\x33\xad\x82\x5e\x79\x56\x87\x04\x27\x83\x5d\x8a\xa6\x91\x78\xc7\x0c" +
+			"\xce\x63\x5e\x7f\x78\x99\x45\x25\xb2\x68\x88\xa2\xa0\x00\x6a\x63\x1d\xf7\x21\xe4\xf5\xd8\x97\xbc" +
+			"\x68\xbc\x45\xd8\xba\xc8\x76\x69\x92\x7c\x37\xc2\xe1\x8d\xe8\x51\x8f\x6e\x01\x6f\xfe\xf5\x64\x9e" +
+			"\x4f\xae\x90\x35\x33\xc0\x73\x10\x73\xef\xbb\x7c\xd9\x7c\xd3\xfb\xa4\xe9\xc1\x5c\xb8\x64\xae\x23" +
+			"\xdd\x26\xdf\xce\x5d\xf1\x54\x4d\x26\xda\xbf\x50\x9d\xba\x86\x4b\xec\x57\x68\x82\x83\x69\x8d\x1b" +
+			"\xb0\x01\xa1\xc0\xe0\x06\x8d\x45\xd0\xa6\x21\x97\x58\x1d\x0a\x64\xf0\x85\x07\xb4\x1e\x9d\x71\x2a" +
+			"\x39\x87\x6b\xdf\xb4\x34\x3e\x53\x50\xfd\x0e\xea\xa3\x47\x35\x1e\x3b\xda\x26\x30\x27\xb0\x06\x28" +
+			"\xb7\xa4\x47\x46\xd5\x54\x69\xd2\x4e\xbd\xc5\x8c\xaa\xfa\x6c\xf4\x38\x1c\xf6\x15\xfa\xdb\xb7\xb6" +
+			"\xc3\x9d\x8d\x26\x90\xfd\x39\x56\x05\xea\x32\x5f\x6e\x6e\x96\x79\xd8\xc1\xa3\x68\xd3\xec\x11\x0c" +
+			"\x1f\x0f\x17\xc2\x16\x11\xc3\x37\x95\xec\x40\x97\xac\x04\xdf\x03\xe5\x1e\x26\x50\x3d\xfc\x41\xc0" +
+			"\x07\xec\x44\xd2\x0f\x6b\x1e\xf6\x9b\xf2\x52\xe5\x1d\xaf\xae\xbb\xd1\xd1\x46\xbd\x5f\xdf\xaa\x73" +
+			"\xb5\xd6\x79\x76\x1d\x1a\xcf\xe3\xdf\x17\x7c\xc4\x2d\xea\x5a\x6a\xce\x64\xa7\xad\x5b\x84\x0d\xf2" +
+			"\x68\x32\x4c\xa7\x8a\xd0\x56\x57\xa3\xa2\x0a\x52\xa4\xbb\xf4\xff\x03\x00",
+		size: 5376,
+	},
+	"messages.proto.tmpl": &asset{
+		name: "messages.proto.tmpl",
+		data: "" +
+			"\x4c\x8f\xc1\x6a\x03\x21\x14\x45\xf7\x7e\xc5\x65\x3e\x40\x99\x66\x29\xf3\x09\x85\x2e\xb2\x0f\x4e" +
+			"\xf2\x2a\xd2\xd1\x27\xea\xa4\x0d\xe2\xbf\x17\x23\x03\xb3\x7c\x07\xee\xb9\xf7\xe5\x57\x28\xe6\x0f" +
+			"\x0b\xa6\x98\xb8\xf0\x65\xd2\x42\x44\x73\xff\x31\x96\xb0\xff\x6e\x66\xcd\xb2\x56\xf9\x35\x48\x6b" +
+			"\xf2\x39\x6b\x21\x9c\x8f\x9c\x0a\x26\xcb\x6c\x37\x52\xef\xe4\xba\x7f\xab\xe2\x3c\xe5\x62\x7c\x94" +
+			"\x6f\xd4\x5d\x1c\x8b\xe3\x00\xcb\xb7\xc3\xba\x60\xaa\x55\x7e\xf2\x63\xdf\xa8\xb5\x11\x56\x96\x82" +
+			"\xb2\xac\x46\xa3\x3a\x37\xaa\xe7\xac\xcf\x77\x5c\xbb\xd6\x53\xce\x5d\x56\xab\xbc\xbe\x22\xb5\x86" +
+			"\x2a\x00\x20\x97\xe4\x82\x85\x7b\x60\xc1\xac\xcf\x28\x18\xdf\xbb\x3f\x06\x1c\xcb\xe5\xb1\x5c\x5e" +
+			"\x8f\xe5\xb8\x27\x32\x85\x6e\xfd\x15\x2c\xb8\x68\xd1\xc4\xff\x00",
+		size: 292,
+	},
+	"model.go.tmpl": &asset{
+		name: "model.go.tmpl",
+		data: "" +
+			"\x5c\x90\x4d\x6a\x43\x31\x0c\x84\xd7\x4f\xa7\x10\x5e\x25\x1b\xe7\x04\x5d\xb5\x29\x74\xf3\x16\x25" +
+			"\x17\x70\xfc\xf4\x12\xd3\xd8\x32\xb2\xdc\x12\x8c\xef\x5e\x9c\xf4\x8f\x6a\x25\x86\x99\x6f\x60\xb2" +
+			"\xf3\x6f\xee\x44\x28\x94\x19\x20\xc4\xcc\xa2\xb8\x81\xc9\x90\x08\x4b\x31\x30\x19\x0d\x91\x0c\x6c" +
+			"\x01\x76\x3b\xdc\xdf\x54\xd4\xb3\x53\xf4\x2e\xe1\x71\x24\xb5\x4a\xa2\x05\x57\xe1\x88\x7a\xbe\xb3" +
+			"\x2c\xbc\x3b\x19\xa0\xbd\x48\x6b\xf6\x70\xcd\xd4\xfb\xcc\xfa\xcc\x35\x2d\xf8\x80\x77\xbc\x9d\xe9" +
+			"\x63\x63\x5a\xb3\xaf\x54\xb8\x8a\xa7\xde\xd1\x73\xbd\x2c\x98\x58\x07\x7c\x1d\x76\xb3\xfd\x6a\xff" +
+			"\x01\xe1\x42\xc5\x4b\x38\x52\xb9\x15\x46\x5e\xe8\x82\x2b\x0b\xba\x5f\x8f\x05\xbd\x66\xfa\x93\x29" +
+			"\x2a\xd5\x2b\x36\x98\x5e\x9e\xf0\xfb\x8a\x4a\x48\x27\x98\x66\x17\xe9\x9f\xf4\x28\xe4\x94\x0e\x21" +
+			"\x12\x8e\x09\xec\xf8\xa0\xc3\xe7\x00",
+		size: 306,
+	},
+	"repo_memory.go.tmpl": &asset{
+		name: "repo_memory.go.tmpl",
+		data: "" +
+			"\xac\x55\xdf\x8b\xdb\x38\x10\x7e\xb6\xfe\x8a\x69\xa0\xc5\x3e\x5c\xc5\xcb\x2d\x4b\x30\xec\xcb\x35" +
+			"\xbd\x23\xd0\xee\x43\xda\xe5\x1e\x82\x59\x54\x7b\xbc\xab\x26\x96\xcc\x48\xde\x24\xe4\xfc\xbf\x1f" +
+			"\x92\xf3\xc3\xf6\xee\xd2\x97\x86\x80\xa4\x19\x69\xe6\x9b\x6f\x3e\xc9\xb5\xc8\xd7\xe2\x11\x81\xb0" +
+			"\xd6\x8c\xc9\xaa\xd6\x64\x21\x64\xc1\x24\xd7\xca\xe2\xce\x4e\xdc\x94\xf6\xb5\xd5\x53\x12\xaa\x70" +
+			"\xcb\xb2\xf2\x56\xa3\xa9\x1b\xf7\x2a\x77\xa3\x95\x15\x4e\x58\xc4\xd8\x74\x0a\x4b\xac\xb5\x91\x56" +
+			"\xd3\x1e\xa4\x81\xc6\x60\x01\x56\x83\xb1\x9a\x10\x84\x2a\xa0\x44\x9b\x3f\xc1\xe1\xc0\x97\x68\x74" +
+			"\x43\x39\x9a\xb6\x05\xa9\xa0\xc2\x4a\xd3\x9e\x33\xbb\xaf\xb1\x1f\xc4\x58\x6a\x72\x0b\x07\x16\x54" +
+			"\x0d\x00\x80\xcb\xc9\x97\xff\x7e\x6d\x2c\xee\x58\x30\x8a\x53\x89\x7a\x65\x2c\x49\xf5\x98\x1d\x0e" +
+			"\xfc\xfb\xbe\xc6\xb6\x65\xad\xc7\x75\x87\xdb\x5e\xd4\x9c\x50\x58\x34\x20\x40\xe1\x36\x06\xa9\x3e" +
+			"\x76\xf9\x81\xce\x7b\x38\x2b\x1b\x95\x0f\xcf\x85\x11\xfc\x71\x59\x39\x50\x84\xb6\x21\x05\x1f\x2e" +
+			"\xd6\x03\x0b\x46\xb0\xd2\x57\x71\x1d\xda\x98\x05\x27\x74\x9f\x3c\x9e\xb3\x6f\x88\xaf\xcf\x56\x47" +
+			"\x96\x7d\xc2\x97\x48\x43\xea\x83\x8b\xc6\x31\xc3\x07\x38\x36\x96\x7f\xea\xc6\x18\x94\xa8\x10\x3a" +
+			"\x60\x11\x84\xe7\xad\x31\x20\x91\xa6\xc8\x15\xf8\x2c\x08\x64\x01\xab\xab\x9b\xec\xc7\xde\x22\x0b" +
+			"\x1e\xbc\x17\xd2\x5b\x70\xaa\xe0\x4b\x14\x45\x28\x8b\x55\x9a\x45\x2c\x90\xa5\xf7\xbd\xbb\x05\x25" +
+			"\x37\xee\xf4\x89\x9f\x7e\xd5\x50\x56\x96\x7f\x76\x09\xca\x70\xf2\x88\x0a\x49\x58\x1c\xd5\xb8\x98" +
+			"\xa7\xf0\x7e\x3b\xf1\xa9\x22\x47\x53\x30\x9d\xc2\x57\x41\x6b\x5f\xfa\x62\x0e\xc2\x71\xf3\x8c\x64" +
+			"\xa4\x56\x70\x0d\xf7\xf7\x8b\x39\x67\x81\x2c\x56\x37\x19\xdc\x82\x1f\x3f\x24\xbb\xa4\x84\xff\x20" +
+			"\xd9\x5d\x27\xde\x35\x3b\xba\x66\xce\xf5\x67\xe7\x9a\x25\x6c\xa0\xa2\xb6\x75\x95\x5d\xe0\xb2\x20" +
+			"\x70\x58\x4e\x3f\x07\xfd\x5b\x4d\x52\xd9\x32\x9c\xbc\xdf\x7d\xec\xfd\x27\xb1\x8b\x9d\xa4\xd7\x99" +
+			"\x9f\x5c\xa7\x37\xdd\xe4\x26\x9d\x75\x93\x59\x7a\x95\x74\xb3\xab\x24\xcd\xa2\x98\x05\xc1\x9d\xa8" +
+			"\xf0\x14\xdc\xf5\xc2\xd9\xba\xb6\x7d\x97\xce\xe3\x2e\x16\xbf\xd3\xdb\x30\xf2\x5a\x61\x01\xf1\xaa" +
+			"\xe1\x5f\x74\xbe\x0e\x23\x16\x14\x58\x22\x81\x37\xdd\xab\xcd\xd1\x48\x7c\x28\xbe\xd5\xa0\x38\xbe" +
+			"\x98\x3b\x12\x06\x36\xc6\x7a\x5d\xba\x98\x63\xd7\xc3\xa3\x3c\xff\x41\x7b\xd1\x26\xa1\x25\x89\xcf" +
+			"\x68\x7c\x2f\x06\x67\x60\x2b\xed\x93\x37\x3f\xca\x67\x54\xb0\x98\xbf\xa1\xcd\x7e\xc0\xd7\x84\x29" +
+			"\x8b\x5f\xc8\xd2\x57\xbd\x7c\x85\x89\xe5\x99\x8a\x51\x5f\x63\xd0\x6b\xaf\xda\x31\x41\xb2\xc8\xbc" +
+			"\x72\xdf\xe9\xf5\x9b\x92\xfd\x4c\x74\x5e\xdf\x69\xfb\xb7\x6e\x54\x71\xec\xc8\x2f\xa8\xfb\x22\xcd" +
+			"\xa9\x54\xd3\x91\xd7\x90\x32\x20\x36\x9b\xd1\x23\x18\x83\xde\x14\x68\x2c\x94\x92\x8c\x7d\x83\xb7" +
+			"\x61\xb4\x70\xc4\x5b\x04\xe1\x2a\xfb\x2d\x74\x99\xee\x1e\x54\x62\x8d\xc3\x90\x49\x0c\x1b\x54\xe1" +
+			"\x98\xc4\x28\x62\x41\xa9\x09\x1e\x62\x78\x71\x99\x48\x28\xf7\x95\x19\x9d\x80\x97\xef\x24\xdc\x82" +
+			"\xa8\x6b\x54\x45\x38\x66\x66\x10\xb3\x7b\x0f\xdc\x37\x88\x7f\xdb\xc8\x1c\x5f\xec\x76\xcc\x85\x32" +
+			"\x86\x9f\x20\x95\x8d\xe0\x87\xd6\xe3\xb7\x68\xd0\xfe\x8c\x5f\xae\x1c\xff\x0b\x4b\x4d\xe3\x90\xab" +
+			"\x9f\xfd\x3d\x2e\x7f\xf4\x6a\xe7\xcd\xa5\xf5\xff\x0f\x00",
+		size: 1885,
+	},
+	"repo_postgres.go.tmpl": &asset{
+		name: "repo_postgres.go.tmpl",
+		data: "" +
+			"\xb4\x58\x5d\x6f\xe3\xba\x11\x7d\x96\x7e\xc5\x5c\x01\xdd\x4a\x5b\x47\x42\x81\xbe\x34\x45\x1e\x6e" +
+			"\xe2\xec\xd6\x40\xd6\xd9\xeb\x6c\xb0\x05\x2e\x2e\x16\xb4\x34\x92\xd9\x50\xa4\x42\x52\xf9\x80\xe1" +
+			"\xff\x5e\x0c\x29\xc9\x96\x1d\x67\x37\x5b\xdc\xa7\x58\xd4\x70\x78\x78\xe6\xcc\x87\xd2\xb0\xfc\x8e" +
+			"\x55\x08\x1a\x1b\x15\x86\xbc\x6e\x94\xb6\x10\x87\x41\x94\x2b\x69\xf1\xc9\x46\x61\x10\x15\xcc\xb2" +
+			"\x25\x33\x98\x99\x7b\x41\xcf\xa8\xb5\xd2\x86\x7e\x95\xb5\x33\x90\x68\xb3\x56\x8b\x28\x0c\x83\xa8" +
+			"\xe2\x76\xd5\x2e\xd3\x5c\xd5\xd9\x27\x66\x2c\xea\x9a\xcb\xc2\x64\xe6\xbe\xe5\x5a\xa3\x88\xc6\x26" +
+			"\x95\x12\x4c\x56\x27\x35\xaf\x34\xb3\x98\xf5\x7f\x1f\xfe\xf1\xa3\x76\xd9\x00\xae\x51\xc6\x56\x1a" +
+			"\x09\xd7\x92\x4b\x5a\x86\x1f\xf3\x60\x54\xab\x73\xcc\x2a\xf5\xad\xdb\xb7\x77\xf6\x7f\x59\x7e\x97" +
+			"\x67\x4d\x65\x9f\x1b\x3c\xf2\xea\xe9\x10\xf1\xee\xab\xcc\xd8\x42\xf0\xe5\x9e\x85\x68\x25\x3b\x29" +
+			"\xda\x5c\x28\x93\x71\x69\xac\x6e\x6b\x94\x16\x8b\x8e\xe5\x1f\xb3\xcc\x54\x83\xd2\x6a\x96\x73\x59" +
+			"\xed\xed\x32\x5c\xb7\x8d\x41\x99\x09\x55\xe9\xd6\xb8\xe8\xac\xd7\xe9\x27\x55\xb4\x02\x37\x9b\x2c" +
+			"\xaf\x8b\x6c\xbd\x4e\xe7\xac\xa6\x27\x2e\x2d\x6a\xc9\x44\x46\x4a\xe8\xe8\xe1\x4a\x9a\x68\x6f\x57" +
+			"\x73\x57\x91\x43\xba\x99\x50\x55\x14\x26\x61\x98\x65\x95\x3a\xad\x50\x22\x31\x0a\x95\x3a\xe9\xf9" +
+			"\x3f\x69\xee\x2a\xd8\xba\x82\x93\x46\x63\xc9\x9f\x46\x4b\x52\xd5\x68\x99\x37\xe7\x95\x54\x1a\x61" +
+			"\xd8\xae\x20\xdd\x41\x92\x75\xeb\x69\x35\x5e\x27\x00\xb0\xc0\x46\x19\x6e\x95\x7e\x06\x6e\xa0\x35" +
+			"\x58\x80\x55\x60\x2c\xf9\x63\xb2\x80\x12\x6d\xbe\x82\xf5\x3a\x5d\xa0\x8f\xb7\xd9\x6c\xa0\xd4\xaa" +
+			"\x06\x06\xbd\x84\xd2\x90\x42\xbc\xeb\x8a\xa8\xce\x2d\xac\xc3\xa0\x58\xc2\x7b\x73\x2f\xd2\xe9\x79" +
+			"\x18\x98\x25\xf4\x72\x4e\x6f\x2c\xb3\x48\xd1\x38\x6f\xb9\x28\x50\x7f\x79\x6e\x30\xdc\x38\x48\x73" +
+			"\x7c\xdc\x71\x95\x6b\x64\x16\x0d\x30\x90\xf8\x38\x19\xce\x3c\x59\xb2\xfc\x0e\x0b\xd0\x83\xe5\x84" +
+			"\xf6\x76\xb7\x93\x15\xd8\x15\x0e\xc6\x74\x25\x7a\x16\xe4\xc9\x82\xc9\x57\x58\xb3\x34\x2c\x5b\x99" +
+			"\x8f\x4f\x8b\x8b\xe5\xed\xe2\x8a\xe0\x73\x59\x4d\x40\xa8\xaa\x42\x0d\xef\xbd\x10\xd2\x2b\xf7\x98" +
+			"\x40\xfc\x7e\xbb\x63\x02\x2e\xab\x13\xba\x6b\xc3\xb4\xc1\xc2\xad\xc0\xe9\x19\xb4\x5a\xa4\x9f\x69" +
+			"\xc9\x7b\x4d\xc2\x80\x97\xee\xdd\x2f\x67\x20\xb9\xa0\x1d\x81\x46\xdb\x6a\x49\x8f\x13\x28\x6b\x9b" +
+			"\x5e\x92\xb3\x32\x8e\xb8\x7c\x60\x82\x17\xdb\x1b\xdc\x2e\xae\x4e\xe1\x2f\x8f\x91\xf3\x9e\x84\xc1" +
+			"\x26\x0c\x83\x2c\x83\xaf\x4a\xdf\x01\xd3\xaa\x95\x05\x7c\xfe\xf8\x1f\x90\xca\x02\xc1\x20\x06\x6e" +
+			"\x17\x57\x06\x1e\xb9\x5d\xa9\xd6\x02\x83\x95\x32\x16\x72\xa5\x35\xe6\x56\x3c\xa7\x6e\xfb\xca\xda" +
+			"\xc6\x9c\x66\xd9\x0b\xf9\x97\x2b\x29\x33\x6e\x4c\x8b\x26\xfb\xfb\x3f\x1d\x76\x7f\xbf\xf4\xdf\xca" +
+			"\x58\xc9\x6a\x8c\x13\x38\x3b\x83\x28\x72\x17\xd9\x79\x07\x67\x10\x09\x95\x33\x41\x27\x46\xf0\xb7" +
+			"\xdd\x7d\x1e\x38\xf9\xbe\x50\xb2\xe4\xd5\x40\x56\x53\x3d\x79\xb2\xfc\x7a\xdc\xed\xb9\x71\x91\x88" +
+			"\x93\x37\x91\x97\xab\x56\x14\xf2\xaf\x9e\x09\x84\xe9\x39\x4c\x6f\xe6\x07\xec\x6d\x41\x74\x81\x05" +
+			"\x87\x42\xa8\x2a\x9d\xe3\x63\xec\x63\x9f\x74\x86\x37\xd6\xa1\xf4\xf5\x28\x5d\x60\xc5\x8d\x45\x7d" +
+			"\x31\xb8\x88\xb7\xde\x92\x30\x28\xf4\x03\x59\xef\x95\x9c\xf4\xab\x66\xcd\x54\xf3\x07\xd4\x71\x18" +
+			"\x04\x9d\xaf\x8f\x68\xa7\x58\xb2\x56\xd8\xee\x55\x32\x09\x83\xe0\x60\x2b\xb7\xab\x2f\x9a\xe5\xa8" +
+			"\xe3\x9d\xc2\x45\x40\xbb\xd5\x92\x09\x83\xc9\xd1\xbd\xd7\x35\xb7\xbf\xea\xca\x1c\xf7\x7e\xdd\x98" +
+			"\xcb\xa7\x5c\xb4\x05\x16\xf1\xfe\xfb\xeb\xe6\xe6\xb7\xab\x85\x7a\x34\x73\x7c\xb2\xe4\x21\x09\x83" +
+			"\x5c\xe6\x56\x0f\xe1\x2b\xf4\x43\x7a\xdd\x20\x51\x20\x31\xb7\x4a\xc7\x1d\x6b\x6f\x0a\x1c\x5d\x0d" +
+			"\x0a\x47\xc3\x41\xb8\xcc\xbd\x98\x9e\xbb\x18\x38\x40\x28\xa7\xe7\xb1\xc3\xb0\x3d\xc1\xbd\x9b\x9e" +
+			"\xa7\x9f\x9d\x64\xfe\xb5\x7f\xea\xb7\xc1\xe0\x42\x28\x83\x71\xf2\xaa\x84\xdc\x3d\xfa\xc2\xd1\xa7" +
+			"\xe1\x21\x2a\x57\x13\x07\x1a\xfa\x4a\x4b\x84\xce\xa4\xb1\x4c\xe6\x18\xf7\x8b\x7d\x05\x8d\xb7\x25" +
+			"\x38\xfd\xd5\x18\xb4\xd4\x49\x28\x32\xb0\xff\xe2\x8d\xb2\xa7\x4a\x49\x89\xdf\x1d\xb8\x75\x77\x00" +
+			"\xdb\x32\x5d\xa1\xdd\x26\x5f\x37\x02\x8c\x71\x3b\xae\x26\x54\x76\xe3\xc1\xa0\x93\xf8\xcf\xe1\xea" +
+			"\xbd\xbc\x02\xac\x1e\x30\x79\x1b\x24\x89\x8f\x50\x45\xfd\xb0\x31\x81\x9e\xfc\x68\x18\x61\x26\xe0" +
+			"\x6f\xf6\x73\xf8\xba\x23\x0f\xb5\xe7\xc5\x55\xa7\xb7\x4d\x7c\xe0\xf9\xdd\x3b\xf8\xc5\x0f\x76\xe9" +
+			"\xcc\xc4\xa8\xf5\x64\x80\x7e\xa9\xf5\x5c\x5d\xac\x98\xac\x30\x79\x15\xc1\xb6\x6b\xb5\xcd\xc1\xe1" +
+			"\xdd\xa6\x77\xdb\x9e\x43\xae\x8a\xe5\xa9\x17\x33\xe5\xb3\x59\x9e\x1e\xef\xac\xe9\x67\xc1\x72\x5c" +
+			"\x29\xfa\xfd\x41\xe9\x9a\xd9\x78\xb0\x9d\x2a\x21\x98\x4e\xd2\x45\x2b\x89\x64\x1f\x72\x4a\xf0\xcd" +
+			"\x84\x50\x76\x0d\xd9\xa5\x0b\x68\x14\xc8\x0c\x1a\x97\x10\xba\x9f\x06\x40\x95\x6e\x61\x8b\xae\xeb" +
+			"\xab\xb1\x86\x9d\x36\x99\x40\x97\x73\xbe\x5d\x12\x1b\xdd\xbd\x74\x5a\x2c\x87\x84\xf4\xe7\x51\xfe" +
+			"\x42\xbe\xc2\xfc\x8e\x0e\x63\x76\xdc\xcb\x73\x26\x61\x49\x08\x58\xbe\xc2\xe2\xc8\x69\xae\x04\xe4" +
+			"\xf6\x09\xba\x71\x3c\xbd\xf0\x7f\x8f\x9c\x4f\xe6\x9d\x05\xed\xea\x81\x10\x95\x06\xbc\xa1\xbf\xb7" +
+			"\xb1\xcc\x72\x63\x79\x3e\x5c\x7c\x8b\xcb\x97\x0c\xae\x24\x34\x4a\x89\x23\xc0\x9c\xcb\x38\x01\x3f" +
+			"\x13\xf9\x03\xf6\xc0\x74\x26\x3d\xf9\x6e\xfe\x59\xaf\x53\x9a\x90\x36\x9b\xf1\x3c\xb4\x3b\x98\x6d" +
+			"\x36\xc0\x65\x17\x9c\xef\xc5\x62\xec\xf3\x25\xa2\x26\x40\x2d\xbe\x9b\x84\x12\x88\x07\xe3\xdd\x81" +
+			"\x87\xdf\x53\xa6\xea\xd4\x2c\xd3\x99\x34\xa8\x2d\xf5\xb5\x68\x3c\x2c\x46\xd4\x2e\xd2\x1b\xb4\x9f" +
+			"\x58\x13\xd7\xac\xf9\xdd\xbb\xfc\xc3\xcd\xcd\x25\xcb\x71\xbd\x21\x3d\x47\x74\x5c\x74\xea\x4e\x25" +
+			"\xfd\x25\xe9\x4d\x5b\x96\xfc\xc9\x79\x5c\x5c\x7e\xb9\x5d\xcc\x67\xf3\x8f\xc0\x8b\x49\xc7\xc0\x37" +
+			"\xcb\x6b\x74\xce\xc3\x30\x18\xd3\x70\x7a\x06\x03\x5c\xf2\x4d\xf5\x75\xeb\xd9\xa7\x33\xb5\xe7\xfb" +
+			"\xf4\xb7\x16\xf5\xf3\x42\x3d\xee\xc6\x3e\xbd\xc9\x99\x8c\xdf\x8d\x5c\xa6\xb3\xe9\x04\xf6\x96\x3c" +
+			"\x89\x5f\x78\x8d\xaf\x55\x9b\x2d\x90\xcd\x0b\x55\x07\x0f\x83\x78\x2c\xff\x47\x46\xbb\xd9\xf9\x11" +
+			"\xed\x56\x1d\x1a\xad\xe6\xf8\xd0\x65\xe9\x98\x16\x1a\x05\xdd\x72\xc5\x1f\x50\xc2\x6c\x7a\x44\x1d" +
+			"\xbb\x0e\x5f\x96\x06\x2f\xbe\x23\x8c\x07\xa6\xa1\x9c\x4d\xc1\x7f\xfc\xa5\xb7\xb7\xb3\xe9\x40\x7b" +
+			"\x39\x9b\x92\x1c\x62\x5e\xbc\xc8\x5b\x96\xc1\x6c\xda\xe5\x3d\xd3\xe8\x26\x5a\xda\x6f\x28\xf1\xe9" +
+			"\xa1\x66\xf4\x45\xc2\xe4\xf3\xf8\x7a\xe9\x11\xce\x2f\xb5\x1e\x9e\xe7\xca\x7e\xa0\x51\xd9\xa9\x60" +
+			"\xab\xdd\x1b\x14\x98\x7b\xed\xf2\x22\x9a\x0c\x6a\x74\xbf\xf6\xd5\x96\x7e\xd0\xaa\x3e\xaa\xf3\xaf" +
+			"\x2b\xd4\x48\x6f\x87\x12\x7b\x79\xbf\x26\xaf\xa7\x74\xef\x4d\x27\x57\xa2\x67\x1c\x9b\x01\x62\xdf" +
+			"\x6c\x5e\xd3\x66\x18\x04\x87\xf2\x3c\x5c\x9c\x7b\xc1\x07\x47\x75\xeb\x07\xb9\xc3\x10\xf8\x95\xdd" +
+			"\x8e\x46\xd5\xca\x75\x33\x1a\x02\x7d\x2f\x7b\x03\xd9\xc4\xf6\x77\xf3\xa1\x57\xee\xff\x99\x0d\x57" +
+			"\xdc\xf4\xea\x35\x9b\xcd\x50\xbb\x99\x10\x7b\x1f\xb1\x13\xa0\x8e\x68\x2c\x94\x5c\x1b\x7b\x24\x15" +
+			"\xc6\xde\x5e\x6e\x28\xf1\x37\xf8\xfd\x8f\x71\x16\xec\x64\xc2\x9f\xa2\xb2\x6b\x5d\xa0\x3e\x7f\x8e" +
+			"\x5f\xda\x49\x1c\xa9\x47\x33\xcc\x51\x9d\x90\x46\xdd\xed\x0d\xc3\x91\xe0\xc6\xee\x7f\xfe\xdf\x93" +
+			"\xc3\xbd\xc8\x04\x05\x96\xa8\x81\x58\x8c\xbd\x40\xf2\x4b\x0f\x80\xd0\xec\x4c\xdc\xfd\xac\x3e\x4c" +
+			"\x4f\xf9\xe5\x18\x49\x97\x00\xa3\x5a\x29\x94\xfb\xa2\xdd\xc3\x41\x9e\x3b\x18\xe4\x24\xf1\x4a\xdb" +
+			"\xc4\x2f\xa4\x18\x99\xef\x44\x29\x0c\x4a\xa5\x3d\x32\xfa\xa4\xe9\x10\xbf\x9a\x96\x1d\x2c\xb7\xa7" +
+			"\xcf\xc2\x97\xd3\xf0\x58\x1e\xbe\x9a\x88\xc1\x0e\x37\xbb\x64\x1c\x8b\x8b\xa1\x6a\xb8\xc7\x89\xa7" +
+			"\x64\x14\x18\x97\x7a\x7b\x3c\x9c\x01\x6b\x1a\x94\x45\xbc\x9f\x10\x23\x57\xa3\xd1\xd7\xdd\xfa\x52" +
+			"\xeb\xf8\x6d\xff\xc4\xb0\xd8\x8d\xb5\x07\x20\xcd\x0f\x24\xb6\xd9\x66\xf6\xff\x06\x00",
+		size: 5486,
+	},
+	"server.go.tmpl": &asset{
+		name: "server.go.tmpl",
+		data: "" +
+			"\xd4\x96\xc1\x6e\xe3\x36\x10\x86\xcf\xe2\x53\x4c\x75\x28\xac\x40\x20\xd1\xab\x01\x1f\x8a\x6c\xbb" +
+			"\x30\xd0\x2e\x82\x34\xb7\xc5\xa2\xa0\xa9\x91\x42\x54\x22\xb5\x24\x95\x6c\x20\xe8\xdd\x8b\xa1\x14" +
+			"\xaf\xa4\x78\x6d\x6f\x8b\x1e\xea\x8b\x21\x8a\x33\xfc\xf9\xcf\x37\x22\x5b\xa9\xfe\x92\x15\x82\x47" +
+			"\xf7\x84\x8e\x31\xdd\xb4\xd6\x05\xd8\xb0\x24\x55\xd6\x04\xfc\x12\x52\x96\xa4\xe8\x9c\x75\x3e\x65" +
+			"\x2c\x49\x2b\x1d\x1e\xbb\x03\x57\xb6\x11\x95\xad\xa5\xa9\x44\xeb\x6c\xb0\x87\xae\x14\x6d\x78\x69" +
+			"\xd1\xa7\xcb\x49\x5e\xbb\xae\xf5\x68\x44\x6d\x2b\xd7\x8d\x6f\xad\xad\x6a\xe4\x63\x38\xb7\xae\x12" +
+			"\x95\x6b\x95\x50\xb6\xc0\x73\xef\x7d\x90\xa1\x1b\x45\xf4\x3d\xff\xdd\x16\x5d\x8d\xc3\x20\x54\x53" +
+			"\x88\xbe\xe7\x1f\x64\x43\x4f\xda\x04\x74\x46\xd6\xc2\x61\x6b\x53\x96\xf4\x3d\xbf\x1b\xb7\x38\x0c" +
+			"\xb4\x47\xad\xb0\x3d\xc0\x22\x41\xd4\x2f\x2a\x34\xa2\xb2\xa2\x7b\xae\xe5\xc1\x8b\x79\x98\x98\xe2" +
+			"\xc4\xd3\x4f\xab\x84\xdf\x9d\x89\x32\x64\x8c\x91\x4f\x64\xb1\x10\x70\x8f\xad\xf5\x3a\x58\xf7\x02" +
+			"\xb2\xae\xed\xb3\x07\x1f\xac\xd3\xa6\x02\x69\x0a\x28\x31\xa8\x47\x7a\xb0\x25\xf4\x3d\xbf\x47\x6f" +
+			"\x3b\xa7\xd0\x0f\x03\x67\xc9\x2c\x34\xee\xba\x94\x0a\xa1\x67\x49\x72\xeb\x50\x06\xec\x7b\xfe\xf0" +
+			"\xd2\xe2\x30\x6c\x54\xf8\x02\x53\x31\xf9\xed\xf8\x9f\x83\x91\x0d\x82\x0f\xb4\x54\x06\x1b\x72\x8b" +
+			"\x1f\x23\x72\x88\x05\xcf\x58\x92\xbc\xc7\x70\x21\x91\x2e\xae\x48\xf3\x9b\xf6\xaf\x79\xfc\xe9\x44" +
+			"\x19\x6c\x3e\x7e\xfa\x56\xfc\xc0\xa2\x59\x7f\x44\x48\x41\x37\x6d\x8d\x0d\x9a\xe0\x21\x3c\x22\x54" +
+			"\xf7\x77\xb7\x13\xbf\x5f\x8d\x60\xc9\x34\xd9\x07\xd7\xa9\x10\x7d\x21\xc3\x00\x66\x96\x93\x30\x5b" +
+			"\x55\xe8\xe0\x66\xa4\x93\x8f\x8f\xb4\x60\xc6\x98\x10\xb0\xb2\x12\x54\x7c\xf6\x20\xc1\xe0\xf3\xbc" +
+			"\x24\xc3\x00\xda\x44\x39\xee\x98\x9e\xb3\xb2\x33\x0a\x36\x1e\x6e\x46\x31\x19\x5c\x55\x1a\x87\x9f" +
+			"\xe1\xe6\x24\xb8\x7c\x15\x7f\x8f\x9f\x3b\xf4\xe4\xdd\xb5\xf3\x7d\x6b\x8d\xc7\x57\x67\xc9\x16\x5d" +
+			"\xd2\x82\xfc\x3d\x06\x6a\xa1\x4d\x06\xbb\x1d\xa4\x69\x34\xcc\x61\xe8\x9c\x01\xa3\xeb\x1c\xc6\xee" +
+			"\xe3\xbf\x50\xdc\x26\xb6\x2a\xdf\x9b\x27\x59\xeb\xe2\x67\x57\x75\x54\x8d\x1c\xd2\x08\x55\xd3\xf9" +
+			"\x00\x07\x04\xdf\xa2\xd2\xa5\xc6\x22\x9d\x2a\xb8\xb0\x2b\x4a\x80\xed\x0e\x3c\xa7\x82\xf0\x13\xce" +
+			"\xe4\x4b\x61\x59\xd4\x4a\x51\x3f\xec\x48\xd3\x75\x12\xc7\xcf\x41\x5c\x6d\x7a\x93\x4d\x72\xda\xc3" +
+			"\x92\x34\x12\xb3\x90\xf8\x60\xef\xa8\xa1\x37\x8b\xc1\x8b\x2a\xd0\xb9\x31\xff\x34\xf8\xe3\x77\x55" +
+			"\x86\xb2\x1d\x07\xb7\x30\xd7\xc8\x92\x21\xa7\x25\xd8\x10\xd1\x9c\x77\x26\x8c\x6b\x8d\xfd\xb0\xa4" +
+			"\x52\x59\xe7\x62\xee\x82\xbe\x23\xc1\xc6\x29\xfb\x77\x39\xe8\x12\x4a\xdb\x99\xe2\x04\xa5\x97\x9b" +
+			"\xfe\x0c\xa2\xf3\xe0\x8b\x7c\x2e\x27\xbf\x85\xf3\x2c\x32\x6b\x99\x47\x5e\x8e\xa3\xfb\xe2\x1b\xd8" +
+			"\x8c\x23\xd6\x79\xbe\xf7\x1b\x74\x2e\x8f\x6d\x4b\x80\x1c\x63\x3f\xd8\xf0\x2b\xf9\x13\x75\x5c\x04" +
+			"\xed\x75\x76\x0e\xe9\x42\x33\x28\xdb\xd5\x05\x18\x1b\x5b\x22\x1a\x4e\xed\x90\x0c\xff\x67\x76\x4f" +
+			"\x55\xed\x7a\x70\x97\x67\xc1\x11\x5d\x59\xd7\x6b\x7c\xe3\x39\xf7\x86\xce\xcb\x67\xc9\x59\x3e\x97" +
+			"\xe1\x17\x09\x5d\x4f\x3f\xcb\xa8\x7f\x0b\xe9\x5b\xb5\xff\xd9\x77\x8c\xdc\xdc\xee\xe0\xe3\xa7\x9b" +
+			"\xe5\x25\xe5\xeb\x89\xda\x0f\x2c\x29\xad\x83\x3f\xf3\xd5\x57\x62\xbb\x03\x27\x4d\xb5\x76\x3f\x4a" +
+			"\xfb\xc7\xac\x9d\xd8\xe6\x5b\xda\x92\x61\xbe\x04\xad\xb9\x03\xd9\xb6\x68\x8a\xcd\x7c\x34\x9f\x13" +
+			"\x95\x5d\x03\xe9\xe9\xc2\xcd\x30\xf5\x0b\x4e\xfd\x0a\xd4\x88\xdd\xe5\x6d\xc2\xf2\xca\xb2\xe6\x68" +
+			"\x6e\xfe\x9c\x19\x15\x8e\x56\x8e\xb7\x66\xfe\xa0\x1b\xf4\x41\x36\xed\x89\x45\xa6\xc3\x82\xa6\xfc" +
+			"\x3b\x78\xd2\x52\xea\x1a\x0b\x3a\x06\x94\x35\x4f\xe8\xc2\x74\xa3\x81\xa0\x1b\xa4\xe1\x78\x85\x05" +
+			"\x92\x94\x9e\x71\x79\xc1\x14\x4b\x92\x7d\xb1\x85\xd7\xdf\x52\xf9\xfe\x5d\xce\x92\x84\x0e\xf0\xed" +
+			"\xc9\xf7\xf4\x26\x3f\xde\x59\x69\x87\x5b\x50\x61\x5e\x89\xbf\x07\x00",
+		size: 3234,
+	},
+	"server_test.go.tmpl": &asset{
+		name: "server_test.go.tmpl",
+		data: "" +
+			"\xac\x96\xd1\x4f\xa5\x38\x14\xc6\x9f\xe9\x5f\x71\x86\x44\x03\xb3\x58\xe2\xeb\x4d\x7c\xd8\xd5\x99" +
+			"\xcd\x4d\x66\xcd\xc6\xf5\xcd\x18\x53\xe1\xd0\x6d\x2e\xb4\xd8\x96\x3b\x1a\xc2\xff\xbe\x69\x0b\xee" +
+			"\x05\x87\x3b\x63\xa2\x2f\x5e\x4e\x0f\x1f\xdf\xf7\xa3\x3d\xa1\x65\xc5\x8e\x71\x04\x83\x7a\x8f\xfa" +
+			"\xc1\xa2\xb1\x84\x88\xa6\x55\xda\x42\x42\xa2\xb8\x50\xd2\xe2\xb3\x8d\x49\x14\xbb\x35\x21\xb9\xff" +
+			"\x29\x1a\x8c\x09\x89\x62\x2e\xec\xbf\xdd\x23\x2d\x54\x93\x1b\xa1\xbb\xd6\xa0\xcc\x6b\xc5\x75\x67" +
+			"\x5c\x1b\x57\x8a\xd7\x48\xb9\xaa\x99\xe4\x54\x69\x9e\x73\xdd\x16\x79\xa1\x4a\x3c\xb6\x6e\x2c\xb3" +
+			"\x4e\x80\x44\x71\xdf\xd3\xbf\x54\xd9\xd5\x38\x0c\x79\xd1\x94\x79\xdf\xd3\x6b\xd6\xb8\x2b\x21\x2d" +
+			"\x6a\xc9\xea\x5c\x63\xab\xe2\x5f\x6b\x0d\x21\x63\x12\xf5\x3d\xfd\x3b\x04\x1f\x06\x57\x14\x05\xb6" +
+			"\x8f\x30\x93\x68\xb5\xb2\x2a\xe7\x28\x73\xae\xf2\xee\x7b\xcd\x1e\x4d\x7e\x78\x5b\x3e\xde\x97\xef" +
+			"\xcf\x63\x92\x12\x92\xe7\x50\xb1\x1d\xde\x60\xab\x40\x18\x60\x12\x84\x3c\x6b\xb0\x51\xfa\x05\x5c" +
+			"\xd1\x08\xab\xf4\x0b\x25\xf6\xa5\xc5\xff\x3b\x8d\xd5\x5d\x61\xa1\xf7\x96\x6e\xd0\xa8\x4e\x17\x68" +
+			"\x86\x01\x1a\xd6\xde\x19\xab\x85\xe4\xf7\x2e\x20\xed\x7b\x7a\xfb\xd2\xe2\x30\x90\x81\x90\xaa\x93" +
+			"\x05\x24\x15\x7c\x9e\x74\x52\xb8\xd4\xc8\x2c\xbe\x76\x25\x0f\x30\xbe\x39\x7a\x19\xfe\x67\x20\x59" +
+			"\x83\x10\x34\x53\x48\xe6\xaa\x19\xa0\xd6\x4a\xa7\x0b\x27\xc3\x00\x9b\x0b\x98\xb7\xf6\x24\x8a\xb6" +
+			"\x57\x1b\x98\xfe\xbc\xec\x6f\x10\x9f\x89\x32\xce\x48\x14\x39\xe8\x9b\x83\x35\x57\x0b\xee\x6e\x85" +
+			"\x5b\x71\x7b\x87\x5e\xab\xef\x49\x9a\x91\x68\x20\x51\x45\xe7\xd1\xef\x66\xcf\xa7\xdb\xab\x7b\xb8" +
+			"\x80\x59\x8d\x44\x1a\x6d\xa7\xe5\xbc\x9a\x81\x14\xf5\x0a\x9d\x3f\xd1\x1e\x45\x23\xca\x77\x83\xc9" +
+			"\x40\xed\x1c\x9c\x37\xf6\x45\x79\x4f\x22\x51\xc1\x27\xb5\x73\x37\x4d\x5e\x17\x10\x87\x2c\x54\xbe" +
+			"\x68\xfd\x5a\xbc\x56\xf6\xab\xea\x64\xe9\xb1\xbc\x37\xe2\x37\x61\xa6\x8c\x66\x18\x92\x45\xc4\x14" +
+			"\x92\xbb\xfb\xf5\x64\x7b\xa6\x61\xb1\x01\x97\xed\x24\xaa\x94\x86\x87\x0c\xde\x6e\x0f\x26\x39\xbe" +
+			"\xe1\xe0\xb3\x2f\x4a\x17\xc0\xda\x16\x65\x99\xcc\xeb\x0b\xcd\x74\x25\xbf\x59\x02\xb8\x45\x63\xff" +
+			"\xf1\x47\x3a\xb1\xf0\x79\x1c\x4f\xf4\xd6\x27\x2a\xec\xb3\xf3\x36\x61\xf8\x83\x15\x3b\xae\x1d\xdc" +
+			"\x24\x25\x91\x71\x4b\xa7\x61\x1a\xd0\xa0\xe0\xdc\x3a\x90\x6e\xe7\x9e\x4e\x54\x7b\xe8\xfb\x33\x38" +
+			"\x34\xb0\x59\x3f\x98\xfd\x30\xb8\xad\xfe\x4d\x71\x8e\x7a\x03\x61\x0a\xd2\x6b\x9c\x36\x3a\x89\x0a" +
+			"\x7f\x0a\x4a\x0f\xde\x39\x30\x74\x79\x6a\x0b\xfb\x9c\xc1\xe9\x0f\xa7\xd3\xb2\xf7\x06\x9f\x3a\x34" +
+			"\xb6\x0f\xc7\xcd\x0f\xe7\xd8\xa1\x13\x95\x97\xff\x74\xe1\x50\xf9\x97\x60\xe9\x57\x66\x59\x5d\x25" +
+			"\x71\x27\xf1\xb9\xc5\xc2\x62\x19\xde\xfd\x06\x4e\xf6\xb1\xb7\x13\x98\x8b\x0a\x46\x8f\x74\x76\x62" +
+			"\x52\x77\xe9\x1e\x94\xa4\x4e\x38\x3c\x6c\xd4\xfe\xe2\x84\xaa\x24\x7e\x55\xf6\xf3\xc0\x75\x64\xc0" +
+			"\x95\x85\x93\xa7\x38\xfb\xa9\x6a\x1a\x00\x71\x65\x0f\xe0\xcc\x9a\x8f\x91\x39\x6c\x9c\xb0\x84\x37" +
+			"\x17\x6a\xdb\x72\x73\xc4\xc1\xb6\x4c\xd2\x0f\x00\xc7\x95\x5d\x11\x77\x92\xc7\x1f\xbf\x86\x72\x7b" +
+			"\x05\x27\x4f\xbf\x84\xd1\xa9\x64\x47\x3c\x8c\x80\x6b\x61\x0e\x09\x2f\x67\xc6\x11\xc6\xf3\xd6\x89" +
+			"\xf2\x07\x70\xab\x51\x26\xce\xd6\x81\x71\xe7\x25\xf5\xd8\xce\xd7\xc8\x9c\x2f\x07\xa3\x67\x54\xc6" +
+			"\xd9\xba\xde\x88\xe0\x21\xe4\xff\xf0\x0d\x16\x37\xc2\x18\xf7\x79\x34\x32\x09\x1f\x31\xf4\x52\x95" +
+			"\x98\xb8\xbc\x7e\x17\xb8\x0f\x1f\x3a\x8d\xf9\xb5\x6c\x0c\x5e\x3b\x3c\xb4\x31\xdc\x21\xb8\x59\x8c" +
+			"\x0f\x18\x22\x3f\xf5\xbc\x95\x7b\x56\x8b\xf2\x77\xcd\xbb\x06\xa5\x5d\xb5\x2e\x61\xd9\xb9\x16\x61" +
+			"\x20\xff\x0d\x00",
+		size: 2683,
+	},
+	"service.proto.tmpl": &asset{
+		name: "service.proto.tmpl",
+		data: "" +
+			"\xb4\x93\x41\x8f\xd3\x30\x10\x85\xef\xfe\x15\xa3\x9c\xb6\x48\xd8\x8a\xb8\x6d\xd4\x13\x07\x2e\x20" +
+			"\xa1\x85\x7b\xe5\x36\x23\x63\xd1\xda\xc6\xe3\x04\x2a\xcb\xff\x1d\xb9\xf1\x66\x93\xd6\x2a\x42\xb0" +
+			"\x39\x65\x9e\xdf\x8c\xbf\xe7\x38\x74\x36\x41\xfe\x82\x2d\x34\xce\xdb\x60\xdf\x35\x1d\x63\x4e\x1e" +
+			"\xbe\x4b\x85\x30\xfc\x3c\xca\x3d\xf1\x18\xf9\xe7\x49\x49\x89\x13\xfa\x51\x1f\x90\x8f\x6d\xc7\x98" +
+			"\x3e\x39\xeb\x03\x34\x93\x51\x2c\x8d\x62\x6c\x57\x35\xbf\x8c\x6f\xba\xb9\x47\x59\xab\x8e\x28\xa4" +
+			"\xd3\x42\x1a\x63\x83\x0c\xda\x1a\x9a\x6d\xcc\xba\x2c\x80\xb2\xbb\x67\x9c\x2d\x34\x31\xf2\x4f\xb6" +
+			"\x1f\x8e\x79\x83\x8b\x53\x28\x34\x42\x59\x51\x23\x28\xa8\x62\x6c\xbb\xa5\x5e\x64\xb7\xcf\xbb\x94" +
+			"\x02\x62\xe4\x5f\xa6\xd7\x94\x20\x32\x00\x00\xef\x0e\xf0\xde\xa3\x0c\x18\x23\xff\x7a\x76\x79\xe5" +
+			"\xe1\x4a\x78\xc2\x1f\x03\x52\xd8\x80\xc7\x30\x78\x43\x15\x03\x39\x6b\x08\x37\x65\x68\x7e\x4a\xb2" +
+			"\x87\xe9\x04\xb8\x74\x9a\x7f\x0b\xc1\x6d\x60\xbb\x30\xe5\xc7\x59\x0a\x8f\xd0\x94\xa3\x7c\x42\xb2" +
+			"\x83\x3f\x20\xa5\xd4\xac\x6c\x7b\xdb\x9f\x1f\xa1\x79\xf3\xa2\xa6\xee\xf2\x9a\xd8\x1c\xe4\x03\x86" +
+			"\x45\x8a\x65\x75\x1b\x61\xbd\xfa\x0f\xfc\x0a\xeb\xf8\x22\x42\x8c\x6f\x61\x96\x52\xda\xe9\x3e\xdd" +
+			"\xc3\xff\xa8\xe9\x99\x89\x72\x80\x75\x7d\x1b\xe1\x7a\xfd\x15\x42\x54\x70\x13\x63\x27\x24\xca\x77" +
+			"\xb5\x7e\x4f\xca\x68\x0a\x5e\x1b\x05\x46\x9e\xf2\xa5\x6e\xbb\xbb\x8d\x13\x7a\xe9\xac\xfd\x91\x63" +
+			"\xcb\x67\x3b\x2c\x18\x53\xba\x19\x5e\xf9\xee\x6b\xa4\x55\xfb\x4e\xf7\x7f\x98\xf0\x3f\xd9\xaa\x9f" +
+			"\x14\xe2\x1d\xc7\x6a\x77\x8f\x2e\x1f\x5c\xff\x57\x18\xf4\xc2\xf1\x7b\x00",
+		size: 1288,
+	},
+}
+
+// AssetAndInfo loads and returns the asset and asset info for the
+// given name. It returns an error if the asset could not be found
+// or could not be loaded.
+func AssetAndInfo(name string) ([]byte, os.FileInfo, error) {
+	a, ok := _bindata[filepath.ToSlash(name)]
+	if !ok {
+		return nil, nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	a.once.Do(func() {
+		fr := flate.NewReader(strings.NewReader(a.data))
+
+		var buf bytes.Buffer
+		if _, a.err = io.Copy(&buf, fr); a.err != nil {
+			return
+		}
+
+		if a.err = fr.Close(); a.err == nil {
+			a.bytes = buf.Bytes()
+		}
+	})
+	if a.err != nil {
+		return nil, nil, &os.PathError{Op: "read", Path: name, Err: a.err}
+	}
+
+	return a.bytes, a, nil
+}
+
+// AssetInfo loads and returns the asset info for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func AssetInfo(name string) (os.FileInfo, error) {
+	a, ok := _bindata[filepath.ToSlash(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	return a, nil
+}
+
+// Asset loads and returns the asset for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func Asset(name string) ([]byte, error) {
+	data, _, err := AssetAndInfo(name)
+	return data, err
+}
+
+// MustAsset is like Asset but panics when Asset would return an error.
+// It simplifies safe initialization of global variables.
+func MustAsset(name string) []byte {
+	a, err := Asset(name)
+	if err != nil {
+		panic("asset: Asset(" + name + "): " + err.Error())
+	}
+
+	return a
+}
+
+// AssetNames returns the names of the assets.
+func AssetNames() []string {
+	names := make([]string, 0, len(_bindata))
+	for name := range _bindata {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// RestoreAsset restores an asset under the given directory
+func RestoreAsset(dir, name string) error {
+	return restore.Asset(dir, name, AssetAndInfo)
+}
+
+// RestoreAssets restores an asset under the given directory recursively
+func RestoreAssets(dir, name string) error {
+	return restore.Assets(dir, name, AssetDir, AssetAndInfo)
+}
+
+// AssetDir returns the file names below a certain
+// directory embedded in the file by go-bindata.
+// For example if you run go-bindata on data/... and data contains the
+// following hierarchy:
+//     data/
+//       foo.txt
+//       img/
+//         a.png
+//         b.png
+// then AssetDir("data") would return []string{"foo.txt", "img"}
+// AssetDir("data/img") would return []string{"a.png", "b.png"}
+// AssetDir("foo.txt") and AssetDir("notexist") would return an error
+// AssetDir("") will return []string{"data"}.
+func AssetDir(name string) ([]string, error) {
+	node := _bintree
+
+	if name != "" {
+		var ok bool
+		for _, p := range strings.Split(filepath.ToSlash(name), "/") {
+			if node, ok = node[p]; !ok {
+				return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+			}
+		}
+	}
+
+	if len(node) == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	rv := make([]string, 0, len(node))
+	for name := range node {
+		rv = append(rv, name)
+	}
+
+	return rv, nil
+}
+
+type bintree map[string]bintree
+
+var _bintree = bintree{
+	"001_setup.down.sql.tmpl": bintree{},
+	"001_setup.up.sql.tmpl":   bintree{},
+	"deploy.yml.tmpl":         bintree{},
+	"main.go.tmpl":            bintree{},
+	"messages.proto.tmpl":     bintree{},
+	"model.go.tmpl":           bintree{},
+	"repo_memory.go.tmpl":     bintree{},
+	"repo_postgres.go.tmpl":   bintree{},
+	"server.go.tmpl":          bintree{},
+	"server_test.go.tmpl":     bintree{},
+	"service.proto.tmpl":      bintree{},
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/uw-labs/go-mono/cmd/new-service/internal/scaffold"
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
+)
+
+var (
+	repoRoot    = flag.String("repo-root", ".", "The root of the repo, to create the service in.")
+	moduleName  = flag.String("module-name", "github.com/uw-labs/go-mono", "The name of the local module.")
+	name        = flag.String("name", "", "The name of the service, such as billing-api. The service is created in cmd/<name>.")
+	pkg         = flag.String("package", "", "The name of the proto package of the service, created in proto/uwlabs/<package>. Defaults to the name without any -api or -service suffix or dashes.")
+	resource    = flag.String("resource", "item", "The singular name of the resource the service creates, gets and lists.")
+	postgres    = flag.Bool("postgres", false, "Store the resources in a Postgres database, migrated on startup, rather than in memory.")
+	grpcPort    = flag.Int("grpc-port", 8080, "The default port to serve the gRPC server on.")
+	gatewayPort = flag.Int("grpc-gateway-port", 8081, "The default port to serve the gRPC-Gateway on.")
+	adminPort   = flag.Int("admin-port", 8082, "The default port to serve the admin endpoints, such as metrics, on.")
+	generate    = flag.Bool("generate", true, "Generate the code of the proto packages and migrations, which requires the generators of make install-generators.")
+	logConfig   = pkglog.RegisterFlags(flag.CommandLine)
+)
+
+func main() {
+	flag.Parse()
+
+	logger, err := pkglog.New(logConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	if *name == "" {
+		logger.Fatal("name must be specified")
+	}
+	if *pkg == "" {
+		*pkg = scaffold.DefaultPackage(*name)
+	}
+
+	err = run(logger, &scaffold.Request{
+		RepoRoot:    *repoRoot,
+		Module:      *moduleName,
+		Name:        *name,
+		Package:     *pkg,
+		Resource:    *resource,
+		Postgres:    *postgres,
+		GRPCPort:    *grpcPort,
+		GatewayPort: *gatewayPort,
+		AdminPort:   *adminPort,
+	}, *generate)
+	if err != nil {
+		logger.WithError(err).Fatal()
+	}
+}
+
+func run(logger *logrus.Logger, req *scaffold.Request, generate bool) error {
+	files, err := scaffold.Generate(req)
+	if err != nil {
+		return fmt.Errorf("generate service: %w", err)
+	}
+	for _, f := range files {
+		logger.Infoln("Created", f)
+	}
+
+	if !generate {
+		logger.Infoln("Run make generate to generate the code of the proto packages and migrations")
+		return nil
+	}
+
+	ctx := pkgctx.WithSignalHandler(context.Background())
+	commands := [][]string{
+		{filepath.Join(".", "proto", "generate.sh")},
+		{"go", "generate", "-x", "./" + filepath.ToSlash(filepath.Join("cmd", req.Name)) + "/..."},
+	}
+	for _, args := range commands {
+		logger.Infoln("Running", args)
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = req.RepoRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("run %s, install the generators with make install-generators and run make generate: %w", args[0], err)
+		}
+	}
+
+	logger.Infof("Created %s, run it with go run ./cmd/%s", req.Name, req.Name)
+	return nil
+}
@@ -13,13 +13,13 @@ import (
 	bindata "github.com/golang-migrate/migrate/v4/source/go_bindata"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/log/logrusadapter"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/luna-duclos/instrumentedsql"
 	"github.com/luna-duclos/instrumentedsql/opentracing"
 	"github.com/sirupsen/logrus"
 
 	"github.com/uw-labs/go-mono/cmd/user-api/internal/repo/migrations"
+	"github.com/uw-labs/go-mono/pkg/log/pgxlog"
 )
 
 //go:generate go-bindata -pkg migrations -prefix migrations -nometadata -ignore bindata -o ./migrations/bindata.go ./migrations
@@ -48,7 +48,7 @@ func NewRepository(dbURL string, logger *logrus.Logger) (*Repository, error) {
 		return nil, fmt.Errorf("couldn't parse DB DSN: %w", err)
 	}
 
-	connConfig.Logger = logrusadapter.NewLogger(logger)
+	connConfig.Logger = pgxlog.New(logger)
 
 	connStr := stdlib.RegisterConnConfig(connConfig)
 	drv := instrumentedsql.WrapDriver(
@@ -99,6 +99,16 @@ func (r Repository) Close() error {
 	return r.db.Close()
 }
 
+// Ping checks that the database can be reached.
+func (r Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Stats returns the statistics of the database connection pool.
+func (r Repository) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
 // CreateUser creates a new user in the repository.
 func (r Repository) CreateUser(ctx context.Context, name string) (User, error) {
 	iq := r.sb.Insert(
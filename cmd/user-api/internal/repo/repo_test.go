@@ -16,11 +16,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/uw-labs/podrick"
 	_ "github.com/uw-labs/podrick/runtimes/docker" // register docker runtime
-	podricklogger "logur.dev/adapter/logrus"
 
 	"github.com/uw-labs/go-mono/cmd/user-api/internal/repo"
 	"github.com/uw-labs/go-mono/cmd/user-api/internal/repo/migrations"
 	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+	"github.com/uw-labs/go-mono/pkg/log/podricklog"
 )
 
 var (
@@ -58,7 +58,7 @@ func TestMain(m *testing.M) {
 			defer db.Close()
 			return db.Ping()
 		}),
-		podrick.WithLogger(podricklogger.New(logger)),
+		podrick.WithLogger(podricklog.New(logger)),
 	)
 	if err != nil {
 		logger.Println("Failed to start database container", err)
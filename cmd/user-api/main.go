@@ -14,55 +14,73 @@ import (
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/uw-labs/go-mono/cmd/user-api/internal/repo"
 	"github.com/uw-labs/go-mono/cmd/user-api/internal/server"
 	"github.com/uw-labs/go-mono/cmd/user-api/third_party/swagger"
-	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+	pkgconfig "github.com/uw-labs/go-mono/pkg/config"
+	pkghealth "github.com/uw-labs/go-mono/pkg/health"
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
+	pkgmetrics "github.com/uw-labs/go-mono/pkg/metrics"
+	pkgrun "github.com/uw-labs/go-mono/pkg/run"
 	usersservicepb "github.com/uw-labs/go-mono/proto/gen/go/uwlabs/users/service/v1"
 )
 
 //go:generate cp ../../proto/gen/openapiv2/uwlabs/users/service/v1/service.swagger.json ./third_party/swagger/swagger.json
 //go:generate go-bindata -pkg swagger -prefix third_party/swagger -nometadata -ignore bindata -o ./third_party/swagger/bindata.go ./third_party/swagger
 
-var (
-	postgresURL   = flag.String("postgres-url", "", "The URL of the postgres database to connect to.")
-	grpcPort      = flag.Uint("grpc-port", 8080, "The port to serve the gRPC server on.")
-	gatewayPort   = flag.Uint("grpc-gateway-port", 8081, "The port to serve the gRPC-Gateway on.")
-	adminUser     = flag.String("admin-user", "admin", "The username of the admin user.")
-	adminPassword = flag.String("admin-password", "", "The password of the admin user.")
-)
+// config is the configuration of the service, set by flags or by environment
+// variables prefixed with USER_API_, such as USER_API_POSTGRES_URL.
+type config struct {
+	PostgresURL   string        `config:"postgres-url" usage:"The URL of the postgres database to connect to." required:"true" secret:"true"`
+	GRPCPort      uint          `config:"grpc-port" usage:"The port to serve the gRPC server on."`
+	GatewayPort   uint          `config:"grpc-gateway-port" usage:"The port to serve the gRPC-Gateway on."`
+	AdminPort     uint          `config:"admin-port" usage:"The port to serve the admin endpoints, such as metrics, on."`
+	AdminUser     string        `config:"admin-user" usage:"The username of the admin user." required:"true"`
+	AdminPassword string        `config:"admin-password" usage:"The password of the admin user." required:"true" secret:"true"`
+	DrainDelay    time.Duration `config:"drain-delay" usage:"How long to report not ready for before shutting down, so load balancers stop sending requests."`
+}
 
-func main() {
-	flag.Parse()
+var logConfig = pkglog.RegisterFlags(flag.CommandLine)
 
-	logger := logrus.New()
-	logger.Formatter = &logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: time.StampMilli,
+func main() {
+	cfg := &config{
+		GRPCPort:    8080,
+		GatewayPort: 8081,
+		AdminPort:   8082,
+		AdminUser:   "admin",
+		DrainDelay:  10 * time.Second,
 	}
+	binding, err := pkgconfig.Bind(flag.CommandLine, "USER_API", cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal()
+	}
+
+	flag.Parse()
 
-	if *postgresURL == "" {
-		logger.Fatal("postgres-url must be specified")
+	logger, err := pkglog.New(logConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal()
 	}
 
-	if *adminPassword == "" {
-		logger.Fatal("admin-password must be specified")
+	err = binding.Load()
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid configuration")
 	}
+	binding.Log(logger)
 
-	err := run(logger, *postgresURL, *adminUser, *adminPassword, *grpcPort, *gatewayPort)
+	err = run(logger, cfg)
 	if err != nil {
 		logger.WithError(err).Fatal()
 	}
 }
 
-func run(logger *logrus.Logger, postgresURL, adminUser, adminPassword string, grpcPort, gatewayPort uint) (err error) {
-	ctx := pkgctx.WithSignalHandler(context.Background())
+func run(logger *logrus.Logger, cfg *config) (err error) {
+	ctx := context.Background()
 
-	rp, err := repo.NewRepository(postgresURL, logger)
+	rp, err := repo.NewRepository(cfg.PostgresURL, logger)
 	if err != nil {
 		return fmt.Errorf("create repository: %w", err)
 	}
@@ -73,18 +91,39 @@ func run(logger *logrus.Logger, postgresURL, adminUser, adminPassword string, gr
 		}
 	}()
 
+	m := pkgmetrics.New()
+	err = m.RegisterDB("users", rp)
+	if err != nil {
+		return fmt.Errorf("register database metrics: %w", err)
+	}
+
 	admin := &server.User{
-		Username: adminUser,
-		Password: adminPassword,
+		Username: cfg.AdminUser,
+		Password: cfg.AdminPassword,
 	}
 
-	grpcAddr := ":" + strconv.Itoa(int(grpcPort))
+	grpcAddr := ":" + strconv.Itoa(int(cfg.GRPCPort))
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		return fmt.Errorf("starting TCP listener: %w", err)
 	}
 
-	srv := grpc.NewServer()
+	gatewayAddr := ":" + strconv.Itoa(int(cfg.GatewayPort))
+	gwLis, err := net.Listen("tcp", gatewayAddr)
+	if err != nil {
+		return fmt.Errorf("starting TCP listener: %w", err)
+	}
+
+	adminAddr := ":" + strconv.Itoa(int(cfg.AdminPort))
+	adminLis, err := net.Listen("tcp", adminAddr)
+	if err != nil {
+		return fmt.Errorf("starting TCP listener: %w", err)
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(pkglog.UnaryServerInterceptor(logger), m.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(pkglog.StreamServerInterceptor(logger), m.StreamServerInterceptor()),
+	)
 
 	backend := &server.Server{
 		Logger: logger,
@@ -95,30 +134,18 @@ func run(logger *logrus.Logger, postgresURL, adminUser, adminPassword string, gr
 	usersservicepb.RegisterUserReaderServiceServer(srv, backend)
 	usersservicepb.RegisterUserWriterServiceServer(srv, backend)
 
-	eg, ctx := errgroup.WithContext(ctx)
-	eg.Go(func() error {
-		return srv.Serve(lis)
-	})
-	eg.Go(func() error {
-		<-ctx.Done()
-		tCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
-		ok := make(chan struct{})
-		go func() {
-			srv.GracefulStop()
-			close(ok)
-		}()
-
-		select {
-		case <-tCtx.Done():
-			srv.Stop()
-		case <-ok:
-		}
-		return nil
-	})
-
-	cc, err := grpc.Dial(grpcAddr, grpc.WithInsecure(), grpc.WithBlock())
+	hc := &pkghealth.Health{
+		Logger: logger,
+	}
+	hc.Add("postgres", pkghealth.CheckerFunc(rp.Ping))
+	hc.RegisterGRPC(srv)
+
+	// The connection is established lazily, once the gRPC server is serving.
+	cc, err := grpc.Dial(grpcAddr,
+		grpc.WithInsecure(),
+		grpc.WithChainUnaryInterceptor(m.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(m.StreamClientInterceptor()),
+	)
 	if err != nil {
 		return fmt.Errorf("dialling gRPC server: %w", err)
 	}
@@ -136,6 +163,7 @@ func run(logger *logrus.Logger, postgresURL, adminUser, adminPassword string, gr
 	}
 	gwmux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonpb),
+		runtime.WithMetadata(pkglog.GatewayMetadata),
 	)
 	err = usersservicepb.RegisterUserReaderServiceHandler(ctx, gwmux, cc)
 	if err != nil {
@@ -157,40 +185,47 @@ func run(logger *logrus.Logger, postgresURL, adminUser, adminPassword string, gr
 		AssetInfo: swagger.AssetInfo,
 	})
 
-	gatewayAddr := ":" + strconv.Itoa(int(gatewayPort))
+	gwHandler := m.Middleware("gateway", gwmux)
+	swaggerHandler = m.Middleware("swagger", swaggerHandler)
+
+	mux := http.NewServeMux()
+	// The health endpoints are not logged, as they are polled frequently.
+	hc.Register(mux)
+	mux.Handle("/", pkglog.Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1") {
+			gwHandler.ServeHTTP(w, r)
+			return
+		}
+
+		swaggerHandler.ServeHTTP(w, r)
+	})))
+
 	gwServer := &http.Server{
-		Addr: gatewayAddr,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if strings.HasPrefix(r.URL.Path, "/v1") {
-				gwmux.ServeHTTP(w, r)
-				return
-			}
-
-			swaggerHandler.ServeHTTP(w, r)
-		}),
+		Handler:      mux,
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
 	}
 
-	eg.Go(func() error {
-		<-ctx.Done()
-		tCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+	adminMux := http.NewServeMux()
+	adminMux.Handle(pkgmetrics.Path, m.Handler())
+	adminServer := &http.Server{
+		Handler:      adminMux,
+		WriteTimeout: 15 * time.Second,
+		ReadTimeout:  15 * time.Second,
+	}
 
-		err := gwServer.Shutdown(tCtx)
-		if err != nil {
-			return fmt.Errorf("shutdown gRPC gateway: %w", err)
-		}
-		return nil
-	})
-	eg.Go(func() error {
-		logger.Info("Serving gRPC-Gateway and Swagger Documentation on http://localhost:", gatewayPort)
-		err = gwServer.ListenAndServe()
-		if err != http.ErrServerClosed {
-			return fmt.Errorf("serve gRPC gateway: %w", err)
-		}
-		return nil
-	})
+	// Members are stopped in reverse order, so the gateway stops
+	// accepting requests before the gRPC server is drained, and
+	// metrics are served until the end.
+	g := &pkgrun.Group{
+		Logger:     logger,
+		DrainDelay: cfg.DrainDelay,
+	}
+	g.AddHTTP("admin server", adminServer, adminLis)
+	g.AddGRPC("gRPC server", srv, lis)
+	g.AddHTTP("gRPC-Gateway", gwServer, gwLis)
+	g.OnDrain(hc.Shutdown)
 
-	return eg.Wait()
+	logger.Info("Serving gRPC-Gateway and Swagger Documentation on http://localhost:", cfg.GatewayPort)
+	return g.Run(ctx)
 }
@@ -0,0 +1,301 @@
+// Package config binds the fields of a struct to flags, environment variables
+// and secret files.
+//
+// Each exported field with a config tag is bound to the flag named by the tag,
+// and to the environment variable of the same name, upper cased with dashes
+// replaced by underscores and prefixed with the prefix of the service. For example,
+// with the prefix USER_API, the field
+//
+//	PostgresURL string `config:"postgres-url" usage:"The URL of the database." required:"true" secret:"true"`
+//
+// is set by the --postgres-url flag or, if the flag is not passed, by the
+// USER_API_POSTGRES_URL environment variable or the contents of the file named by
+// USER_API_POSTGRES_URL_FILE, such as a mounted Kubernetes secret. Fields default
+// to their value when bound. Required fields must be set to a non-zero value,
+// and the values of secret fields are redacted when printed.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileSuffix is the suffix of the environment variables naming secret files.
+const FileSuffix = "_FILE"
+
+// Redacted replaces the values of secret fields when printed.
+const Redacted = "REDACTED"
+
+// Binding is a struct bound to a flag set and the environment.
+type Binding struct {
+	fs     *flag.FlagSet
+	fields []*field
+}
+
+type field struct {
+	name     string
+	env      string
+	required bool
+	secret   bool
+	value    reflect.Value
+}
+
+// Errors lists all the problems found when loading a configuration.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	stringsType  = reflect.TypeOf([]string(nil))
+)
+
+// Bind registers a flag on the flag set for each field of the struct pointed to
+// by cfg with a config tag. The fields must be strings, booleans, integers,
+// floats, durations or slices of strings, set from comma separated values.
+func Bind(fs *flag.FlagSet, prefix string, cfg interface{}) (*Binding, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to a struct, got %T", cfg)
+	}
+	v = v.Elem()
+
+	b := &Binding{fs: fs}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("field %s is not exported", sf.Name)
+		}
+		if !supported(sf.Type) {
+			return nil, fmt.Errorf("field %s has unsupported type %s", sf.Name, sf.Type)
+		}
+
+		f := &field{
+			name:     name,
+			env:      envName(prefix, name),
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+			value:    v.Field(i),
+		}
+		usage := sf.Tag.Get("usage")
+		if usage != "" {
+			usage += " "
+		}
+		usage += "Can also be set by " + f.env + ", or by the contents of the file named by " + f.env + FileSuffix + "."
+		fs.Var(f, name, usage)
+		b.fields = append(b.fields, f)
+	}
+
+	return b, nil
+}
+
+// Load sets the fields not set by flags from the environment, and checks that
+// the required fields are set. It must be called after parsing the flag set.
+// The returned error lists all the problems found.
+func (b *Binding) Load() error {
+	set := map[string]bool{}
+	b.fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var errs Errors
+	for _, f := range b.fields {
+		if !set[f.name] {
+			err := loadEnv(f)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		if f.required && f.value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required, set --%s or %s", f.name, f.name, f.env))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// loadEnv sets the field from its environment variable or secret file, if set.
+func loadEnv(f *field) error {
+	value := os.Getenv(f.env)
+	source := f.env
+	if value == "" {
+		path := os.Getenv(f.env + FileSuffix)
+		if path == "" {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f.env+FileSuffix, err)
+		}
+		value = strings.TrimRight(string(content), "\r\n")
+		source = f.env + FileSuffix
+	}
+
+	err := f.Set(value)
+	if err != nil {
+		return fmt.Errorf("invalid value of %s: %w", source, err)
+	}
+
+	return nil
+}
+
+// Fields returns the effective configuration, with the values of secret fields redacted.
+func (b *Binding) Fields() logrus.Fields {
+	fields := logrus.Fields{}
+	for _, f := range b.fields {
+		fields[f.name] = f.display()
+	}
+
+	return fields
+}
+
+// String returns the effective configuration as name=value pairs sorted by name,
+// with the values of secret fields redacted.
+func (b *Binding) String() string {
+	pairs := make([]string, 0, len(b.fields))
+	for _, f := range b.fields {
+		pairs = append(pairs, f.name+"="+f.display())
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
+
+// Log logs the effective configuration, with the values of secret fields redacted.
+func (b *Binding) Log(logger logrus.FieldLogger) {
+	logger.WithFields(b.Fields()).Info("Loaded configuration")
+}
+
+func (f *field) display() string {
+	if f.secret && !f.value.IsZero() {
+		return Redacted
+	}
+
+	return f.String()
+}
+
+// String implements flag.Value.
+func (f *field) String() string {
+	// The flag package calls String on the zero value of flags to print their usage.
+	if !f.value.IsValid() {
+		return ""
+	}
+
+	switch {
+	case f.value.Type() == durationType:
+		return time.Duration(f.value.Int()).String()
+	case f.value.Kind() == reflect.Slice:
+		return strings.Join(f.value.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
+
+// Set implements flag.Value.
+func (f *field) Set(s string) error {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		fl, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(fl)
+	case v.Kind() == reflect.Slice:
+		var values []string
+		for _, value := range strings.Split(s, ",") {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be passed without a value.
+func (f *field) IsBoolFlag() bool {
+	return f.value.IsValid() && f.value.Kind() == reflect.Bool
+}
+
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t == stringsType
+	default:
+		return false
+	}
+}
+
+// envName returns the name of the environment variable of the flag.
+func envName(prefix, name string) string {
+	env := strings.ToUpper(strings.Replace(name, "-", "_", -1))
+	if prefix == "" {
+		return env
+	}
+
+	return strings.TrimSuffix(prefix, "_") + "_" + env
+}
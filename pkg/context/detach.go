@@ -0,0 +1,21 @@
+package context
+
+import (
+	"context"
+	"time"
+)
+
+// Detach returns a context carrying the values of ctx, but not its
+// deadline or cancellation, such as for work that must finish even
+// if the caller that started it goes away.
+func Detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
@@ -0,0 +1,30 @@
+package context_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+)
+
+type key struct{}
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Minute)
+	cancel()
+
+	ctx := pkgctx.Detach(parent)
+	if ctx.Err() != nil {
+		t.Errorf("expected the detached context not to be cancelled, got %v", ctx.Err())
+	}
+	if ctx.Done() != nil {
+		t.Error("expected the detached context never to be done")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected the detached context to have no deadline")
+	}
+	if got := ctx.Value(key{}); got != "value" {
+		t.Errorf("expected the value of the parent, got %v", got)
+	}
+}
@@ -4,19 +4,160 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
-// WithSignalHandler wraps the context so a system Interrupt or SIGTERM signal cancels it
-func WithSignalHandler(pCtx context.Context) context.Context {
-	ctx, cancel := context.WithCancel(pCtx)
-	go func() {
-		sCh := make(chan os.Signal, 1)
-		signal.Notify(sCh, os.Interrupt, syscall.SIGTERM)
-		<-sCh
+// Option configures a signal handler.
+type Option func(*options)
+
+type options struct {
+	signals       []os.Signal
+	forceExit     func(os.Signal)
+	reload        func(os.Signal)
+	reloadSignals []os.Signal
+}
+
+// WithSignals sets the signals that cancel the context.
+// Defaults to Interrupt and SIGTERM, also when no signals are given,
+// as notifying of no signals would notify of every signal.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		if len(signals) > 0 {
+			o.signals = signals
+		}
+	}
+}
+
+// WithForceExit sets the function called when a second signal is received
+// after the context was cancelled, such as when graceful shutdown hangs.
+// Defaults to exiting immediately, with 128 plus the number of the signal
+// as the exit code. Nil ignores further signals.
+func WithForceExit(fn func(os.Signal)) Option {
+	return func(o *options) {
+		o.forceExit = fn
+	}
+}
+
+// WithReload calls the function, without cancelling the context, whenever
+// one of the signals is received, such as to reload configuration.
+// The signals default to SIGHUP.
+func WithReload(fn func(os.Signal), signals ...os.Signal) Option {
+	return func(o *options) {
+		o.reload = fn
+		o.reloadSignals = signals
+		if len(signals) == 0 {
+			o.reloadSignals = []os.Signal{syscall.SIGHUP}
+		}
+	}
+}
+
+// signalKey is the context key of the signal that cancelled the context.
+type signalKey struct{}
+
+// received holds the signal that cancelled the context.
+type received struct {
+	mu  sync.Mutex
+	sig os.Signal
+}
+
+// WithSignalHandler wraps the context so a system Interrupt or SIGTERM signal cancels it.
+// A second signal exits the process immediately. Signal handling stops when the
+// parent context is done.
+func WithSignalHandler(pCtx context.Context, opts ...Option) context.Context {
+	ctx, _ := WithSignalHandlerStop(pCtx, opts...)
+	return ctx
+}
+
+// WithSignalHandlerStop is like WithSignalHandler, but also returns a function
+// that stops handling signals, restoring their default behaviour, and cancels the context.
+func WithSignalHandlerStop(pCtx context.Context, opts ...Option) (context.Context, context.CancelFunc) {
+	o := &options{
+		signals:   []os.Signal{os.Interrupt, syscall.SIGTERM},
+		forceExit: exit,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	r := &received{}
+	ctx, cancel := context.WithCancel(context.WithValue(pCtx, signalKey{}, r))
+
+	sCh := make(chan os.Signal, 1)
+	signal.Notify(sCh, o.signals...)
+	var rCh chan os.Signal
+	if o.reload != nil {
+		rCh = make(chan os.Signal, 1)
+		signal.Notify(rCh, o.reloadSignals...)
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+		})
 		cancel()
+	}
+
+	go func() {
+		defer func() {
+			signal.Stop(sCh)
+			if rCh != nil {
+				signal.Stop(rCh)
+			}
+		}()
+
+		for {
+			select {
+			case sig := <-sCh:
+				if ctx.Err() == nil {
+					r.set(sig)
+					cancel()
+					continue
+				}
+				if o.forceExit != nil {
+					o.forceExit(sig)
+				}
+			case sig := <-rCh:
+				o.reload(sig)
+			case <-pCtx.Done():
+				cancel()
+				return
+			case <-done:
+				return
+			}
+		}
 	}()
-	return ctx
+
+	return ctx, stop
+}
+
+// Signal returns the signal that cancelled the context, or
+// nil if the context was not cancelled by a signal handler.
+func Signal(ctx context.Context) os.Signal {
+	r, ok := ctx.Value(signalKey{}).(*received)
+	if !ok {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sig
+}
+
+func (r *received) set(sig os.Signal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sig = sig
+}
+
+// exit exits the process with the conventional exit code for the signal.
+func exit(sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok {
+		os.Exit(128 + int(s))
+	}
+	os.Exit(1)
 }
 
 // Background wraps `context.Background()` with a signal handler
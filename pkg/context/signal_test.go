@@ -0,0 +1,122 @@
+// +build !windows
+
+package context_test
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+)
+
+func raise(t *testing.T, sig syscall.Signal) {
+	t.Helper()
+	err := syscall.Kill(os.Getpid(), sig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func receive(t *testing.T, ch <-chan os.Signal) os.Signal {
+	t.Helper()
+	select {
+	case sig := <-ch:
+		return sig
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the signal to be handled")
+		return nil
+	}
+}
+
+func TestWithSignalHandler(t *testing.T) {
+	t.Run("It cancels on the first signal and forces exit on the second", func(t *testing.T) {
+		exited := make(chan os.Signal, 1)
+		ctx, stop := pkgctx.WithSignalHandlerStop(context.Background(),
+			pkgctx.WithSignals(syscall.SIGUSR1),
+			pkgctx.WithForceExit(func(sig os.Signal) {
+				exited <- sig
+			}),
+		)
+		defer stop()
+
+		if sig := pkgctx.Signal(ctx); sig != nil {
+			t.Errorf("expected no signal before cancellation, got %v", sig)
+		}
+
+		raise(t, syscall.SIGUSR1)
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the context to be cancelled")
+		}
+		if sig := pkgctx.Signal(ctx); sig != syscall.SIGUSR1 {
+			t.Errorf("expected the context to be cancelled by SIGUSR1, got %v", sig)
+		}
+
+		raise(t, syscall.SIGUSR1)
+		if sig := receive(t, exited); sig != syscall.SIGUSR1 {
+			t.Errorf("expected a forced exit on SIGUSR1, got %v", sig)
+		}
+	})
+	t.Run("It calls the reload function without cancelling", func(t *testing.T) {
+		reloaded := make(chan os.Signal, 1)
+		ctx, stop := pkgctx.WithSignalHandlerStop(context.Background(),
+			pkgctx.WithSignals(syscall.SIGUSR1),
+			pkgctx.WithReload(func(sig os.Signal) {
+				reloaded <- sig
+			}, syscall.SIGUSR2),
+		)
+		defer stop()
+
+		raise(t, syscall.SIGUSR2)
+		if sig := receive(t, reloaded); sig != syscall.SIGUSR2 {
+			t.Errorf("expected a reload on SIGUSR2, got %v", sig)
+		}
+		if ctx.Err() != nil {
+			t.Errorf("expected the context not to be cancelled, got %v", ctx.Err())
+		}
+	})
+	t.Run("It keeps the default signals when none are given", func(t *testing.T) {
+		ctx, stop := pkgctx.WithSignalHandlerStop(context.Background(), pkgctx.WithSignals())
+		defer stop()
+
+		// SIGURG is sent by the runtime to preempt goroutines, so must not cancel.
+		urg := make(chan os.Signal, 1)
+		signal.Notify(urg, syscall.SIGURG)
+		defer signal.Stop(urg)
+
+		raise(t, syscall.SIGURG)
+		receive(t, urg)
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected the context not to be cancelled by SIGURG, got %v", pkgctx.Signal(ctx))
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+	t.Run("It is cancelled with its parent", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := pkgctx.WithSignalHandler(parent, pkgctx.WithSignals(syscall.SIGUSR1))
+
+		cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the context to be cancelled")
+		}
+		if sig := pkgctx.Signal(ctx); sig != nil {
+			t.Errorf("expected no signal, got %v", sig)
+		}
+	})
+	t.Run("It is cancelled when stopped", func(t *testing.T) {
+		ctx, stop := pkgctx.WithSignalHandlerStop(context.Background(), pkgctx.WithSignals(syscall.SIGUSR1))
+
+		stop()
+		if ctx.Err() != context.Canceled {
+			t.Errorf("expected the context to be cancelled, got %v", ctx.Err())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// WatchInterval is how often the readiness of a service is checked for watchers.
+const WatchInterval = 5 * time.Second
+
+// RegisterGRPC registers the standard grpc.health.v1.Health service on the server.
+// The overall health, of the empty service name, and the health of every other
+// service registered on the server is the readiness of the service.
+func (h *Health) RegisterGRPC(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, &grpcServer{health: h, srv: srv})
+}
+
+type grpcServer struct {
+	health *Health
+	srv    *grpc.Server
+}
+
+func (s *grpcServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.known(req.GetService()) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+func (s *grpcServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	shutdown := s.health.shutdownCh()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		// As specified, watching unknown services is not an error,
+		// in case they are registered later.
+		st := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if s.known(req.GetService()) {
+			st = s.status(ctx)
+		}
+		if st != last {
+			err := stream.Send(&healthpb.HealthCheckResponse{Status: st})
+			if err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-shutdown:
+			shutdown = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *grpcServer) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if s.health.Ready(ctx) != nil {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+func (s *grpcServer) known(service string) bool {
+	if service == "" {
+		return true
+	}
+	_, ok := s.srv.GetServiceInfo()[service]
+
+	return ok
+}
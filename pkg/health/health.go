@@ -0,0 +1,212 @@
+// Package health reports the health of a service over the standard gRPC health
+// checking protocol and HTTP liveness and readiness endpoints.
+//
+// A service is live while it is running, and ready while it is not shutting down
+// and all of its checks, such as pinging its database, pass. Checks run with a
+// timeout and their results are cached, so frequent probes do not overload
+// the dependencies of the service.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+)
+
+// Defaults of a Health that does not set them.
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// ErrShuttingDown is the error of the readiness of a service shutting down.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Checker checks a dependency of a service.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a function implementing Checker, such as the Ping method of a repository.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls the function.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// DialChecker checks that a TCP connection can be opened to the address.
+func DialChecker(address string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// Health reports the health of a service. The zero value is ready to use.
+type Health struct {
+	// Logger logs failing checks. Defaults to the standard logrus logger.
+	Logger logrus.FieldLogger
+	// Timeout is how long each check may take. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// CacheTTL is how long the result of each check is reused for.
+	// Defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+
+	mu           sync.Mutex
+	checks       []*check
+	shuttingDown bool
+	// changed is closed and replaced when the service starts shutting down.
+	changed chan struct{}
+}
+
+type check struct {
+	name    string
+	checker Checker
+
+	mu      sync.Mutex
+	err     error
+	checked time.Time
+}
+
+// Result is the result of a check.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Add adds a check of the readiness of the service.
+func (h *Health) Add(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &check{name: name, checker: c})
+}
+
+// Shutdown marks the service as shutting down, and so not ready. It can be
+// passed to the OnDrain method of a run.Group.
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shuttingDown = true
+	if h.changed != nil {
+		close(h.changed)
+		h.changed = nil
+	}
+}
+
+// Check runs the checks, or reuses their cached results, concurrently.
+// The results are sorted by name.
+func (h *Health) Check(ctx context.Context) []*Result {
+	h.mu.Lock()
+	checks := h.checks
+	h.mu.Unlock()
+
+	results := make([]*Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = &Result{Name: c.name, Err: h.run(ctx, c)}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results
+}
+
+// Ready returns nil if the service is ready, ErrShuttingDown if it is shutting
+// down, or an error listing the failing checks.
+func (h *Health) Ready(ctx context.Context) error {
+	if h.isShuttingDown() {
+		return ErrShuttingDown
+	}
+
+	var failed []string
+	for _, r := range h.Check(ctx) {
+		if r.Err != nil {
+			failed = append(failed, r.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed checks: %v", failed)
+	}
+
+	return nil
+}
+
+// run runs the check, unless its cached result is still fresh.
+func (h *Health) run(ctx context.Context, c *check) error {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ttl := h.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checked.IsZero() && time.Since(c.checked) < ttl {
+		return c.err
+	}
+
+	// The result is cached for other callers, so the check must not fail
+	// because this caller, such as a probe that timed out, went away.
+	ctx, cancel := context.WithTimeout(pkgctx.Detach(ctx), timeout)
+	defer cancel()
+	err := c.checker.Check(ctx)
+	if err != nil {
+		h.logger().WithError(err).WithField("check", c.name).Warn("Health check failed")
+	}
+	c.err = err
+	c.checked = time.Now()
+
+	return err
+}
+
+func (h *Health) isShuttingDown() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.shuttingDown
+}
+
+// shutdownCh returns a channel closed when the service starts shutting down.
+func (h *Health) shutdownCh() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.shuttingDown {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	if h.changed == nil {
+		h.changed = make(chan struct{})
+	}
+	return h.changed
+}
+
+func (h *Health) logger() logrus.FieldLogger {
+	if h.Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return h.Logger
+}
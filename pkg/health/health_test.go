@@ -0,0 +1,161 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/uw-labs/go-mono/pkg/health"
+)
+
+func newHealth() *health.Health {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	return &health.Health{Logger: logger}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		err        error
+		shutdown   bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "It is ready when the checks pass",
+			wantStatus: http.StatusOK,
+			wantBody:   "[+] database ok\nok\n",
+		},
+		{
+			name:       "It is not ready when a check fails",
+			err:        errors.New("dial tcp postgres.internal:5432: connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[-] database failed\n",
+		},
+		{
+			name:       "It is not ready when shutting down",
+			shutdown:   true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "shutting down\n",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			h := newHealth()
+			h.Add("database", health.CheckerFunc(func(context.Context) error {
+				return test.err
+			}))
+			if test.shutdown {
+				h.Shutdown()
+			}
+
+			w := httptest.NewRecorder()
+			h.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, health.ReadinessPath, nil))
+			if w.Code != test.wantStatus {
+				t.Errorf("expected status %d, got %d", test.wantStatus, w.Code)
+			}
+			if got := w.Body.String(); got != test.wantBody {
+				t.Errorf("expected body %q, got %q", test.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestCheckCaches(t *testing.T) {
+	h := newHealth()
+	var calls int32
+	h.Add("database", health.CheckerFunc(func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		err := h.Ready(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the check to run once, ran %d times", calls)
+	}
+}
+
+func TestCheckIgnoresCallerCancellation(t *testing.T) {
+	h := newHealth()
+	h.Add("database", health.CheckerFunc(func(ctx context.Context) error {
+		return ctx.Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := h.Ready(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRegisterGRPC(t *testing.T) {
+	h := newHealth()
+	srv := grpc.NewServer()
+	h.RegisterGRPC(srv)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	defer srv.Stop()
+
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cc.Close()
+	client := healthpb.NewHealthClient(cc)
+	ctx := context.Background()
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "grpc.health.v1.Health"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected status %v, got %v", healthpb.HealthCheckResponse_SERVING, resp.Status)
+	}
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown.Service"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected code %v, got %v", codes.NotFound, err)
+	}
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []healthpb.HealthCheckResponse_ServingStatus{
+		healthpb.HealthCheckResponse_SERVING,
+		healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Status != want {
+			t.Errorf("expected status %v, got %v", want, resp.Status)
+		}
+		h.Shutdown()
+	}
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Paths of the HTTP endpoints, as conventional in Kubernetes.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// LivenessHandler responds with 200 OK while the service is running.
+// It runs no checks, so that failing dependencies do not get the service restarted.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
+
+// ReadinessHandler responds with 200 OK while the service is ready, and with
+// 503 Service Unavailable otherwise, listing the results of the checks.
+// The errors of failing checks are logged rather than listed, as they can
+// include details of the dependencies, such as database hosts and users.
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+
+		if h.isShuttingDown() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, ErrShuttingDown)
+			return
+		}
+
+		results := h.Check(r.Context())
+		status := http.StatusOK
+		for _, res := range results {
+			if res.Err != nil {
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		w.WriteHeader(status)
+		for _, res := range results {
+			if res.Err != nil {
+				_, _ = fmt.Fprintf(w, "[-] %s failed\n", res.Name)
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "[+] %s ok\n", res.Name)
+		}
+		if status == http.StatusOK {
+			_, _ = fmt.Fprintln(w, "ok")
+		}
+	})
+}
+
+// Register registers the liveness and readiness endpoints on the mux.
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.Handle(LivenessPath, h.LivenessHandler())
+	mux.Handle(ReadinessPath, h.ReadinessHandler())
+}
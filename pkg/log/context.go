@@ -0,0 +1,72 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Fields set on the loggers of requests.
+const (
+	FieldRequestID = "request_id"
+	FieldMethod    = "method"
+	FieldPeer      = "peer"
+	FieldDuration  = "duration"
+)
+
+// RequestIDHeader is the HTTP header, and lowercased the gRPC metadata key,
+// propagating request IDs between services.
+const RequestIDHeader = "X-Request-Id"
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, logger logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the context,
+// or the standard logger if it has none.
+func FromContext(ctx context.Context) logrus.FieldLogger {
+	logger, ok := ctx.Value(loggerKey{}).(logrus.FieldLogger)
+	if !ok {
+		return logrus.StandardLogger()
+	}
+
+	return logger
+}
+
+// WithFields returns a copy of the context carrying
+// its logger with the fields added.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithFields(fields))
+}
+
+// WithRequestID returns a copy of the context carrying the request ID,
+// and its logger with the request ID field added.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return WithFields(ctx, logrus.Fields{FieldRequestID: id})
+}
+
+// RequestID returns the request ID of the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		// The system random number generator is not expected to ever fail.
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// FieldCode is the field of the status code of gRPC calls.
+const FieldCode = "grpc.code"
+
+// UnaryServerInterceptor returns an interceptor attaching the logger, with the
+// request ID, method and peer of each call, to the context of the call, and
+// logging the call with its duration and status code once it completes.
+// The request ID is read from the incoming metadata or generated.
+func UnaryServerInterceptor(logger logrus.FieldLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = serverContext(ctx, logger, info.FullMethod)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is like UnaryServerInterceptor, for streaming calls.
+func StreamServerInterceptor(logger logrus.FieldLogger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := serverContext(ss.Context(), logger, info.FullMethod)
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor propagating
+// the request ID of the context in the outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is like UnaryClientInterceptor, for streaming calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func serverContext(ctx context.Context, logger logrus.FieldLogger, method string) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(strings.ToLower(RequestIDHeader)); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = NewRequestID()
+	}
+
+	fields := logrus.Fields{FieldMethod: method}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[FieldPeer] = p.Addr.String()
+	}
+
+	ctx = WithLogger(ctx, logger.WithFields(fields))
+	return WithRequestID(ctx, id)
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	id := RequestID(ctx)
+	if id == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, strings.ToLower(RequestIDHeader), id)
+}
+
+func logCall(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	logger := FromContext(ctx).WithFields(logrus.Fields{
+		FieldCode:     code.String(),
+		FieldDuration: time.Since(start).String(),
+	})
+	if err != nil {
+		logger = logger.WithError(err)
+	}
+
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		logger.Info("Handled gRPC call")
+	case codes.ResourceExhausted, codes.Aborted:
+		logger.Warn("Handled gRPC call")
+	default:
+		logger.Error("Handled gRPC call")
+	}
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
+)
+
+// FieldStatus is the field of the status code of HTTP requests.
+const FieldStatus = "http.status"
+
+// Middleware returns a handler attaching the logger, with the request ID,
+// method and path, and peer of each request, to the context of the request,
+// and logging the request with its duration and status code once it is served.
+// The request ID is read from the X-Request-Id header or generated,
+// and is set on the response.
+func Middleware(logger logrus.FieldLogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := WithLogger(r.Context(), logger.WithFields(logrus.Fields{
+			FieldMethod: r.Method + " " + r.URL.Path,
+			FieldPeer:   r.RemoteAddr,
+		}))
+		ctx = WithRequestID(ctx, id)
+
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		entry := FromContext(ctx).WithFields(logrus.Fields{
+			FieldStatus:   rw.status,
+			FieldDuration: time.Since(start).String(),
+		})
+		if rw.status >= http.StatusInternalServerError {
+			entry.Error("Served HTTP request")
+			return
+		}
+		entry.Info("Served HTTP request")
+	})
+}
+
+// GatewayMetadata returns the request ID of the context as gRPC metadata,
+// so that the gRPC-Gateway propagates it to the gRPC server, when passed
+// to runtime.WithMetadata.
+func GatewayMetadata(ctx context.Context, _ *http.Request) metadata.MD {
+	id := RequestID(ctx)
+	if id == "" {
+		return nil
+	}
+
+	return metadata.Pairs(strings.ToLower(RequestIDHeader), id)
+}
+
+// responseWriter records the status code of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses, such as those of server streaming RPCs.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
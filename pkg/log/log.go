@@ -0,0 +1,85 @@
+// Package log configures structured logging, attaches loggers with request
+// scoped fields to contexts and logs the calls to gRPC and HTTP servers.
+package log
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of the log lines.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Environment variables setting the default level and format.
+const (
+	LevelEnv  = "LOG_LEVEL"
+	FormatEnv = "LOG_FORMAT"
+)
+
+// Config configures the level and format of a logger.
+type Config struct {
+	// Level is the minimum level of the logged lines, such as debug or info.
+	Level string
+	// Format is the format of the log lines, either text or json.
+	Format string
+}
+
+// ConfigFromEnv returns the config set by the LOG_LEVEL and LOG_FORMAT
+// environment variables, defaulting to the info level and the text format.
+func ConfigFromEnv() *Config {
+	c := &Config{
+		Level:  os.Getenv(LevelEnv),
+		Format: os.Getenv(FormatEnv),
+	}
+	if c.Level == "" {
+		c.Level = logrus.InfoLevel.String()
+	}
+	if c.Format == "" {
+		c.Format = FormatText
+	}
+
+	return c
+}
+
+// RegisterFlags registers the --log-level and --log-format flags on the
+// flag set, defaulting to the config set by the environment.
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	c := ConfigFromEnv()
+	fs.StringVar(&c.Level, "log-level", c.Level, "The minimum level of the logged lines, one of trace, debug, info, warning, error, fatal or panic. Defaults to "+LevelEnv+", if set.")
+	fs.StringVar(&c.Format, "log-format", c.Format, "The format of the log lines, either text or json. Defaults to "+FormatEnv+", if set.")
+
+	return c
+}
+
+// New returns a logger configured by the config.
+func New(c *Config) (*logrus.Logger, error) {
+	level, err := logrus.ParseLevel(c.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	logger := logrus.New()
+	logger.Level = level
+	switch c.Format {
+	case FormatText, "":
+		logger.Formatter = &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.StampMilli,
+		}
+	case FormatJSON:
+		logger.Formatter = &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		}
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be %s or %s", c.Format, FormatText, FormatJSON)
+	}
+
+	return logger, nil
+}
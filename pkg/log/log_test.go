@@ -0,0 +1,163 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pkglog "github.com/uw-labs/go-mono/pkg/log"
+)
+
+func TestNew(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		config  *pkglog.Config
+		want    logrus.Level
+		wantErr bool
+	}{
+		{
+			name:   "It configures the level",
+			config: &pkglog.Config{Level: "debug", Format: pkglog.FormatJSON},
+			want:   logrus.DebugLevel,
+		},
+		{
+			name:    "It fails on unknown levels",
+			config:  &pkglog.Config{Level: "verbose", Format: pkglog.FormatText},
+			wantErr: true,
+		},
+		{
+			name:    "It fails on unknown formats",
+			config:  &pkglog.Config{Level: "info", Format: "xml"},
+			wantErr: true,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			logger, err := pkglog.New(test.config)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", logger)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if logger.Level != test.want {
+				t.Errorf("expected level %v, got %v", test.want, logger.Level)
+			}
+		})
+	}
+}
+
+// newLogger returns a logger writing JSON lines to the buffer.
+func newLogger(buf *bytes.Buffer) *logrus.Logger {
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	return logger
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		line := map[string]interface{}{}
+		err := dec.Decode(&line)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		delete(line, "time")
+		delete(line, pkglog.FieldDuration)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	interceptor := pkglog.UnaryServerInterceptor(newLogger(&buf))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "abc"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetUser"}
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		if id := pkglog.RequestID(ctx); id != "abc" {
+			t.Errorf("expected request ID abc, got %q", id)
+		}
+		pkglog.FromContext(ctx).Info("Getting user")
+		return nil, status.Error(codes.NotFound, "no such user")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"level":               "info",
+			"msg":                 "Getting user",
+			pkglog.FieldRequestID: "abc",
+			pkglog.FieldMethod:    info.FullMethod,
+		},
+		{
+			"level":               "info",
+			"msg":                 "Handled gRPC call",
+			"error":               "rpc error: code = NotFound desc = no such user",
+			pkglog.FieldRequestID: "abc",
+			pkglog.FieldMethod:    info.FullMethod,
+			pkglog.FieldCode:      "NotFound",
+		},
+	}
+	if diff := cmp.Diff(want, decodeLines(t, &buf)); diff != "" {
+		t.Errorf("unexpected log lines (-want +got):\n%s", diff)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	var id string
+	h := pkglog.Middleware(newLogger(&buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = pkglog.RequestID(r.Context())
+		md := pkglog.GatewayMetadata(r.Context(), r)
+		if diff := cmp.Diff([]string{id}, md.Get("x-request-id")); diff != "" {
+			t.Errorf("unexpected gateway metadata (-want +got):\n%s", diff)
+		}
+		http.Error(w, "failed", http.StatusBadGateway)
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	h.ServeHTTP(w, r)
+
+	if id == "" {
+		t.Fatal("expected a request ID to be generated")
+	}
+	if got := w.Header().Get(pkglog.RequestIDHeader); got != id {
+		t.Errorf("expected the response to have request ID %q, got %q", id, got)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"level":               "error",
+			"msg":                 "Served HTTP request",
+			pkglog.FieldRequestID: id,
+			pkglog.FieldMethod:    "GET /v1/users",
+			pkglog.FieldPeer:      "10.0.0.1:1234",
+			pkglog.FieldStatus:    float64(http.StatusBadGateway),
+		},
+	}
+	if diff := cmp.Diff(want, decodeLines(t, &buf)); diff != "" {
+		t.Errorf("unexpected log lines (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,14 @@
+// Package pgxlog adapts loggers for pgx. It is separate from package log
+// so that only services using pgx depend on it.
+package pgxlog
+
+import (
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/log/logrusadapter"
+	"github.com/sirupsen/logrus"
+)
+
+// New adapts the logger for the pgx connection config.
+func New(logger logrus.FieldLogger) pgx.Logger {
+	return logrusadapter.NewLogger(logger)
+}
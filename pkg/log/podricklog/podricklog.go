@@ -0,0 +1,14 @@
+// Package podricklog adapts loggers for podrick. It is separate from package log
+// so that only tests starting containers depend on it.
+package podricklog
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/uw-labs/podrick"
+	logurlogrus "logur.dev/adapter/logrus"
+)
+
+// New adapts the logger for the containers started with podrick.
+func New(logger *logrus.Logger) podrick.Logger {
+	return logurlogrus.New(logger)
+}
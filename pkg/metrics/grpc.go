@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Types of RPCs, as labelled.
+const (
+	typeUnary        = "unary"
+	typeClientStream = "client_stream"
+	typeServerStream = "server_stream"
+	typeBidiStream   = "bidi_stream"
+)
+
+// UnaryServerInterceptor records the number and duration of unary RPCs handled by the server.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(m.serverHandled, m.serverLatency, typeUnary, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records the number and duration of streaming RPCs handled by the server.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(m.serverHandled, m.serverLatency, streamType(info.IsClientStream, info.IsServerStream), info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor records the number and duration of unary RPCs made by the client.
+func (m *Metrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observe(m.clientHandled, m.clientLatency, typeUnary, method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor records the number and duration of streaming RPCs made by the client.
+// They are recorded when they end, which the client sees when it receives their last message,
+// or an error, or when they fail to open. Streams abandoned before they end are recorded when
+// their context is cancelled, which gRPC requires of them to release their resources.
+func (m *Metrics) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		typ := streamType(desc.ClientStreams, desc.ServerStreams)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observe(m.clientHandled, m.clientLatency, typ, method, start, err)
+			return nil, err
+		}
+
+		s := &clientStream{
+			ClientStream:  cs,
+			serverStreams: desc.ServerStreams,
+			observe: func(err error) {
+				observe(m.clientHandled, m.clientLatency, typ, method, start, err)
+			},
+			done: make(chan struct{}),
+		}
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.end(status.FromContextError(ctx.Err()).Err())
+			case <-s.done:
+			}
+		}()
+		return s, nil
+	}
+}
+
+// clientStream observes the end of the stream it wraps.
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	observe       func(err error)
+	once          sync.Once
+	// done is closed when the end of the stream is observed.
+	done chan struct{}
+}
+
+// RecvMsg receives a message from the stream, observing the end of the stream.
+func (s *clientStream) RecvMsg(msg interface{}) error {
+	err := s.ClientStream.RecvMsg(msg)
+	switch {
+	case err == io.EOF:
+		s.end(nil)
+	case err != nil:
+		s.end(err)
+	case !s.serverStreams:
+		// The server sends a single message, so receiving it ends the stream.
+		s.end(nil)
+	}
+
+	return err
+}
+
+// end observes the end of the stream, if it was not already observed.
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		s.observe(err)
+		close(s.done)
+	})
+}
+
+func observe(handled *prometheus.CounterVec, latency *prometheus.HistogramVec, typ, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	latency.WithLabelValues(typ, service, method).Observe(time.Since(start).Seconds())
+	handled.WithLabelValues(typ, service, method, status.Code(err).String()).Inc()
+}
+
+// splitMethod splits a full method name, such as /package.Service/Method,
+// into the service and method names.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	i := strings.LastIndex(fullMethod, "/")
+	if i < 0 {
+		return "unknown", fullMethod
+	}
+
+	return fullMethod[:i], fullMethod[i+1:]
+}
+
+func streamType(clientStream, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return typeBidiStream
+	case clientStream:
+		return typeClientStream
+	case serverStream:
+		return typeServerStream
+	default:
+		return typeUnary
+	}
+}
@@ -0,0 +1,100 @@
+// Package metrics instruments gRPC servers and clients, HTTP handlers and
+// database pools with Prometheus metrics, and serves them.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is the path metrics are served on.
+const Path = "/metrics"
+
+// Metrics holds the collectors of a service, registered on its own registry
+// along with the standard Go runtime and process collectors.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	serverHandled *prometheus.CounterVec
+	serverLatency *prometheus.HistogramVec
+	clientHandled *prometheus.CounterVec
+	clientLatency *prometheus.HistogramVec
+	httpRequests  *prometheus.CounterVec
+	httpLatency   *prometheus.HistogramVec
+}
+
+// New returns metrics registered on a new registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		serverHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "The number of RPCs completed by the server, by method and status code.",
+		}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		serverLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "The time RPCs took to be handled by the server, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		clientHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_handled_total",
+			Help: "The number of RPCs completed by the client, by method and status code.",
+		}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		clientLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_client_handling_seconds",
+			Help:    "The time RPCs took to complete for the client, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "The number of HTTP requests served, by handler, method and status code.",
+		}, []string{"handler", "method", "code"}),
+		httpLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "The time HTTP requests took to be served, by handler and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"handler", "method"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.serverHandled,
+		m.serverLatency,
+		m.clientHandled,
+		m.clientLatency,
+		m.httpRequests,
+		m.httpLatency,
+	)
+
+	return m
+}
+
+// Register registers further collectors, such as those of the service itself.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		err := m.registry.Register(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the number and duration of the requests served by the handler.
+// The name labels its metrics, such as gateway or swagger.
+func (m *Metrics) Middleware(name string, next http.Handler) http.Handler {
+	labels := prometheus.Labels{"handler": name}
+	return promhttp.InstrumentHandlerDuration(
+		m.httpLatency.MustCurryWith(labels),
+		promhttp.InstrumentHandlerCounter(m.httpRequests.MustCurryWith(labels), next),
+	)
+}
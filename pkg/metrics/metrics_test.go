@@ -0,0 +1,221 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/uw-labs/go-mono/pkg/metrics"
+)
+
+type fakeDB struct {
+	stats sql.DBStats
+}
+
+func (f fakeDB) Stats() sql.DBStats {
+	return f.stats
+}
+
+// fakeClientStream receives the messages, then the error.
+type fakeClientStream struct {
+	grpc.ClientStream
+	messages int
+	err      error
+}
+
+func (s *fakeClientStream) RecvMsg(interface{}) error {
+	if s.messages > 0 {
+		s.messages--
+		return nil
+	}
+	return s.err
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	body, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/uwlabs.user.service.v1.UserService/GetUser"}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	_, err := m.UnaryServerInterceptor()(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected error %q, got %v", codes.NotFound, err)
+	}
+
+	h := m.Middleware("gateway", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/users", nil))
+
+	err = m.RegisterDB("users", fakeDB{stats: sql.DBStats{OpenConnections: 3, InUse: 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`grpc_server_handled_total{grpc_code="NotFound",grpc_method="GetUser",grpc_service="uwlabs.user.service.v1.UserService",grpc_type="unary"} 1`,
+		`grpc_server_handling_seconds_count{grpc_method="GetUser",grpc_service="uwlabs.user.service.v1.UserService",grpc_type="unary"} 1`,
+		`http_requests_total{code="201",handler="gateway",method="post"} 1`,
+		`http_request_duration_seconds_count{handler="gateway",method="post"} 1`,
+		`go_sql_open_connections{db_name="users"} 3`,
+		`go_sql_in_use_connections{db_name="users"} 1`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+}
+
+func TestRegisterDBDuplicate(t *testing.T) {
+	m := metrics.New()
+	err := m.RegisterDB("users", fakeDB{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = m.RegisterDB("users", fakeDB{})
+	if err == nil {
+		t.Fatal("expected an error registering the same database twice")
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	const method = "/uwlabs.user.service.v1.UserService/ListUsers"
+
+	for _, test := range []struct {
+		name       string
+		desc       *grpc.StreamDesc
+		openErr    error
+		messages   int
+		err        error
+		abandon    bool
+		wantType   string
+		wantCode   codes.Code
+		wantOpened bool
+	}{
+		{
+			name:       "It records a server stream when it ends",
+			desc:       &grpc.StreamDesc{ServerStreams: true},
+			messages:   2,
+			err:        io.EOF,
+			wantType:   "server_stream",
+			wantCode:   codes.OK,
+			wantOpened: true,
+		},
+		{
+			name:       "It records a server stream when it fails",
+			desc:       &grpc.StreamDesc{ServerStreams: true},
+			messages:   1,
+			err:        status.Error(codes.Unavailable, "connection reset"),
+			wantType:   "server_stream",
+			wantCode:   codes.Unavailable,
+			wantOpened: true,
+		},
+		{
+			name:       "It records a client stream when it receives the response",
+			desc:       &grpc.StreamDesc{ClientStreams: true},
+			messages:   1,
+			err:        io.EOF,
+			wantType:   "client_stream",
+			wantCode:   codes.OK,
+			wantOpened: true,
+		},
+		{
+			name:       "It records a stream abandoned when its context is cancelled",
+			desc:       &grpc.StreamDesc{ServerStreams: true},
+			messages:   2,
+			abandon:    true,
+			wantType:   "server_stream",
+			wantCode:   codes.Canceled,
+			wantOpened: true,
+		},
+		{
+			name:     "It records a stream that fails to open",
+			desc:     &grpc.StreamDesc{ClientStreams: true, ServerStreams: true},
+			openErr:  status.Error(codes.PermissionDenied, "denied"),
+			wantType: "bidi_stream",
+			wantCode: codes.PermissionDenied,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m := metrics.New()
+			streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				if test.openErr != nil {
+					return nil, test.openErr
+				}
+				return &fakeClientStream{messages: test.messages, err: test.err}, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cs, err := m.StreamClientInterceptor()(ctx, test.desc, nil, method, streamer)
+			if test.openErr != nil {
+				if status.Code(err) != test.wantCode {
+					t.Fatalf("expected error %q, got %v", test.wantCode, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			handled := fmt.Sprintf(`grpc_client_handled_total{grpc_code="%s",grpc_method="ListUsers",grpc_service="uwlabs.user.service.v1.UserService",grpc_type="%s"} 1`,
+				test.wantCode, test.wantType)
+			if test.wantOpened {
+				if strings.Contains(scrape(t, m), "grpc_client_handled_total{") {
+					t.Fatal("expected the stream not to be recorded before it ends")
+				}
+				if test.abandon {
+					cancel()
+				} else {
+					for err == nil {
+						err = cs.RecvMsg(nil)
+					}
+				}
+				// Receiving again, or cancelling after the stream ended,
+				// does not record the stream again.
+				_ = cs.RecvMsg(nil)
+				cancel()
+			}
+
+			// Cancelled streams are recorded asynchronously.
+			deadline := time.Now().Add(time.Second)
+			body := scrape(t, m)
+			for !strings.Contains(body, handled) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+				body = scrape(t, m)
+			}
+			if !strings.Contains(body, handled) {
+				t.Errorf("expected metrics to contain %q, got:\n%s", handled, body)
+			}
+		})
+	}
+}
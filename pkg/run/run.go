@@ -0,0 +1,262 @@
+// Package run manages the lifecycle of the servers and background workers of a service.
+//
+// A Group starts everything added to it and runs until its context is cancelled,
+// by default on an Interrupt or SIGTERM signal, or until one of them stops.
+// It then calls the functions registered with OnDrain, such as to report the
+// service as not ready, waits for the drain delay so that load balancers stop
+// sending new requests, and stops everything in the reverse of the order it was
+// added in, each within the shutdown timeout.
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	pkgctx "github.com/uw-labs/go-mono/pkg/context"
+)
+
+// DefaultShutdownTimeout is the shutdown timeout of a Group that does not set one.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// Group runs servers and workers until it is cancelled or one of them stops.
+// The zero value is ready to use. A Group must not be copied or reused after Run.
+type Group struct {
+	// Logger logs the starting and stopping of the members of the group.
+	// Defaults to the standard logrus logger.
+	Logger logrus.FieldLogger
+	// ShutdownTimeout is how long each member is given to stop gracefully
+	// before it is stopped forcefully. Defaults to DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long to wait after calling the OnDrain functions, and
+	// before stopping anything, so that load balancers stop sending new requests.
+	DrainDelay time.Duration
+	// SignalOptions configure the signals handled while running.
+	SignalOptions []pkgctx.Option
+
+	mu      sync.Mutex
+	members []*member
+	onDrain []func()
+}
+
+type member struct {
+	name   string
+	run    func(ctx context.Context) error
+	stop   func(ctx context.Context) error
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Add adds a member running until run returns. Stop is called with a context
+// cancelled after the shutdown timeout when the group stops, and must make run return.
+func (g *Group) Add(name string, run func() error, stop func(ctx context.Context) error) {
+	g.add(&member{
+		name: name,
+		run: func(context.Context) error {
+			return run()
+		},
+		stop: stop,
+	})
+}
+
+// AddWorker adds a background worker running until fn returns. The context
+// passed to fn carries the values of the context of the group, but is only
+// cancelled when it is the turn of the worker to stop.
+func (g *Group) AddWorker(name string, fn func(ctx context.Context) error) {
+	g.add(&member{
+		name: name,
+		run: func(ctx context.Context) error {
+			err := fn(ctx)
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				return nil
+			}
+			return err
+		},
+		stop: func(context.Context) error {
+			return nil
+		},
+	})
+}
+
+// AddGRPC adds a gRPC server serving on the listener. The server is stopped
+// gracefully, letting pending RPCs finish, until the shutdown timeout.
+func (g *Group) AddGRPC(name string, srv *grpc.Server, lis net.Listener) {
+	g.add(&member{
+		name: name,
+		run: func(context.Context) error {
+			return srv.Serve(lis)
+		},
+		stop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return fmt.Errorf("graceful stop: %w", ctx.Err())
+			}
+		},
+	})
+}
+
+// AddHTTP adds an HTTP server serving on the listener or, if it is nil, on the
+// address of the server. The server is shut down gracefully, letting active
+// connections finish, until the shutdown timeout.
+func (g *Group) AddHTTP(name string, srv *http.Server, lis net.Listener) {
+	g.add(&member{
+		name: name,
+		run: func(context.Context) error {
+			var err error
+			if lis != nil {
+				err = srv.Serve(lis)
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if err == http.ErrServerClosed {
+				return nil
+			}
+			return err
+		},
+		stop: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				cErr := srv.Close()
+				if cErr != nil {
+					return fmt.Errorf("close: %w", cErr)
+				}
+				return fmt.Errorf("shutdown: %w", err)
+			}
+			return nil
+		},
+	})
+}
+
+// OnDrain registers a function called when the group starts shutting down,
+// before the drain delay, such as to report that the service is not ready.
+func (g *Group) OnDrain(fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onDrain = append(g.onDrain, fn)
+}
+
+// Run starts everything in the group and blocks until the context is
+// cancelled, a handled signal is received or a member stops. It returns
+// the first error returned by a member, or by stopping one.
+func (g *Group) Run(ctx context.Context) error {
+	logger := g.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	timeout := g.ShutdownTimeout
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	ctx, stopSignals := pkgctx.WithSignalHandlerStop(ctx, g.SignalOptions...)
+	defer stopSignals()
+
+	g.mu.Lock()
+	members := g.members
+	g.mu.Unlock()
+
+	stopped := make(chan *member, len(members))
+	for _, m := range members {
+		m := m
+		var mCtx context.Context
+		mCtx, m.cancel = context.WithCancel(pkgctx.Detach(ctx))
+		logger.WithField("member", m.name).Debug("Starting")
+		go func() {
+			defer close(m.done)
+			m.err = m.run(mCtx)
+			stopped <- m
+		}()
+	}
+	logger.Debug("Started")
+
+	var firstErr error
+	select {
+	case <-ctx.Done():
+		fields := logrus.Fields{}
+		if sig := pkgctx.Signal(ctx); sig != nil {
+			fields["signal"] = sig.String()
+		}
+		logger.WithFields(fields).Info("Shutting down")
+	case m := <-stopped:
+		if m.err != nil {
+			firstErr = fmt.Errorf("%s: %w", m.name, m.err)
+			logger.WithError(m.err).WithField("member", m.name).Error("Stopped unexpectedly, shutting down")
+		} else {
+			logger.WithField("member", m.name).Info("Stopped, shutting down")
+		}
+	}
+
+	g.mu.Lock()
+	onDrain := g.onDrain
+	g.mu.Unlock()
+	for _, fn := range onDrain {
+		fn()
+	}
+	if g.DrainDelay > 0 {
+		time.Sleep(g.DrainDelay)
+	}
+
+	for i := len(members) - 1; i >= 0; i-- {
+		err := g.stop(logger, members[i], timeout)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// stop stops the member and waits for it to return, until the timeout.
+func (g *Group) stop(logger logrus.FieldLogger, m *member, timeout time.Duration) error {
+	logger = logger.WithField("member", m.name)
+	logger.Debug("Stopping")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.stop(ctx)
+	m.cancel()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to stop gracefully")
+		err = fmt.Errorf("stop %s: %w", m.name, err)
+	}
+
+	select {
+	case <-m.done:
+		if m.err != nil && err == nil {
+			err = fmt.Errorf("%s: %w", m.name, m.err)
+		}
+	case <-ctx.Done():
+		logger.Warn("Did not stop before the shutdown timeout")
+		if err == nil {
+			err = fmt.Errorf("stop %s: %w", m.name, ctx.Err())
+		}
+	}
+
+	return err
+}
+
+func (g *Group) add(m *member) {
+	m.done = make(chan struct{})
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.members = append(g.members, m)
+}
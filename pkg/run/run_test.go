@@ -0,0 +1,174 @@
+package run_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/uw-labs/go-mono/pkg/run"
+)
+
+func newGroup() *run.Group {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	return &run.Group{
+		Logger:          logger,
+		ShutdownTimeout: time.Second,
+	}
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("It stops members in reverse order when cancelled", func(t *testing.T) {
+		g := newGroup()
+
+		var mu sync.Mutex
+		var order []string
+		record := func(name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+		for _, name := range []string{"first", "second"} {
+			name := name
+			stop := make(chan struct{})
+			g.Add(name, func() error {
+				<-stop
+				return nil
+			}, func(context.Context) error {
+				record(name)
+				close(stop)
+				return nil
+			})
+		}
+		started := make(chan struct{})
+		g.AddWorker("worker", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			record("worker")
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		drained := false
+		g.OnDrain(func() {
+			drained = true
+			if len(order) > 0 {
+				t.Error("expected the drain functions to be called before stopping anything")
+			}
+		})
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		err := g.Run(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !drained {
+			t.Error("expected the drain functions to be called")
+		}
+		if diff := cmp.Diff([]string{"worker", "second", "first"}, order); diff != "" {
+			t.Errorf("unexpected stop order (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("It stops when a member fails", func(t *testing.T) {
+		g := newGroup()
+
+		wantErr := errors.New("failed")
+		g.Add("failing", func() error {
+			return wantErr
+		}, func(context.Context) error {
+			return nil
+		})
+		stopped := false
+		g.AddWorker("worker", func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped = true
+			return nil
+		})
+
+		err := g.Run(context.Background())
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected error %q, got %v", wantErr, err)
+		}
+		if !stopped {
+			t.Error("expected the worker to be stopped")
+		}
+	})
+	t.Run("It reports members that do not stop in time", func(t *testing.T) {
+		g := newGroup()
+		g.ShutdownTimeout = 10 * time.Millisecond
+
+		release := make(chan struct{})
+		defer close(release)
+		g.AddWorker("stuck", func(context.Context) error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := g.Run(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected error %q, got %v", context.DeadlineExceeded, err)
+		}
+	})
+	t.Run("It serves and stops gRPC and HTTP servers", func(t *testing.T) {
+		g := newGroup()
+
+		grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		g.AddGRPC("grpc", grpc.NewServer(), grpcLis)
+
+		httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		g.AddHTTP("http", &http.Server{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})}, httpLis)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- g.Run(ctx)
+		}()
+
+		var resp *http.Response
+		for i := 0; i < 100; i++ {
+			resp, err = http.Get("http://" + httpLis.Addr().String())
+			if err == nil && resp.StatusCode == http.StatusOK {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+
+		cancel()
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the group to stop")
+		}
+	})
+}